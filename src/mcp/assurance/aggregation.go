@@ -0,0 +1,169 @@
+package assurance
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Evidence aggregation strategies for the self score (B.3)
+const (
+	AggregateMean         = "mean"
+	AggregateWeightedMean = "weighted_mean"
+	AggregateMin          = "min"
+	AggregateBeta         = "beta"
+)
+
+// AggregationPolicy turns the scores of a holon's evidence into its self score.
+// Weights come from the evidence's assurance_level multiplied by its carrier_ref;
+// levels or carriers that are not listed weigh DefaultEvidenceWeight.
+type AggregationPolicy struct {
+	Strategy       string             `json:"strategy"`
+	LevelWeights   map[string]float64 `json:"assurance_level_weights,omitempty"`
+	CarrierWeights map[string]float64 `json:"carrier_weights,omitempty"`
+	// Prior is the Beta(α, β) pseudo-count prior used by the beta strategy
+	Prior [2]float64 `json:"prior"`
+}
+
+// DefaultEvidenceWeight applies to levels and carriers without a configured weight
+const DefaultEvidenceWeight = 1.0
+
+// DefaultAggregationPolicy keeps the plain mean, with weights ready for the
+// weighted strategies: L2 test results outweigh L1 internal reasoning
+func DefaultAggregationPolicy() AggregationPolicy {
+	return AggregationPolicy{
+		Strategy: AggregateMean,
+		LevelWeights: map[string]float64{
+			"l0": 0.25,
+			"l1": 0.5,
+			"l2": 1.0,
+		},
+		CarrierWeights: map[string]float64{
+			"test-runner":    1.0,
+			"auditor":        1.0,
+			"formal-logic":   0.8,
+			"internal-logic": 0.5,
+		},
+		Prior: [2]float64{1, 1},
+	}
+}
+
+// AggregationStrategies lists the supported strategies
+func AggregationStrategies() []string {
+	return []string{AggregateBeta, AggregateMean, AggregateMin, AggregateWeightedMean}
+}
+
+// Validate checks the strategy, weights and prior
+func (p AggregationPolicy) Validate() error {
+	switch p.Strategy {
+	case AggregateMean, AggregateWeightedMean, AggregateMin, AggregateBeta:
+	default:
+		return fmt.Errorf("unknown aggregation strategy %q (available: %s)", p.Strategy, strings.Join(AggregationStrategies(), ", "))
+	}
+	for _, weights := range []map[string]float64{p.LevelWeights, p.CarrierWeights} {
+		for k, w := range weights {
+			if w < 0 {
+				return fmt.Errorf("weight for %q must not be negative, got %.2f", k, w)
+			}
+		}
+	}
+	if p.Prior[0] < 0 || p.Prior[1] < 0 {
+		return fmt.Errorf("beta prior must not be negative, got (%.2f, %.2f)", p.Prior[0], p.Prior[1])
+	}
+	return nil
+}
+
+// Weight returns how much one evidence item counts under the weighted strategies
+func (p AggregationPolicy) Weight(assuranceLevel, carrierRef string) float64 {
+	return lookupWeight(p.LevelWeights, assuranceLevel) * lookupWeight(p.CarrierWeights, carrierRef)
+}
+
+func lookupWeight(weights map[string]float64, key string) float64 {
+	if w, ok := weights[strings.ToLower(strings.TrimSpace(key))]; ok {
+		return w
+	}
+	return DefaultEvidenceWeight
+}
+
+// weightedScore is one evidence score with its aggregation weight
+type weightedScore struct {
+	score  float64
+	weight float64
+}
+
+// aggregate combines evidence scores into a self score in [0,1].
+// It returns 0 when there is nothing to aggregate.
+func (p AggregationPolicy) aggregate(items []weightedScore) float64 {
+	if len(items) == 0 {
+		return 0
+	}
+
+	switch p.Strategy {
+	case AggregateMin:
+		lowest := 1.0
+		for _, it := range items {
+			lowest = math.Min(lowest, it.score)
+		}
+		return lowest
+
+	case AggregateWeightedMean:
+		var sum, total float64
+		for _, it := range items {
+			sum += it.score * it.weight
+			total += it.weight
+		}
+		if total == 0 {
+			return 0
+		}
+		return sum / total
+
+	case AggregateBeta:
+		// Each item is weight pseudo-observations of success (score) and failure (1-score);
+		// the self score is the posterior mean α/(α+β)
+		alpha, beta := p.Prior[0], p.Prior[1]
+		for _, it := range items {
+			alpha += it.score * it.weight
+			beta += (1 - it.score) * it.weight
+		}
+		if alpha+beta == 0 {
+			return 0
+		}
+		return alpha / (alpha + beta)
+
+	default:
+		var sum float64
+		for _, it := range items {
+			sum += it.score
+		}
+		return sum / float64(len(items))
+	}
+}
+
+// String renders the strategy and the weights it uses for reports
+func (p AggregationPolicy) String() string {
+	switch p.Strategy {
+	case AggregateWeightedMean:
+		return fmt.Sprintf("%s (levels %s; carriers %s)", p.Strategy, formatWeights(p.LevelWeights), formatWeights(p.CarrierWeights))
+	case AggregateBeta:
+		return fmt.Sprintf("%s (prior α=%.1f β=%.1f; levels %s; carriers %s)", p.Strategy, p.Prior[0], p.Prior[1], formatWeights(p.LevelWeights), formatWeights(p.CarrierWeights))
+	default:
+		return p.Strategy
+	}
+}
+
+func formatWeights(weights map[string]float64) string {
+	if len(weights) == 0 {
+		return "none"
+	}
+	keys := make([]string, 0, len(weights))
+	for k := range weights {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%.2f", k, weights[k]))
+	}
+	return strings.Join(parts, " ")
+}
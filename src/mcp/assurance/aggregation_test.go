@@ -0,0 +1,62 @@
+package assurance
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAggregationPolicy_Strategies(t *testing.T) {
+	// An L2 test-runner pass and an L1 internal-logic fail
+	items := []weightedScore{
+		{score: 1.0, weight: 1.0},
+		{score: 0.0, weight: 0.25},
+	}
+
+	tests := []struct {
+		strategy string
+		expected float64
+	}{
+		{AggregateMean, 0.5},
+		{AggregateWeightedMean, 0.8},
+		{AggregateMin, 0.0},
+		{AggregateBeta, 2.0 / 3.25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			p := DefaultAggregationPolicy()
+			p.Strategy = tt.strategy
+			got := p.aggregate(items)
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("Expected %.4f, got %.4f", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAggregationPolicy_Weight(t *testing.T) {
+	p := DefaultAggregationPolicy()
+	if w := p.Weight("L2", "test-runner"); w != 1.0 {
+		t.Errorf("Expected weight 1.0 for L2 test-runner, got %f", w)
+	}
+	if w := p.Weight("L1", "internal-logic"); w != 0.25 {
+		t.Errorf("Expected weight 0.25 for L1 internal-logic, got %f", w)
+	}
+	if w := p.Weight("", "src/cache.go"); w != DefaultEvidenceWeight {
+		t.Errorf("Expected default weight for unknown level and carrier, got %f", w)
+	}
+}
+
+func TestAggregationPolicy_Validate(t *testing.T) {
+	p := DefaultAggregationPolicy()
+	p.Strategy = "median"
+	if err := p.Validate(); err == nil {
+		t.Errorf("Expected error for unknown strategy")
+	}
+
+	p = DefaultAggregationPolicy()
+	p.CarrierWeights["auditor"] = -1
+	if err := p.Validate(); err == nil {
+		t.Errorf("Expected error for negative weight")
+	}
+}
@@ -3,70 +3,308 @@ package assurance
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"math"
 	"strings"
 	"time"
 )
 
+// Formality (F) bounds, C.2.3: F0 is an informal sketch, F9 a machine-checked proof
+const (
+	MinFormality = 0
+	MaxFormality = 9
+)
+
 // AssuranceReport contains details of the reliability calculation for AI explanation
 type AssuranceReport struct {
-	HolonID      string
-	FinalScore   float64
-	SelfScore    float64 // Score based on own evidence
-	WeakestLink  string  // ID of the dependency pulling the score down
-	DecayPenalty float64
-	Factors      []string // Textual explanations for AI
+	HolonID       string
+	FinalScore    float64
+	SelfScore     float64 // Score based on own evidence
+	WeakestLink   string  // ID of the dependency pulling the score down
+	DecayPenalty  float64
+	SelfFormality int                // Formality declared on the holon itself
+	Formality     int                // Effective F after weakest-link propagation
+	SelfScope     Scope              // ClaimScope declared on the holon itself
+	Scope         Scope              // Effective G: intersection over dependencies, span-union over members
+	Penalty       PenaltyProfile     // Φ(CL) used, recorded so the report can be reproduced
+	Aggregation   string             // Evidence aggregation strategy that produced SelfScore
+	AsOf          time.Time          // Instant the evidence ages were evaluated at
+	Waived        []string           // Evidence IDs whose decay was suspended by an active waiver
+	Cycle         bool               // Holon was reached again through a cycle and not re-evaluated
+	Cycles        [][]string         // Dependency cycles below this holon, each path closing on its first ID
+	Evidence      []EvidenceDetail   // Per-item scoring behind SelfScore
+	Dependencies  []DependencyDetail // Per-edge WLNK inputs, each with the child's report
+	Factors       []string           // Textual explanations for AI
 }
 
 // Calculator handles assurance logic
 type Calculator struct {
-	DB *sql.DB
+	DB          *sql.DB
+	Penalty     PenaltyProfile
+	Decay       DecayPolicy
+	Aggregation AggregationPolicy
+	// AsOf evaluates evidence age at a fixed instant instead of now. Results
+	// for a non-zero AsOf are projections and are not written to the cache.
+	AsOf time.Time
+	// NoCache computes R without writing it to the cache, for callers that
+	// must not write through DB (a read-only request, or one whose transaction
+	// holds the write lock)
+	NoCache bool
+	// Progress, if set, is called after each holon CalculateAll and
+	// RefreshDirty evaluate with the number done and the total
+	Progress func(done, total int)
 }
 
-// New creates a new Calculator
+// New creates a new Calculator with the default penalty, decay and aggregation policies
 func New(db *sql.DB) *Calculator {
-	return &Calculator{DB: db}
+	return &Calculator{
+		DB:          db,
+		Penalty:     DefaultPenaltyProfile(),
+		Decay:       DefaultDecayPolicy(),
+		Aggregation: DefaultAggregationPolicy(),
+	}
+}
+
+// writesCache reports whether fresh results may be written to cached_r_score
+func (c *Calculator) writesCache() bool {
+	return c.AsOf.IsZero() && !c.NoCache
+}
+
+func (c *Calculator) now() time.Time {
+	if c.AsOf.IsZero() {
+		return time.Now()
+	}
+	return c.AsOf
 }
 
 // CalculateReliability calculates R for a holon (public API)
 func (c *Calculator) CalculateReliability(ctx context.Context, holonID string) (*AssuranceReport, error) {
-	visited := make(map[string]bool)
-	return c.calculateReliabilityWithVisited(ctx, holonID, visited)
+	e := newEvaluation(sqlSource{db: c.DB})
+	e.cache = c.writesCache()
+	return c.calculateReliabilityWithVisited(ctx, e, holonID)
 }
 
-// calculateReliabilityWithVisited is the internal implementation with cycle detection
-func (c *Calculator) calculateReliabilityWithVisited(ctx context.Context, holonID string, visited map[string]bool) (*AssuranceReport, error) {
-	// Cycle detection: if already visited, return neutral score to break cycle
-	if visited[holonID] {
-		return &AssuranceReport{
-			HolonID:    holonID,
-			FinalScore: 1.0, // Neutral - don't penalize for cycle
-			SelfScore:  1.0,
-			Factors:    []string{"Cycle detected, skipping re-evaluation"},
-		}, nil
-	}
-	visited[holonID] = true
+// evaluation is the state of one calculation run
+type evaluation struct {
+	src  graphSource
+	path []string                    // Holons on the current recursion path, root first
+	memo map[string]*AssuranceReport // Finished reports, reused when a DAG reaches a holon twice
+	// cache writes each report to cached_r_score as it completes
+	cache bool
+}
 
-	report := &AssuranceReport{HolonID: holonID}
+func newEvaluation(src graphSource) *evaluation {
+	return &evaluation{src: src, memo: make(map[string]*AssuranceReport)}
+}
 
-	// 1. Calculate Self Score (based on Evidence)
-	// B.3.4: Check for expired evidence
-	rows, err := c.DB.QueryContext(ctx, "SELECT verdict, valid_until FROM evidence WHERE holon_id = ?", holonID)
+// cycleTo returns the cycle closed by reaching holonID again, or nil when
+// holonID is not on the current path
+func (e *evaluation) cycleTo(holonID string) []string {
+	for i, id := range e.path {
+		if id == holonID {
+			cycle := append([]string{}, e.path[i:]...)
+			return append(cycle, holonID)
+		}
+	}
+	return nil
+}
+
+// CalculateAll recalculates R for every holon from a single snapshot of the
+// graph. Shared dependencies are evaluated once, and all cached scores are
+// written in one transaction (skipped for projections with a non-zero AsOf).
+func (c *Calculator) CalculateAll(ctx context.Context) (map[string]*AssuranceReport, error) {
+	snap, err := loadSnapshot(ctx, c.DB)
 	if err != nil {
+		return nil, fmt.Errorf("failed to load holon graph: %w", err)
+	}
+
+	e := newEvaluation(snap)
+	if err := c.evaluateAll(ctx, e, snap.ids); err != nil {
+		return nil, err
+	}
+
+	// memo also holds dangling dependency IDs; report only stored holons
+	reports := make(map[string]*AssuranceReport, len(snap.ids))
+	for _, id := range snap.ids {
+		reports[id] = e.memo[id]
+	}
+
+	if !c.writesCache() {
+		return reports, nil
+	}
+	if err := c.writeCachedScores(ctx, snap.ids, reports); err != nil {
 		return nil, err
 	}
-	defer rows.Close() //nolint:errcheck
+	return reports, nil
+}
+
+// updateCachedScore stores a fresh R and clears the dirty flag set by invalidation
+const updateCachedScore = "UPDATE holons SET cached_r_score = ?, r_dirty = 0, r_computed_at = ? WHERE id = ?"
+
+// CachedScore is a holon's cached R and when it was computed
+type CachedScore struct {
+	R          float64
+	ComputedAt time.Time
+	// Recomputed is set when the cache was dirty and had to be refreshed
+	Recomputed bool
+}
 
-	var totalScore, count float64
+// CachedReliability returns the cached R of a holon, recomputing it first
+// when it was invalidated or never computed
+func (c *Calculator) CachedReliability(ctx context.Context, holonID string) (CachedScore, error) {
+	var r sql.NullFloat64
+	var dirty sql.NullInt64
+	var computedAt sql.NullTime
+	err := c.DB.QueryRowContext(ctx, "SELECT cached_r_score, r_dirty, r_computed_at FROM holons WHERE id = ?", holonID).Scan(&r, &dirty, &computedAt)
+	if err != nil {
+		return CachedScore{}, err
+	}
+	if dirty.Int64 == 0 && computedAt.Valid {
+		return CachedScore{R: r.Float64, ComputedAt: computedAt.Time}, nil
+	}
+
+	report, err := c.CalculateReliability(ctx, holonID)
+	if err != nil {
+		return CachedScore{}, err
+	}
+	return CachedScore{R: report.FinalScore, ComputedAt: report.AsOf, Recomputed: true}, nil
+}
+
+// RefreshDirty recomputes every holon whose cached R was invalidated or never
+// computed, sharing evaluated subgraphs between them, and returns their IDs
+func (c *Calculator) RefreshDirty(ctx context.Context) ([]string, error) {
+	if !c.AsOf.IsZero() {
+		return nil, fmt.Errorf("cached scores cannot be refreshed from a projection (as of %s)", c.AsOf.Format("2006-01-02"))
+	}
+	if c.NoCache {
+		return nil, fmt.Errorf("cached scores cannot be refreshed without writing the cache")
+	}
+
+	rows, err := c.DB.QueryContext(ctx, "SELECT id FROM holons WHERE r_dirty != 0 OR r_computed_at IS NULL ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
 	for rows.Next() {
-		var verdict string
-		var validUntil *time.Time
-		if err := rows.Scan(&verdict, &validUntil); err != nil {
+		var id string
+		if err := rows.Scan(&id); err != nil {
 			continue
 		}
+		ids = append(ids, id)
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, err
+	}
+
+	e := newEvaluation(sqlSource{db: c.DB})
+	e.cache = true
+	if err := c.evaluateAll(ctx, e, ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// evaluateAll evaluates each holon in turn, reporting progress and stopping
+// once ctx is cancelled
+func (c *Calculator) evaluateAll(ctx context.Context, e *evaluation, ids []string) error {
+	for i, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := c.calculateReliabilityWithVisited(ctx, e, id); err != nil {
+			return fmt.Errorf("failed to calculate %s: %w", id, err)
+		}
+		if c.Progress != nil {
+			c.Progress(i+1, len(ids))
+		}
+	}
+	return nil
+}
+
+func (c *Calculator) writeCachedScores(ctx context.Context, ids []string, reports map[string]*AssuranceReport) error {
+	tx, err := c.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.PrepareContext(ctx, updateCachedScore)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close() //nolint:errcheck
+
+	computedAt := time.Now()
+	for _, id := range ids {
+		if _, err := stmt.ExecContext(ctx, reports[id].FinalScore, computedAt, id); err != nil {
+			return fmt.Errorf("failed to cache score for %s: %w", id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// calculateReliabilityWithVisited is the internal implementation with cycle detection.
+// A holon reached twice through a DAG reuses its finished report; a holon
+// reached again while it is still being evaluated closes a true cycle.
+func (c *Calculator) calculateReliabilityWithVisited(ctx context.Context, e *evaluation, holonID string) (*AssuranceReport, error) {
+	if report, ok := e.memo[holonID]; ok {
+		return report, nil
+	}
+
+	// A claim cannot support itself: the edge closing a cycle counts as R 0.
+	// F and G are left neutral, the holon's own values apply further up the path.
+	if cycle := e.cycleTo(holonID); cycle != nil {
+		return &AssuranceReport{
+			HolonID:       holonID,
+			FinalScore:    0.0,
+			SelfScore:     0.0,
+			SelfFormality: MaxFormality,
+			Formality:     MaxFormality,
+			Penalty:       c.Penalty,
+			Aggregation:   c.Aggregation.Strategy,
+			AsOf:          c.now(),
+			Cycle:         true,
+			Cycles:        [][]string{cycle},
+			Factors:       []string{"Cycle closes here, circular support counts as R 0"},
+		}, nil
+	}
+	e.path = append(e.path, holonID)
+	defer func() { e.path = e.path[:len(e.path)-1] }()
+	src := e.src
+
+	report := &AssuranceReport{HolonID: holonID, Penalty: c.Penalty, Aggregation: c.Aggregation.Strategy, AsOf: c.now()}
 
+	attrs, err := src.holonAttrs(ctx, holonID)
+	if err != nil {
+		return nil, err
+	}
+	report.SelfFormality = attrs.formality
+	report.Formality = attrs.formality
+	report.SelfScope = attrs.scope
+	report.Scope = attrs.scope
+	if attrs.scopeNote != "" {
+		report.Factors = append(report.Factors, attrs.scopeNote)
+	}
+
+	// B.3.4: An active waiver is an explicit risk acceptance; it suspends decay
+	waiverRows, err := src.waivers(ctx, holonID)
+	if err != nil {
+		return nil, err
+	}
+	waivers := activeWaivers(waiverRows, report.AsOf)
+
+	// 1. Calculate Self Score (based on Evidence)
+	// B.3.4: Evidence erodes with age according to the decay model for its type
+	// B.3: Items are combined by the configured aggregation strategy
+	items, err := src.evidence(ctx, holonID)
+	if err != nil {
+		return nil, err
+	}
+
+	var decayed, undecayed []weightedScore
+	for _, e := range items {
 		score := 0.0
-		switch strings.ToLower(verdict) {
+		switch strings.ToLower(e.verdict) {
 		case "pass":
 			score = 1.0
 		case "degrade":
@@ -76,67 +314,77 @@ func (c *Calculator) calculateReliabilityWithVisited(ctx context.Context, holonI
 		}
 
 		// Evidence Decay Logic
-		if validUntil != nil && time.Now().After(*validUntil) {
+		model := c.Decay.ModelFor(e.evidenceType)
+		weight := model.Weight(e.createdAt, e.validUntil, report.AsOf)
+		expired := !e.validUntil.IsZero() && report.AsOf.After(e.validUntil)
+		detail := EvidenceDetail{
+			ID:             e.id,
+			Type:           e.evidenceType,
+			Verdict:        e.verdict,
+			AssuranceLevel: e.assuranceLevel,
+			CarrierRef:     e.carrierRef,
+			RawScore:       score,
+			Expired:        expired,
+			DecayModel:     model.String(),
+		}
+		if !e.validUntil.IsZero() {
+			validUntil := e.validUntil
+			detail.ValidUntil = &validUntil
+		}
+		if w, ok := waivers[e.id]; ok && weight < 1.0 {
+			detail.Waiver = &WaiverDetail{WaivedBy: w.by, WaivedUntil: w.until, SuspendedWeight: weight}
+			report.Waived = append(report.Waived, e.id)
+			report.Factors = append(report.Factors, fmt.Sprintf("Evidence %s waived by %s until %s", e.id, w.by, w.until.Format("2006-01-02")))
+			weight = 1.0
+		} else if expired {
 			report.Factors = append(report.Factors, "Evidence expired (Decay applied)")
-			score = 0.1                // Penalty for expiration, not zero but close
-			report.DecayPenalty += 0.9 // Track how much was lost
+		} else if weight < 1.0 && score > 0 {
+			report.Factors = append(report.Factors, fmt.Sprintf("Evidence aged (Decay applied): %s weighted %.2f, %s", e.id, weight, model))
 		}
-		totalScore += score
-		count++
+		evidenceWeight := c.Aggregation.Weight(e.assuranceLevel, e.carrierRef)
+		detail.DecayWeight = weight
+		detail.EffectiveScore = score * weight
+		detail.AggregationWeight = evidenceWeight
+		report.Evidence = append(report.Evidence, detail)
+		undecayed = append(undecayed, weightedScore{score: score, weight: evidenceWeight})
+		decayed = append(decayed, weightedScore{score: score * weight, weight: evidenceWeight})
 	}
 
-	if count > 0 {
-		report.SelfScore = totalScore / count // Or other aggregation logic
+	if len(decayed) > 0 {
+		report.SelfScore = c.Aggregation.aggregate(decayed)
+		report.DecayPenalty = math.Max(0, c.Aggregation.aggregate(undecayed)-report.SelfScore)
 	} else {
 		report.SelfScore = 0.0 // L0: Unsubstantiated
 		report.Factors = append(report.Factors, "No evidence found (L0)")
 	}
 
 	// 2. Calculate Dependencies Score (Weakest Link + CL Penalty)
+	// Formality follows the same edges: F_eff = min(F_self, F_dep), CL does not apply
+	// Scope follows them too as serial composition: G_eff = G_self ∩ G_dep
 	// B.3: R_eff = max(0, min(R_dep) - Penalty(CL))
-	// Relation directionality:
-	//   - componentOf: Part → Whole (source is part OF target)
-	//   - dependsOn:   Dependent → Dependency (source DEPENDS ON target)
-	// When calculating reliability for holonID:
-	//   - componentOf: find rows where target_id = holonID, dependency is source_id
-	//   - dependsOn:   find rows where source_id = holonID, dependency is target_id
-	depRows, err := c.DB.QueryContext(ctx, `
-		SELECT source_id AS dep_id, congruence_level FROM relations
-		WHERE target_id = ? AND relation_type = 'componentOf'
-		UNION
-		SELECT target_id AS dep_id, congruence_level FROM relations
-		WHERE source_id = ? AND relation_type = 'dependsOn'`, holonID, holonID)
-
+	deps, err := src.dependencies(ctx, holonID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Collect deps first to avoid holding cursor during recursive calls
-	type dep struct {
-		id string
-		cl int
-	}
-	var deps []dep
-	for depRows.Next() {
-		var d dep
-		if err := depRows.Scan(&d.id, &d.cl); err != nil {
-			continue
-		}
-		deps = append(deps, d)
-	}
-	_ = depRows.Close()
-
 	minDepScore := 1.0
 	for _, d := range deps {
-		// Recursive call for dependency with visited map for cycle detection
-		depReport, err := c.calculateReliabilityWithVisited(ctx, d.id, visited)
+		// Recursive call for dependency; the path detects cycles, the memo shares subgraphs
+		depReport, err := c.calculateReliabilityWithVisited(ctx, e, d.id)
 		if err != nil {
-			depReport = &AssuranceReport{FinalScore: 0.0}
+			depReport = &AssuranceReport{HolonID: d.id, FinalScore: 0.0, Formality: MinFormality, Scope: Scope{Empty: true}, Factors: []string{"Evaluation failed: " + err.Error()}}
 		}
 
-		// CL Penalty: CL=3 (0.0), CL=2 (0.1), CL=1 (0.4), CL=0 (0.9)
-		penalty := calculateCLPenalty(d.cl)
+		// CL Penalty: Φ(CL) from the configured profile
+		penalty := c.Penalty.Penalty(d.cl)
 		effectiveR := math.Max(0, depReport.FinalScore-penalty)
+		report.Dependencies = append(report.Dependencies, DependencyDetail{
+			Relation:   d.relation,
+			CL:         d.cl,
+			Penalty:    penalty,
+			EffectiveR: effectiveR,
+			Report:     depReport,
+		})
 
 		if effectiveR < minDepScore {
 			minDepScore = effectiveR
@@ -146,6 +394,50 @@ func (c *Calculator) calculateReliabilityWithVisited(ctx context.Context, holonI
 		if penalty > 0 {
 			report.Factors = append(report.Factors, "CL Penalty applied for "+d.id)
 		}
+
+		report.Cycles = appendCycles(report.Cycles, depReport.Cycles...)
+
+		if depReport.Formality < report.Formality {
+			report.Formality = depReport.Formality
+		}
+
+		narrowed := report.Scope.Intersect(depReport.Scope)
+		if !report.Scope.Empty && narrowed.Empty {
+			report.Factors = append(report.Factors, "Scope of "+d.id+" is disjoint from this claim's scope")
+		}
+		report.Scope = narrowed
+	}
+
+	for _, cycle := range report.Cycles {
+		report.Factors = append(report.Factors, "Cycle detected: "+strings.Join(cycle, " → ")+" (circular support counts as R 0)")
+	}
+
+	if report.Formality < report.SelfFormality {
+		report.Factors = append(report.Factors, fmt.Sprintf("Formality capped at F%d by dependencies (self F%d)", report.Formality, report.SelfFormality))
+	}
+
+	// Parallel alternatives (memberOf) do not propagate R, but the group is
+	// supported wherever one of its members is: G_eff = G_self ∩ SpanUnion(G_member)
+	members, err := src.members(ctx, holonID)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) > 0 {
+		var memberScopes []Scope
+		for _, m := range members {
+			memberReport, err := c.calculateReliabilityWithVisited(ctx, e, m)
+			if err != nil {
+				continue
+			}
+			memberScopes = append(memberScopes, memberReport.Scope)
+		}
+		if len(memberScopes) > 0 {
+			report.Scope = report.Scope.Intersect(SpanUnion(memberScopes...))
+		}
+	}
+
+	if !report.Scope.Unbounded() && report.Scope.String() != report.SelfScope.String() {
+		report.Factors = append(report.Factors, "Effective scope narrowed to: "+report.Scope.String())
 	}
 
 	hasDeps := len(deps) > 0
@@ -158,23 +450,64 @@ func (c *Calculator) calculateReliabilityWithVisited(ctx context.Context, holonI
 		report.FinalScore = report.SelfScore
 	}
 
-	// Update cache (non-critical, log warning on failure)
-	if _, err := c.DB.ExecContext(ctx, "UPDATE holons SET cached_r_score = ? WHERE id = ?", report.FinalScore, holonID); err != nil {
+	e.memo[holonID] = report
+
+	// Update cache (non-critical, log warning on failure); batch runs and
+	// projections leave it to the caller
+	if !e.cache {
+		return report, nil
+	}
+	if _, err := c.DB.ExecContext(ctx, updateCachedScore, report.FinalScore, time.Now(), holonID); err != nil {
 		report.Factors = append(report.Factors, "Warning: cache update failed")
 	}
 
 	return report, nil
 }
 
-func calculateCLPenalty(cl int) float64 {
-	switch cl {
-	case 3:
-		return 0.0
-	case 2:
-		return 0.1
-	case 1:
-		return 0.4
-	default:
-		return 0.9
+// appendCycles adds cycles not already listed
+func appendCycles(cycles [][]string, more ...[]string) [][]string {
+	for _, cycle := range more {
+		key := strings.Join(cycle, "\x00")
+		known := false
+		for _, c := range cycles {
+			if strings.Join(c, "\x00") == key {
+				known = true
+				break
+			}
+		}
+		if !known {
+			cycles = append(cycles, cycle)
+		}
+	}
+	return cycles
+}
+
+type waiver struct {
+	by    string
+	until time.Time
+}
+
+// activeWaivers returns, per evidence item, the longest waiver still in force at asOf
+func activeWaivers(rows []waiverRow, asOf time.Time) map[string]waiver {
+	active := make(map[string]waiver)
+	for _, w := range rows {
+		if !w.until.After(asOf) {
+			continue
+		}
+		if cur, ok := active[w.evidenceID]; !ok || w.until.After(cur.until) {
+			active[w.evidenceID] = w.waiver
+		}
+	}
+	return active
+}
+
+// ClampFormality bounds a formality level to the F0-F9 scale
+func ClampFormality(f int) int {
+	if f < MinFormality {
+		return MinFormality
+	}
+	if f > MaxFormality {
+		return MaxFormality
 	}
+	return f
 }
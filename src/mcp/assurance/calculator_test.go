@@ -3,6 +3,8 @@ package assurance
 import (
 	"context"
 	"database/sql"
+	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,9 +20,10 @@ func setupTestDB(t *testing.T) *sql.DB {
 	db.SetMaxOpenConns(1) // Ensure single connection to avoid issues
 
 	schema := `
-	CREATE TABLE holons (id TEXT PRIMARY KEY, cached_r_score REAL DEFAULT 0.0);
-	CREATE TABLE evidence (id TEXT PRIMARY KEY, holon_id TEXT, verdict TEXT, valid_until DATETIME);
+	CREATE TABLE holons (id TEXT PRIMARY KEY, cached_r_score REAL DEFAULT 0.0, formality INTEGER DEFAULT 0, scope TEXT, scope_slice TEXT, r_dirty INTEGER DEFAULT 1, r_computed_at DATETIME);
+	CREATE TABLE evidence (id TEXT PRIMARY KEY, holon_id TEXT, type TEXT DEFAULT 'test', verdict TEXT, assurance_level TEXT, carrier_ref TEXT, valid_until DATETIME, created_at DATETIME DEFAULT CURRENT_TIMESTAMP);
 	CREATE TABLE relations (source_id TEXT, target_id TEXT, relation_type TEXT, congruence_level INTEGER);
+	CREATE TABLE waivers (id TEXT PRIMARY KEY, evidence_id TEXT, waived_by TEXT, waived_until DATETIME, rationale TEXT);
 	`
 	if _, err := db.Exec(schema); err != nil {
 		t.Fatalf("failed to init schema: %v", err)
@@ -137,9 +140,310 @@ func TestCalculateReliability_CycleDetection(t *testing.T) {
 		t.Fatalf("CalculateReliability failed on cycle: %v", err)
 	}
 
-	// All have passing evidence, no CL penalty, cycle should not affect final score
-	// Each node has self-score 1.0, and deps also 1.0 (cycle broken by visited check)
+	// All have passing evidence, but circular support is worth nothing:
+	// the edge closing the cycle (C → A) counts as R 0 and propagates up
+	if report.FinalScore != 0.0 {
+		t.Errorf("Expected score 0.0 (cycle penalized), got %f", report.FinalScore)
+	}
+	if len(report.Cycles) != 1 || strings.Join(report.Cycles[0], ">") != "A>B>C>A" {
+		t.Errorf("Expected cycle A>B>C>A listed in report, got %v", report.Cycles)
+	}
+	if report.WeakestLink != "B" {
+		t.Errorf("Expected weakest link B on the cycle, got %q", report.WeakestLink)
+	}
+}
+
+func TestCalculateReliability_DiamondReusesScore(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// A depends on B and C, both depend on D: D is reached twice but is no cycle
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e1', 'A', 'pass', ?)", time.Now().Add(24*time.Hour))
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e2', 'B', 'pass', ?)", time.Now().Add(24*time.Hour))
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e3', 'C', 'pass', ?)", time.Now().Add(24*time.Hour))
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e4', 'D', 'degrade', ?)", time.Now().Add(24*time.Hour))
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('A', 'B', 'dependsOn', 3)")
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('A', 'C', 'dependsOn', 3)")
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('B', 'D', 'dependsOn', 3)")
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('C', 'D', 'dependsOn', 3)")
+
+	report, err := New(db).CalculateReliability(context.Background(), "A")
+	if err != nil {
+		t.Fatalf("CalculateReliability failed: %v", err)
+	}
+
+	if len(report.Cycles) != 0 {
+		t.Errorf("Diamond is not a cycle, got %v", report.Cycles)
+	}
+	for _, d := range report.Dependencies {
+		if d.Report.Cycle || d.EffectiveR != 0.5 {
+			t.Errorf("Expected %s to reuse D's score 0.5, got %.2f (cycle %v)", d.Report.HolonID, d.EffectiveR, d.Report.Cycle)
+		}
+	}
+	if report.FinalScore != 0.5 {
+		t.Errorf("Expected score 0.5 from shared dependency D, got %f", report.FinalScore)
+	}
+}
+
+func TestCalculateReliability_FormalityWeakestLink(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, _ = db.Exec("INSERT INTO holons (id, formality) VALUES ('A', 7), ('B', 2), ('C', 9)")
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e1', 'A', 'pass', ?)", time.Now().Add(24*time.Hour))
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e2', 'B', 'pass', ?)", time.Now().Add(24*time.Hour))
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e3', 'C', 'pass', ?)", time.Now().Add(24*time.Hour))
+
+	// B is component of A, A depends on C
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('B', 'A', 'componentOf', 3)")
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('A', 'C', 'dependsOn', 3)")
+
+	calc := New(db)
+	report, err := calc.CalculateReliability(context.Background(), "A")
+	if err != nil {
+		t.Fatalf("CalculateReliability failed: %v", err)
+	}
+
+	if report.SelfFormality != 7 {
+		t.Errorf("Expected self formality F7, got F%d", report.SelfFormality)
+	}
+	// Rigorous claim built on an F2 sketch is only as formal as the sketch
+	if report.Formality != 2 {
+		t.Errorf("Expected effective formality F2 (weakest link), got F%d", report.Formality)
+	}
+	if report.FinalScore != 1.0 {
+		t.Errorf("Formality must not affect R, got %f", report.FinalScore)
+	}
+}
+
+func TestCalculateReliability_FormalityUnknownHolon(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	calc := New(db)
+	report, err := calc.CalculateReliability(context.Background(), "missing")
+	if err != nil {
+		t.Fatalf("CalculateReliability failed: %v", err)
+	}
+	if report.Formality != 0 {
+		t.Errorf("Expected F0 for unknown holon, got F%d", report.Formality)
+	}
+}
+
+func TestCalculateReliability_ScopePropagation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, _ = db.Exec(`INSERT INTO holons (id, scope) VALUES
+		('api', 'services=payments,billing; env=prod'),
+		('lib', 'services=payments'),
+		('ctx', 'global'),
+		('alt-a', 'tier=enterprise'),
+		('alt-b', 'tier=free')`)
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('lib', 'api', 'componentOf', 3)")
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('alt-a', 'ctx', 'memberOf', 3)")
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('alt-b', 'ctx', 'memberOf', 3)")
+
+	calc := New(db)
+
+	// Serial: intersection
+	report, err := calc.CalculateReliability(context.Background(), "api")
+	if err != nil {
+		t.Fatalf("CalculateReliability failed: %v", err)
+	}
+	if report.Scope.String() != "env=prod; services=payments" {
+		t.Errorf("Expected intersected scope, got %s", report.Scope)
+	}
+
+	// Parallel: span union
+	report, err = calc.CalculateReliability(context.Background(), "ctx")
+	if err != nil {
+		t.Fatalf("CalculateReliability failed: %v", err)
+	}
+	if report.Scope.String() != "tier=enterprise,free" {
+		t.Errorf("Expected span-union scope, got %s", report.Scope)
+	}
+}
+
+func TestCalculateReliability_PenaltyProfile(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e1', 'A', 'pass', ?)", time.Now().Add(24*time.Hour))
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e2', 'B', 'pass', ?)", time.Now().Add(24*time.Hour))
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('B', 'A', 'componentOf', 2)")
+
+	calc := New(db)
+	calc.Penalty, _ = PenaltyProfileByName("fpf-normative")
+	report, err := calc.CalculateReliability(context.Background(), "A")
+	if err != nil {
+		t.Fatalf("CalculateReliability failed: %v", err)
+	}
+
+	if report.FinalScore != 0.5 {
+		t.Errorf("Expected score 0.5 under fpf-normative CL2 penalty, got %f", report.FinalScore)
+	}
+	if report.Penalty.Name != "fpf-normative" {
+		t.Errorf("Expected report to record profile fpf-normative, got %q", report.Penalty.Name)
+	}
+}
+
+func TestCalculateReliability_HalfLifeDecayAsOf(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	created := time.Now().Add(-24 * time.Hour)
+	_, _ = db.Exec("INSERT INTO holons (id) VALUES ('A')")
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, type, verdict, created_at) VALUES ('e1', 'A', 'test', 'pass', ?)", created)
+
+	calc := New(db)
+	calc.Decay = DecayPolicy{
+		Default: DefaultDecayModel(),
+		ByType:  map[string]DecayModel{"test": {Model: DecayHalfLife, HalfLife: 10 * 24 * time.Hour}},
+	}
+	calc.AsOf = created.Add(20 * 24 * time.Hour)
+
+	report, err := calc.CalculateReliability(context.Background(), "A")
+	if err != nil {
+		t.Fatalf("CalculateReliability failed: %v", err)
+	}
+
+	if math.Abs(report.FinalScore-0.25) > 1e-6 {
+		t.Errorf("Expected score 0.25 after two half-lives, got %f", report.FinalScore)
+	}
+	if math.Abs(report.DecayPenalty-0.75) > 1e-6 {
+		t.Errorf("Expected decay penalty 0.75, got %f", report.DecayPenalty)
+	}
+
+	var cached float64
+	_ = db.QueryRow("SELECT cached_r_score FROM holons WHERE id = 'A'").Scan(&cached)
+	if cached != 0.0 {
+		t.Errorf("Expected as-of projection not to be cached, got %f", cached)
+	}
+}
+
+func TestCalculateReliability_WaiverRestoresScore(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	expired := time.Now().Add(-24 * time.Hour)
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e1', 'A', 'pass', ?)", expired)
+	_, _ = db.Exec("INSERT INTO waivers (id, evidence_id, waived_by, waived_until, rationale) VALUES ('w-old', 'e1', 'alice', ?, 'lapsed')", time.Now().Add(-time.Hour))
+	_, _ = db.Exec("INSERT INTO waivers (id, evidence_id, waived_by, waived_until, rationale) VALUES ('w1', 'e1', 'bob', ?, 'launch')", time.Now().Add(7*24*time.Hour))
+
+	calc := New(db)
+	report, err := calc.CalculateReliability(context.Background(), "A")
+	if err != nil {
+		t.Fatalf("CalculateReliability failed: %v", err)
+	}
+
 	if report.FinalScore != 1.0 {
-		t.Errorf("Expected score 1.0 (cycle handled gracefully), got %f", report.FinalScore)
+		t.Errorf("Expected waived evidence to keep score 1.0, got %f", report.FinalScore)
+	}
+	if len(report.Waived) != 1 || report.Waived[0] != "e1" {
+		t.Errorf("Expected e1 to be reported as waived, got %v", report.Waived)
+	}
+	found := false
+	for _, f := range report.Factors {
+		if strings.HasPrefix(f, "Evidence e1 waived by bob until ") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected waiver factor, got %v", report.Factors)
+	}
+
+	// Past the waiver the expiry applies again
+	calc.AsOf = time.Now().Add(8 * 24 * time.Hour)
+	report, err = calc.CalculateReliability(context.Background(), "A")
+	if err != nil {
+		t.Fatalf("CalculateReliability failed: %v", err)
+	}
+	if report.FinalScore != 0.1 {
+		t.Errorf("Expected score 0.1 after waiver lapses, got %f", report.FinalScore)
+	}
+}
+
+func TestCalculateReliability_WeightedAggregation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	future := time.Now().Add(24 * time.Hour)
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, assurance_level, carrier_ref, valid_until) VALUES ('e1', 'A', 'pass', 'L2', 'test-runner', ?)", future)
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, assurance_level, carrier_ref, valid_until) VALUES ('e2', 'A', 'fail', 'L1', 'internal-logic', ?)", future)
+
+	calc := New(db)
+	report, err := calc.CalculateReliability(context.Background(), "A")
+	if err != nil {
+		t.Fatalf("CalculateReliability failed: %v", err)
+	}
+	if report.SelfScore != 0.5 || report.Aggregation != AggregateMean {
+		t.Errorf("Expected plain mean 0.5 by default, got %f (%s)", report.SelfScore, report.Aggregation)
+	}
+
+	calc.Aggregation.Strategy = AggregateWeightedMean
+	report, err = calc.CalculateReliability(context.Background(), "A")
+	if err != nil {
+		t.Fatalf("CalculateReliability failed: %v", err)
+	}
+	if math.Abs(report.SelfScore-0.8) > 1e-9 {
+		t.Errorf("Expected weighted mean 0.8, got %f", report.SelfScore)
+	}
+	if report.Aggregation != AggregateWeightedMean {
+		t.Errorf("Expected report to name weighted_mean, got %q", report.Aggregation)
+	}
+}
+
+func TestCachedReliability_RecomputesDirty(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, _ = db.Exec("INSERT INTO holons (id) VALUES ('A'), ('B')")
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e1', 'A', 'pass', ?)", time.Now().Add(24*time.Hour))
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('B', 'A', 'dependsOn', 3)")
+
+	calc := New(db)
+	first, err := calc.CachedReliability(ctx, "A")
+	if err != nil {
+		t.Fatalf("CachedReliability failed: %v", err)
+	}
+	if !first.Recomputed || first.R != 1.0 {
+		t.Errorf("Never-computed holon should be recomputed to 1.0, got %+v", first)
+	}
+
+	second, err := calc.CachedReliability(ctx, "A")
+	if err != nil {
+		t.Fatalf("CachedReliability failed: %v", err)
+	}
+	if second.Recomputed || second.R != 1.0 || second.ComputedAt.IsZero() {
+		t.Errorf("Clean holon should be served from cache, got %+v", second)
+	}
+
+	// New failing evidence invalidates A; B was never computed
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e2', 'A', 'fail', ?)", time.Now().Add(24*time.Hour))
+	_, _ = db.Exec("UPDATE holons SET r_dirty = 1 WHERE id = 'A'")
+
+	refreshed, err := calc.RefreshDirty(ctx)
+	if err != nil {
+		t.Fatalf("RefreshDirty failed: %v", err)
+	}
+	if strings.Join(refreshed, ",") != "A,B" {
+		t.Errorf("Expected A and B to be refreshed, got %v", refreshed)
+	}
+
+	for id, want := range map[string]float64{"A": 0.5, "B": 0.0} {
+		cached, err := calc.CachedReliability(ctx, id)
+		if err != nil {
+			t.Fatalf("CachedReliability(%s) failed: %v", id, err)
+		}
+		if cached.Recomputed || cached.R != want {
+			t.Errorf("%s: expected clean cached R %.2f, got %+v", id, want, cached)
+		}
+	}
+
+	calc.AsOf = time.Now().Add(48 * time.Hour)
+	if _, err := calc.RefreshDirty(ctx); err == nil {
+		t.Errorf("Expected projections to refuse refreshing the cache")
 	}
 }
@@ -0,0 +1,177 @@
+package assurance
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Evidence decay models (B.3.4). Step is the historical behaviour: evidence
+// is worth its full score until valid_until and the floor afterwards.
+const (
+	DecayStep     = "step"
+	DecayLinear   = "linear"
+	DecayHalfLife = "half_life"
+)
+
+// DefaultDecayFloor is what expired evidence is still worth under the step model
+const DefaultDecayFloor = 0.1
+
+// DecayModel describes how the weight of one evidence item erodes with age.
+// Age is measured from the evidence's created_at.
+type DecayModel struct {
+	Model string `json:"model"`
+	// HalfLife is the age at which a half_life item is worth half its score
+	HalfLife time.Duration `json:"half_life,omitempty"`
+	// Ramp is the age at which a linear item reaches the floor; zero means
+	// the ramp spans the evidence's own validity window (created_at..valid_until)
+	Ramp time.Duration `json:"ramp,omitempty"`
+	// Floor is the minimum weight; decayed evidence never counts for less
+	Floor float64 `json:"floor"`
+}
+
+// DecayPolicy picks a decay model per evidence type (test, research, audit_report, ...)
+type DecayPolicy struct {
+	Default DecayModel            `json:"default"`
+	ByType  map[string]DecayModel `json:"by_type,omitempty"`
+}
+
+// DefaultDecayModel keeps the step behaviour when nothing is configured
+func DefaultDecayModel() DecayModel {
+	return DecayModel{Model: DecayStep, Floor: DefaultDecayFloor}
+}
+
+// DefaultDecayPolicy applies the step model to every evidence type
+func DefaultDecayPolicy() DecayPolicy {
+	return DecayPolicy{Default: DefaultDecayModel()}
+}
+
+// ModelFor returns the model configured for an evidence type
+func (p DecayPolicy) ModelFor(evidenceType string) DecayModel {
+	if m, ok := p.ByType[strings.ToLower(evidenceType)]; ok {
+		return m
+	}
+	if p.Default.Model == "" {
+		return DefaultDecayModel()
+	}
+	return p.Default
+}
+
+// Validate checks the model name, floor and durations
+func (m DecayModel) Validate() error {
+	if m.Floor < 0 || m.Floor > 1 {
+		return fmt.Errorf("decay floor must be between 0 and 1, got %.2f", m.Floor)
+	}
+	switch m.Model {
+	case DecayStep:
+	case DecayLinear:
+		if m.Ramp < 0 {
+			return fmt.Errorf("linear decay ramp must not be negative")
+		}
+	case DecayHalfLife:
+		if m.HalfLife <= 0 {
+			return fmt.Errorf("half_life decay requires a positive half_life")
+		}
+	default:
+		return fmt.Errorf("unknown decay model %q (available: %s, %s, %s)", m.Model, DecayStep, DecayLinear, DecayHalfLife)
+	}
+	return nil
+}
+
+// Validate checks every model in the policy
+func (p DecayPolicy) Validate() error {
+	if err := p.Default.Validate(); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+	for typ, m := range p.ByType {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", typ, err)
+		}
+	}
+	return nil
+}
+
+// Weight returns the multiplier (floor..1) applied to an evidence score at asOf.
+// createdAt and validUntil may be zero when unknown.
+func (m DecayModel) Weight(createdAt, validUntil, asOf time.Time) float64 {
+	expired := !validUntil.IsZero() && asOf.After(validUntil)
+
+	switch m.Model {
+	case DecayLinear:
+		ramp := m.Ramp
+		if ramp == 0 {
+			if createdAt.IsZero() || validUntil.IsZero() {
+				return m.stepWeight(expired)
+			}
+			ramp = validUntil.Sub(createdAt)
+		}
+		if ramp <= 0 || createdAt.IsZero() {
+			return m.stepWeight(expired)
+		}
+		frac := asOf.Sub(createdAt).Seconds() / ramp.Seconds()
+		return m.clamp(1 - (1-m.Floor)*frac)
+
+	case DecayHalfLife:
+		if createdAt.IsZero() {
+			return m.stepWeight(expired)
+		}
+		age := asOf.Sub(createdAt)
+		return m.clamp(math.Pow(0.5, age.Seconds()/m.HalfLife.Seconds()))
+
+	default:
+		return m.stepWeight(expired)
+	}
+}
+
+func (m DecayModel) stepWeight(expired bool) float64 {
+	if expired {
+		return m.Floor
+	}
+	return 1.0
+}
+
+func (m DecayModel) clamp(w float64) float64 {
+	return math.Max(m.Floor, math.Min(1.0, w))
+}
+
+// String renders the model for reports
+func (m DecayModel) String() string {
+	switch m.Model {
+	case DecayLinear:
+		if m.Ramp == 0 {
+			return fmt.Sprintf("linear over validity window (floor %.2f)", m.Floor)
+		}
+		return fmt.Sprintf("linear over %s (floor %.2f)", FormatDuration(m.Ramp), m.Floor)
+	case DecayHalfLife:
+		return fmt.Sprintf("half-life %s (floor %.2f)", FormatDuration(m.HalfLife), m.Floor)
+	default:
+		return fmt.Sprintf("step at valid_until (floor %.2f)", m.Floor)
+	}
+}
+
+// ParseDuration extends time.ParseDuration with day (d) and week (w) units,
+// e.g. "30d", "2w", "36h"
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if num, ok := strings.CutSuffix(s, suffix); ok {
+			n, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+// FormatDuration renders whole days as "30d" and anything else as time.Duration does
+func FormatDuration(d time.Duration) string {
+	day := 24 * time.Hour
+	if d > 0 && d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return d.String()
+}
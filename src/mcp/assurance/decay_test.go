@@ -0,0 +1,83 @@
+package assurance
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDecayModel_Weight(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	validUntil := created.Add(100 * 24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		model    DecayModel
+		asOf     time.Time
+		expected float64
+	}{
+		{"step before expiry", DefaultDecayModel(), created.Add(99 * 24 * time.Hour), 1.0},
+		{"step after expiry", DefaultDecayModel(), validUntil.Add(time.Hour), 0.1},
+		{"linear over window midway", DecayModel{Model: DecayLinear, Floor: 0}, created.Add(50 * 24 * time.Hour), 0.5},
+		{"linear reaches floor", DecayModel{Model: DecayLinear, Floor: 0.2}, validUntil.Add(24 * time.Hour), 0.2},
+		{"linear explicit ramp", DecayModel{Model: DecayLinear, Ramp: 200 * 24 * time.Hour, Floor: 0.1}, created.Add(100 * 24 * time.Hour), 0.55},
+		{"half life", DecayModel{Model: DecayHalfLife, HalfLife: 30 * 24 * time.Hour}, created.Add(60 * 24 * time.Hour), 0.25},
+		{"half life floor", DecayModel{Model: DecayHalfLife, HalfLife: 24 * time.Hour, Floor: 0.3}, created.Add(30 * 24 * time.Hour), 0.3},
+		{"before creation", DecayModel{Model: DecayHalfLife, HalfLife: 24 * time.Hour}, created.Add(-24 * time.Hour), 1.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.model.Weight(created, validUntil, tt.asOf)
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("Expected weight %.4f, got %.4f", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDecayModel_Validate(t *testing.T) {
+	if err := (DecayModel{Model: DecayHalfLife}).Validate(); err == nil {
+		t.Errorf("Expected error for half_life without duration")
+	}
+	if err := (DecayModel{Model: "exponential"}).Validate(); err == nil {
+		t.Errorf("Expected error for unknown model")
+	}
+	if err := (DecayModel{Model: DecayStep, Floor: 1.5}).Validate(); err == nil {
+		t.Errorf("Expected error for floor above 1")
+	}
+}
+
+func TestDecayPolicy_ModelFor(t *testing.T) {
+	policy := DecayPolicy{
+		Default: DecayModel{Model: DecayLinear},
+		ByType:  map[string]DecayModel{"test": {Model: DecayHalfLife, HalfLife: time.Hour}},
+	}
+	if policy.ModelFor("Test").Model != DecayHalfLife {
+		t.Errorf("Expected per-type model for test evidence")
+	}
+	if policy.ModelFor("research").Model != DecayLinear {
+		t.Errorf("Expected default model for research evidence")
+	}
+	if (DecayPolicy{}).ModelFor("test").Model != DecayStep {
+		t.Errorf("Expected empty policy to fall back to step")
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"90d":  90 * 24 * time.Hour,
+		"2w":   14 * 24 * time.Hour,
+		"36h":  36 * time.Hour,
+		"0.5d": 12 * time.Hour,
+	}
+	for in, expected := range tests {
+		got, err := ParseDuration(in)
+		if err != nil || got != expected {
+			t.Errorf("ParseDuration(%q) = %v, %v; expected %v", in, got, err, expected)
+		}
+	}
+	if _, err := ParseDuration("soon"); err == nil {
+		t.Errorf("Expected error for invalid duration")
+	}
+}
@@ -0,0 +1,180 @@
+package assurance
+
+import (
+	"context"
+	"time"
+)
+
+// EvidenceDetail records how one evidence item contributed to the self score
+type EvidenceDetail struct {
+	ID                string        `json:"id"`
+	Type              string        `json:"type"`
+	Verdict           string        `json:"verdict"`
+	AssuranceLevel    string        `json:"assurance_level,omitempty"`
+	CarrierRef        string        `json:"carrier_ref,omitempty"`
+	RawScore          float64       `json:"raw_score"`
+	DecayModel        string        `json:"decay_model"`
+	DecayWeight       float64       `json:"decay_weight"`
+	EffectiveScore    float64       `json:"effective_score"`
+	AggregationWeight float64       `json:"aggregation_weight"`
+	ValidUntil        *time.Time    `json:"valid_until,omitempty"`
+	Expired           bool          `json:"expired"`
+	Waiver            *WaiverDetail `json:"waiver,omitempty"`
+}
+
+// WaiverDetail is the active waiver that suspended an item's decay
+type WaiverDetail struct {
+	WaivedBy    string    `json:"waived_by"`
+	WaivedUntil time.Time `json:"waived_until"`
+	// SuspendedWeight is the decay weight the item would have had without the waiver
+	SuspendedWeight float64 `json:"suspended_weight"`
+}
+
+// DependencyDetail records one WLNK edge: the child's R before and after Φ(CL)
+type DependencyDetail struct {
+	Relation   string
+	CL         int
+	Penalty    float64
+	EffectiveR float64
+	Report     *AssuranceReport
+}
+
+// Explanation is the JSON form of an AssuranceReport tree
+type Explanation struct {
+	HolonID       string            `json:"holon_id"`
+	R             float64           `json:"r_eff"`
+	SelfScore     float64           `json:"self_score"`
+	DecayPenalty  float64           `json:"decay_penalty"`
+	Aggregation   string            `json:"aggregation"`
+	Formality     int               `json:"f_eff"`
+	SelfFormality int               `json:"self_formality"`
+	Scope         string            `json:"g_eff"`
+	SelfScope     string            `json:"self_scope"`
+	LimitedBy     string            `json:"limited_by"` // "self" or "dependency"
+	WeakestLink   string            `json:"weakest_link,omitempty"`
+	Cycle         bool              `json:"cycle,omitempty"`
+	Evidence      []EvidenceDetail  `json:"evidence"`
+	Dependencies  []EdgeExplanation `json:"dependencies"`
+	Factors       []string          `json:"factors"`
+}
+
+// EdgeExplanation is one dependency edge with the child's own explanation
+type EdgeExplanation struct {
+	DependencyID  string       `json:"dependency_id"`
+	Relation      string       `json:"relation"`
+	CL            int          `json:"cl"`
+	Penalty       float64      `json:"penalty"`
+	ChildR        float64      `json:"child_r_eff"`
+	EffectiveR    float64      `json:"effective_r"`
+	OnWeakestPath bool         `json:"on_weakest_path"`
+	Child         *Explanation `json:"child"`
+}
+
+// RootExplanation wraps the tree with the settings that produced it
+type RootExplanation struct {
+	HolonID         string       `json:"holon_id"`
+	R               float64      `json:"r_eff"`
+	AsOf            time.Time    `json:"as_of"`
+	PenaltyProfile  string       `json:"cl_penalty_profile"`
+	Aggregation     string       `json:"aggregation"`
+	WeakestLinkPath []string     `json:"weakest_link_path"`
+	Cycles          [][]string   `json:"cycles,omitempty"`
+	Tree            *Explanation `json:"tree"`
+}
+
+// Explain calculates R for a holon and returns the full explanation tree
+func (c *Calculator) Explain(ctx context.Context, holonID string) (*RootExplanation, error) {
+	report, err := c.CalculateReliability(ctx, holonID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RootExplanation{
+		HolonID:         holonID,
+		R:               report.FinalScore,
+		AsOf:            report.AsOf,
+		PenaltyProfile:  report.Penalty.String(),
+		Aggregation:     c.Aggregation.String(),
+		WeakestLinkPath: report.WeakestLinkPath(),
+		Cycles:          report.Cycles,
+		Tree:            report.explain(true),
+	}, nil
+}
+
+// limitedByDependency reports whether R_eff comes from a dependency edge
+// rather than the holon's own evidence
+func (r *AssuranceReport) limitedByDependency() (DependencyDetail, bool) {
+	var weakest DependencyDetail
+	found := false
+	for _, d := range r.Dependencies {
+		// Strict comparison keeps the first minimum, as WeakestLink does
+		if !found || d.EffectiveR < weakest.EffectiveR {
+			weakest, found = d, true
+		}
+	}
+	if !found || weakest.EffectiveR >= r.SelfScore {
+		return DependencyDetail{}, false
+	}
+	return weakest, true
+}
+
+// WeakestLinkPath follows the edges that bound R_eff, from this holon down to
+// the holon whose own evidence (or missing evidence) sets the score
+func (r *AssuranceReport) WeakestLinkPath() []string {
+	path := []string{r.HolonID}
+	for cur := r; ; {
+		d, ok := cur.limitedByDependency()
+		if !ok {
+			return path
+		}
+		cur = d.Report
+		path = append(path, cur.HolonID)
+	}
+}
+
+// explain converts the report; onPath marks a node on the weakest link path
+func (r *AssuranceReport) explain(onPath bool) *Explanation {
+	e := &Explanation{
+		HolonID:       r.HolonID,
+		R:             r.FinalScore,
+		SelfScore:     r.SelfScore,
+		DecayPenalty:  r.DecayPenalty,
+		Aggregation:   r.Aggregation,
+		Formality:     r.Formality,
+		SelfFormality: r.SelfFormality,
+		Scope:         r.Scope.String(),
+		SelfScope:     r.SelfScope.String(),
+		LimitedBy:     "self",
+		WeakestLink:   r.WeakestLink,
+		Cycle:         r.Cycle,
+		Evidence:      r.Evidence,
+		Dependencies:  []EdgeExplanation{},
+		Factors:       r.Factors,
+	}
+	if e.Evidence == nil {
+		e.Evidence = []EvidenceDetail{}
+	}
+	if e.Factors == nil {
+		e.Factors = []string{}
+	}
+
+	limit, limited := r.limitedByDependency()
+	if limited {
+		e.LimitedBy = "dependency"
+	}
+
+	for _, d := range r.Dependencies {
+		edgeOnPath := onPath && limited && d.Report == limit.Report
+		e.Dependencies = append(e.Dependencies, EdgeExplanation{
+			DependencyID:  d.Report.HolonID,
+			Relation:      d.Relation,
+			CL:            d.CL,
+			Penalty:       d.Penalty,
+			ChildR:        d.Report.FinalScore,
+			EffectiveR:    d.EffectiveR,
+			OnWeakestPath: edgeOnPath,
+			Child:         d.Report.explain(edgeOnPath),
+		})
+	}
+	return e
+}
@@ -0,0 +1,101 @@
+package assurance
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExplain_WeakestLinkPath(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// A <- B (CL2) <- C (CL3, failing), A <- D (CL3, passing)
+	future := time.Now().Add(24 * time.Hour)
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('ea', 'A', 'pass', ?)", future)
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('eb', 'B', 'pass', ?)", future)
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('ec', 'C', 'degrade', ?)", future)
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('ed', 'D', 'pass', ?)", future)
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('B', 'A', 'componentOf', 2)")
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('C', 'B', 'componentOf', 3)")
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('A', 'D', 'dependsOn', 3)")
+
+	calc := New(db)
+	explanation, err := calc.Explain(context.Background(), "A")
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(explanation.WeakestLinkPath, []string{"A", "B", "C"}) {
+		t.Errorf("Expected weakest link path A→B→C, got %v", explanation.WeakestLinkPath)
+	}
+
+	root := explanation.Tree
+	if root.LimitedBy != "dependency" || len(root.Dependencies) != 2 {
+		t.Fatalf("Expected root limited by one of two dependencies, got %+v", root)
+	}
+	if len(root.Evidence) != 1 || root.Evidence[0].ID != "ea" || root.Evidence[0].RawScore != 1.0 {
+		t.Errorf("Expected root evidence ea with raw score 1.0, got %+v", root.Evidence)
+	}
+
+	for _, edge := range root.Dependencies {
+		switch edge.DependencyID {
+		case "B":
+			if edge.Relation != "componentOf" || edge.CL != 2 || edge.Penalty != 0.1 || !edge.OnWeakestPath {
+				t.Errorf("Unexpected edge to B: %+v", edge)
+			}
+			if edge.ChildR != 0.5 || edge.EffectiveR != 0.4 {
+				t.Errorf("Expected child R 0.5 and effective R 0.4 for B, got %.2f/%.2f", edge.ChildR, edge.EffectiveR)
+			}
+			if edge.Child.LimitedBy != "dependency" || !edge.Child.Dependencies[0].OnWeakestPath {
+				t.Errorf("Expected B to be limited by C on the weakest path, got %+v", edge.Child)
+			}
+		case "D":
+			if edge.Relation != "dependsOn" || edge.OnWeakestPath {
+				t.Errorf("Unexpected edge to D: %+v", edge)
+			}
+		default:
+			t.Errorf("Unexpected dependency %s", edge.DependencyID)
+		}
+	}
+
+	data, err := json.Marshal(explanation)
+	if err != nil {
+		t.Fatalf("Explanation does not encode: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded["holon_id"] != "A" {
+		t.Errorf("Expected JSON with holon_id A, got %s", data)
+	}
+}
+
+func TestExplain_EvidenceDecayAndWaiver(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	expired := time.Now().Add(-24 * time.Hour)
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e1', 'A', 'pass', ?)", expired)
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e2', 'A', 'pass', ?)", expired)
+	_, _ = db.Exec("INSERT INTO waivers (id, evidence_id, waived_by, waived_until, rationale) VALUES ('w1', 'e2', 'bob', ?, 'launch')", time.Now().Add(24*time.Hour))
+
+	explanation, err := New(db).Explain(context.Background(), "A")
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+
+	byID := make(map[string]EvidenceDetail)
+	for _, e := range explanation.Tree.Evidence {
+		byID[e.ID] = e
+	}
+	if e := byID["e1"]; !e.Expired || e.DecayWeight != 0.1 || e.EffectiveScore != 0.1 || e.Waiver != nil {
+		t.Errorf("Expected e1 expired with decay weight 0.1, got %+v", e)
+	}
+	if e := byID["e2"]; e.Waiver == nil || e.Waiver.WaivedBy != "bob" || e.Waiver.SuspendedWeight != 0.1 || e.EffectiveScore != 1.0 {
+		t.Errorf("Expected e2 waived by bob at full score, got %+v", e)
+	}
+	if !reflect.DeepEqual(explanation.WeakestLinkPath, []string{"A"}) {
+		t.Errorf("Expected path to stop at A, got %v", explanation.WeakestLinkPath)
+	}
+}
@@ -0,0 +1,336 @@
+package assurance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// graphSource supplies the holon graph to the calculator. sqlSource queries
+// the database per holon; snapshot holds the whole graph in memory for
+// batch recomputation.
+type graphSource interface {
+	holonAttrs(ctx context.Context, holonID string) (holonAttrs, error)
+	evidence(ctx context.Context, holonID string) ([]evidenceRow, error)
+	waivers(ctx context.Context, holonID string) ([]waiverRow, error)
+	dependencies(ctx context.Context, holonID string) ([]depEdge, error)
+	members(ctx context.Context, holonID string) ([]string, error)
+}
+
+type holonAttrs struct {
+	formality int
+	scope     Scope
+	scopeNote string // Set when the declared scope could not be parsed
+}
+
+// evidenceRow is one evidence item; zero times stand for NULL columns
+type evidenceRow struct {
+	id             string
+	evidenceType   string
+	verdict        string
+	assuranceLevel string
+	carrierRef     string
+	validUntil     time.Time
+	createdAt      time.Time
+}
+
+// waiverRow is one waiver on an evidence item of the holon
+type waiverRow struct {
+	evidenceID string
+	waiver
+}
+
+// depEdge is a WLNK edge from a holon to one of its dependencies
+type depEdge struct {
+	id       string
+	relation string
+	cl       int
+}
+
+// parseHolonAttrs resolves the declared F and G of a holon. Free-text scopes
+// are treated as unbounded.
+func parseHolonAttrs(f sql.NullInt64, scopeText, scopeSlice sql.NullString) holonAttrs {
+	attrs := holonAttrs{formality: ClampFormality(int(f.Int64))}
+	var err error
+	if scopeSlice.Valid && scopeSlice.String != "" {
+		if attrs.scope, err = DecodeScope(scopeSlice.String); err == nil {
+			return attrs
+		}
+	}
+	if attrs.scope, err = ParseScope(scopeText.String); err != nil {
+		attrs.scope = Scope{}
+		attrs.scopeNote = fmt.Sprintf("Scope %q is free text, treated as unbounded", scopeText.String)
+	}
+	return attrs
+}
+
+func scanEvidence(rows *sql.Rows) (string, evidenceRow, error) {
+	var holonID string
+	var e evidenceRow
+	var assuranceLevel, carrierRef sql.NullString
+	var validUntil, createdAt sql.NullTime
+	if err := rows.Scan(&holonID, &e.id, &e.evidenceType, &e.verdict, &assuranceLevel, &carrierRef, &validUntil, &createdAt); err != nil {
+		return "", evidenceRow{}, err
+	}
+	e.assuranceLevel = assuranceLevel.String
+	e.carrierRef = carrierRef.String
+	e.validUntil = validUntil.Time
+	e.createdAt = createdAt.Time
+	return holonID, e, nil
+}
+
+const evidenceColumns = "holon_id, id, type, verdict, assurance_level, carrier_ref, valid_until, created_at"
+
+// sqlSource reads the graph from the database one holon at a time
+type sqlSource struct {
+	db *sql.DB
+}
+
+// holonAttrs reads the declared F and G of a holon. Unknown holons are F0
+// with an unbounded scope.
+func (s sqlSource) holonAttrs(ctx context.Context, holonID string) (holonAttrs, error) {
+	var f sql.NullInt64
+	var scopeText, scopeSlice sql.NullString
+	err := s.db.QueryRowContext(ctx, "SELECT formality, scope, scope_slice FROM holons WHERE id = ?", holonID).Scan(&f, &scopeText, &scopeSlice)
+	if errors.Is(err, sql.ErrNoRows) {
+		return holonAttrs{formality: MinFormality}, nil
+	}
+	if err != nil {
+		return holonAttrs{}, err
+	}
+	return parseHolonAttrs(f, scopeText, scopeSlice), nil
+}
+
+func (s sqlSource) evidence(ctx context.Context, holonID string) ([]evidenceRow, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+evidenceColumns+" FROM evidence WHERE holon_id = ?", holonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var items []evidenceRow
+	for rows.Next() {
+		_, e, err := scanEvidence(rows)
+		if err != nil {
+			continue
+		}
+		items = append(items, e)
+	}
+	return items, rows.Err()
+}
+
+func (s sqlSource) waivers(ctx context.Context, holonID string) ([]waiverRow, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT w.evidence_id, w.waived_by, w.waived_until FROM waivers w
+		JOIN evidence e ON e.id = w.evidence_id
+		WHERE e.holon_id = ?`, holonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var items []waiverRow
+	for rows.Next() {
+		var w waiverRow
+		if err := rows.Scan(&w.evidenceID, &w.by, &w.until); err != nil {
+			continue
+		}
+		items = append(items, w)
+	}
+	return items, rows.Err()
+}
+
+// dependencies follows the WLNK edges of a holon.
+// Relation directionality:
+//   - componentOf: Part → Whole (source is part OF target)
+//   - dependsOn:   Dependent → Dependency (source DEPENDS ON target)
+//
+// When calculating reliability for holonID:
+//   - componentOf: find rows where target_id = holonID, dependency is source_id
+//   - dependsOn:   find rows where source_id = holonID, dependency is target_id
+func (s sqlSource) dependencies(ctx context.Context, holonID string) ([]depEdge, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT source_id AS dep_id, relation_type, congruence_level FROM relations
+		WHERE target_id = ? AND relation_type = 'componentOf'
+		UNION
+		SELECT target_id AS dep_id, relation_type, congruence_level FROM relations
+		WHERE source_id = ? AND relation_type = 'dependsOn'
+		ORDER BY dep_id, relation_type, congruence_level`, holonID, holonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var deps []depEdge
+	for rows.Next() {
+		var d depEdge
+		if err := rows.Scan(&d.id, &d.relation, &d.cl); err != nil {
+			continue
+		}
+		deps = append(deps, d)
+	}
+	return deps, rows.Err()
+}
+
+func (s sqlSource) members(ctx context.Context, holonID string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT source_id FROM relations WHERE target_id = ? AND relation_type = 'memberOf'", holonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var members []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			continue
+		}
+		members = append(members, id)
+	}
+	return members, rows.Err()
+}
+
+// snapshot is the whole holon graph loaded with one query per table
+type snapshot struct {
+	ids      []string // Sorted holon IDs
+	attrs    map[string]holonAttrs
+	evid     map[string][]evidenceRow
+	waiv     map[string][]waiverRow
+	deps     map[string][]depEdge
+	memberOf map[string][]string
+}
+
+// loadSnapshot reads holons, evidence, waivers and relations in one pass each
+func loadSnapshot(ctx context.Context, db *sql.DB) (*snapshot, error) {
+	s := &snapshot{
+		attrs:    make(map[string]holonAttrs),
+		evid:     make(map[string][]evidenceRow),
+		waiv:     make(map[string][]waiverRow),
+		deps:     make(map[string][]depEdge),
+		memberOf: make(map[string][]string),
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT id, formality, scope, scope_slice FROM holons")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var id string
+		var f sql.NullInt64
+		var scopeText, scopeSlice sql.NullString
+		if err := rows.Scan(&id, &f, &scopeText, &scopeSlice); err != nil {
+			continue
+		}
+		s.ids = append(s.ids, id)
+		s.attrs[id] = parseHolonAttrs(f, scopeText, scopeSlice)
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, err
+	}
+	sort.Strings(s.ids)
+
+	rows, err = db.QueryContext(ctx, "SELECT "+evidenceColumns+" FROM evidence")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		holonID, e, err := scanEvidence(rows)
+		if err != nil {
+			continue
+		}
+		s.evid[holonID] = append(s.evid[holonID], e)
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT e.holon_id, w.evidence_id, w.waived_by, w.waived_until FROM waivers w
+		JOIN evidence e ON e.id = w.evidence_id`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var holonID string
+		var w waiverRow
+		if err := rows.Scan(&holonID, &w.evidenceID, &w.by, &w.until); err != nil {
+			continue
+		}
+		s.waiv[holonID] = append(s.waiv[holonID], w)
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, err
+	}
+
+	// Same directionality and order as sqlSource.dependencies; DISTINCT mirrors its UNION
+	rows, err = db.QueryContext(ctx, `
+		SELECT DISTINCT source_id, target_id, relation_type, congruence_level FROM relations
+		WHERE relation_type IN ('componentOf', 'dependsOn', 'memberOf')`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var source, target, relation string
+		var cl int
+		if err := rows.Scan(&source, &target, &relation, &cl); err != nil {
+			continue
+		}
+		switch relation {
+		case "componentOf":
+			s.deps[target] = append(s.deps[target], depEdge{id: source, relation: relation, cl: cl})
+		case "dependsOn":
+			s.deps[source] = append(s.deps[source], depEdge{id: target, relation: relation, cl: cl})
+		case "memberOf":
+			s.memberOf[target] = append(s.memberOf[target], source)
+		}
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, err
+	}
+	for _, deps := range s.deps {
+		sort.Slice(deps, func(i, j int) bool {
+			if deps[i].id != deps[j].id {
+				return deps[i].id < deps[j].id
+			}
+			if deps[i].relation != deps[j].relation {
+				return deps[i].relation < deps[j].relation
+			}
+			return deps[i].cl < deps[j].cl
+		})
+	}
+	return s, nil
+}
+
+func closeRows(rows *sql.Rows) error {
+	err := rows.Err()
+	if cerr := rows.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (s *snapshot) holonAttrs(_ context.Context, holonID string) (holonAttrs, error) {
+	if attrs, ok := s.attrs[holonID]; ok {
+		return attrs, nil
+	}
+	return holonAttrs{formality: MinFormality}, nil
+}
+
+func (s *snapshot) evidence(_ context.Context, holonID string) ([]evidenceRow, error) {
+	return s.evid[holonID], nil
+}
+
+func (s *snapshot) waivers(_ context.Context, holonID string) ([]waiverRow, error) {
+	return s.waiv[holonID], nil
+}
+
+func (s *snapshot) dependencies(_ context.Context, holonID string) ([]depEdge, error) {
+	return s.deps[holonID], nil
+}
+
+func (s *snapshot) members(_ context.Context, holonID string) ([]string, error) {
+	return s.memberOf[holonID], nil
+}
@@ -0,0 +1,126 @@
+package assurance
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCalculateAll_MatchesPerHolon(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	future := time.Now().Add(24 * time.Hour)
+	past := time.Now().Add(-24 * time.Hour)
+
+	// A and B share the subgraph C → D; G groups A and B as alternatives
+	_, _ = db.Exec("INSERT INTO holons (id, formality, scope) VALUES ('A', 5, 'env=prod'), ('B', 3, ''), ('C', 4, ''), ('D', 2, ''), ('G', 6, '')")
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e1', 'A', 'pass', ?)", future)
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e2', 'B', 'pass', ?)", future)
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e3', 'C', 'degrade', ?)", future)
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e4', 'D', 'pass', ?)", past)
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e5', 'D', 'pass', ?)", past)
+	_, _ = db.Exec("INSERT INTO waivers (id, evidence_id, waived_by, waived_until) VALUES ('w1', 'e5', 'alice', ?)", future)
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('A', 'C', 'dependsOn', 3)")
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('B', 'C', 'dependsOn', 1)")
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('D', 'C', 'componentOf', 2)")
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('A', 'G', 'memberOf', 3)")
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('B', 'G', 'memberOf', 3)")
+	// Dangling dependency: X has no holon row
+	_, _ = db.Exec("INSERT INTO relations (source_id, target_id, relation_type, congruence_level) VALUES ('G', 'X', 'dependsOn', 3)")
+
+	ctx := context.Background()
+	calc := New(db)
+	reports, err := calc.CalculateAll(ctx)
+	if err != nil {
+		t.Fatalf("CalculateAll failed: %v", err)
+	}
+	if len(reports) != 5 {
+		t.Fatalf("Expected reports for the 5 stored holons, got %d", len(reports))
+	}
+	if _, ok := reports["X"]; ok {
+		t.Errorf("Dangling dependency X should not be reported")
+	}
+
+	for id, batch := range reports {
+		var cached float64
+		if err := db.QueryRow("SELECT cached_r_score FROM holons WHERE id = ?", id).Scan(&cached); err != nil {
+			t.Fatalf("failed to read cache for %s: %v", id, err)
+		}
+		if cached != batch.FinalScore {
+			t.Errorf("%s: cached_r_score %.3f, batch R %.3f", id, cached, batch.FinalScore)
+		}
+	}
+
+	for id, batch := range reports {
+		single, err := calc.CalculateReliability(ctx, id)
+		if err != nil {
+			t.Fatalf("CalculateReliability(%s) failed: %v", id, err)
+		}
+		if single.FinalScore != batch.FinalScore || single.Formality != batch.Formality || single.Scope.String() != batch.Scope.String() {
+			t.Errorf("%s: batch (R %.3f, F%d, %s) differs from single (R %.3f, F%d, %s)", id,
+				batch.FinalScore, batch.Formality, batch.Scope, single.FinalScore, single.Formality, single.Scope)
+		}
+		if !reflect.DeepEqual(single.Factors, batch.Factors) {
+			t.Errorf("%s: batch factors %v differ from single %v", id, batch.Factors, single.Factors)
+		}
+	}
+
+	// Shared dependency is evaluated once and reused by both parents
+	if reports["A"].Dependencies[0].Report != reports["B"].Dependencies[0].Report {
+		t.Errorf("Expected A and B to share the memoized report for C")
+	}
+}
+
+func TestCalculateAll_AsOfDoesNotWriteCache(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, _ = db.Exec("INSERT INTO holons (id, cached_r_score) VALUES ('A', 0.42)")
+	_, _ = db.Exec("INSERT INTO evidence (id, holon_id, verdict, valid_until) VALUES ('e1', 'A', 'pass', ?)", time.Now().Add(24*time.Hour))
+
+	calc := New(db)
+	calc.AsOf = time.Now().Add(48 * time.Hour)
+	reports, err := calc.CalculateAll(context.Background())
+	if err != nil {
+		t.Fatalf("CalculateAll failed: %v", err)
+	}
+	if reports["A"].FinalScore != 0.1 {
+		t.Errorf("Expected projected R 0.1 after expiry, got %.3f", reports["A"].FinalScore)
+	}
+
+	var cached float64
+	_ = db.QueryRow("SELECT cached_r_score FROM holons WHERE id = 'A'").Scan(&cached)
+	if cached != 0.42 {
+		t.Errorf("Projection should not touch the cache, got %.3f", cached)
+	}
+}
+
+func TestCalculateAll_ProgressAndCancellation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	_, _ = db.Exec("INSERT INTO holons (id) VALUES ('A'), ('B'), ('C')")
+
+	var steps []int
+	calc := New(db)
+	calc.Progress = func(done, total int) {
+		if total != 3 {
+			t.Errorf("Expected a total of 3, got %d", total)
+		}
+		steps = append(steps, done)
+	}
+	if _, err := calc.CalculateAll(context.Background()); err != nil {
+		t.Fatalf("CalculateAll failed: %v", err)
+	}
+	if !reflect.DeepEqual(steps, []int{1, 2, 3}) {
+		t.Errorf("Expected progress after each holon, got %v", steps)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := calc.CalculateAll(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled recomputation to stop, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package assurance
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Congruence levels (B.3): CL3 is the same context, CL0 an unrelated one
+const (
+	MinCL = 0
+	MaxCL = 3
+)
+
+// PenaltyProfile is a congruence penalty function Φ(CL), B.1.3.
+// Table is indexed by CL; the penalty is subtracted from the dependency's R.
+type PenaltyProfile struct {
+	Name  string     `json:"name"`
+	Table [4]float64 `json:"table"`
+}
+
+// DefaultPenaltyProfileName keeps the historical quint-code table
+const DefaultPenaltyProfileName = "default"
+
+var penaltyProfiles = map[string][4]float64{
+	DefaultPenaltyProfileName: {0.9, 0.4, 0.1, 0.0},
+	"fpf-normative":           {1.0, 1.0, 0.5, 0.0},
+	"lenient":                 {0.5, 0.2, 0.05, 0.0},
+	"strict":                  {1.0, 0.6, 0.25, 0.0},
+}
+
+// DefaultPenaltyProfile returns the profile used when nothing is configured
+func DefaultPenaltyProfile() PenaltyProfile {
+	p, _ := PenaltyProfileByName(DefaultPenaltyProfileName)
+	return p
+}
+
+// PenaltyProfileByName returns a built-in profile
+func PenaltyProfileByName(name string) (PenaltyProfile, bool) {
+	table, ok := penaltyProfiles[strings.ToLower(name)]
+	if !ok {
+		return PenaltyProfile{}, false
+	}
+	return PenaltyProfile{Name: strings.ToLower(name), Table: table}, true
+}
+
+// PenaltyProfileNames lists the built-in profiles, sorted
+func PenaltyProfileNames() []string {
+	names := make([]string, 0, len(penaltyProfiles))
+	for name := range penaltyProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks that penalties lie in [0,1] and never grow with congruence
+func (p PenaltyProfile) Validate() error {
+	for cl, v := range p.Table {
+		if v < 0 || v > 1 {
+			return fmt.Errorf("penalty for CL%d must be between 0 and 1, got %.2f", cl, v)
+		}
+		if cl > 0 && v > p.Table[cl-1] {
+			return fmt.Errorf("penalty for CL%d (%.2f) exceeds penalty for CL%d (%.2f)", cl, v, cl-1, p.Table[cl-1])
+		}
+	}
+	return nil
+}
+
+// Penalty returns Φ(CL); out-of-range levels are clamped to CL0..CL3
+func (p PenaltyProfile) Penalty(cl int) float64 {
+	if cl < MinCL {
+		cl = MinCL
+	}
+	if cl > MaxCL {
+		cl = MaxCL
+	}
+	return p.Table[cl]
+}
+
+// String renders the profile name with its table for reports
+func (p PenaltyProfile) String() string {
+	return fmt.Sprintf("%s (CL0=%.2f CL1=%.2f CL2=%.2f CL3=%.2f)", p.Name, p.Table[0], p.Table[1], p.Table[2], p.Table[3])
+}
@@ -0,0 +1,43 @@
+package assurance
+
+import "testing"
+
+func TestPenaltyProfileByName(t *testing.T) {
+	p, ok := PenaltyProfileByName("FPF-Normative")
+	if !ok {
+		t.Fatalf("Expected fpf-normative profile to exist")
+	}
+	if p.Penalty(2) != 0.5 || p.Penalty(1) != 1.0 || p.Penalty(3) != 0.0 {
+		t.Errorf("Unexpected fpf-normative table: %v", p.Table)
+	}
+
+	if _, ok := PenaltyProfileByName("nonexistent"); ok {
+		t.Errorf("Expected unknown profile lookup to fail")
+	}
+
+	for _, name := range PenaltyProfileNames() {
+		p, _ := PenaltyProfileByName(name)
+		if err := p.Validate(); err != nil {
+			t.Errorf("Built-in profile %s is invalid: %v", name, err)
+		}
+	}
+}
+
+func TestPenaltyProfile_Validate(t *testing.T) {
+	bad := PenaltyProfile{Name: "custom", Table: [4]float64{0.2, 0.5, 0.1, 0.0}}
+	if err := bad.Validate(); err == nil {
+		t.Errorf("Expected error when penalty grows with congruence")
+	}
+
+	outOfRange := PenaltyProfile{Name: "custom", Table: [4]float64{1.5, 0.5, 0.1, 0.0}}
+	if err := outOfRange.Validate(); err == nil {
+		t.Errorf("Expected error for penalty above 1")
+	}
+}
+
+func TestPenaltyProfile_ClampsLevels(t *testing.T) {
+	p := DefaultPenaltyProfile()
+	if p.Penalty(-1) != p.Table[0] || p.Penalty(7) != p.Table[3] {
+		t.Errorf("Expected out-of-range CL to clamp to CL0/CL3")
+	}
+}
@@ -0,0 +1,267 @@
+package assurance
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Scope is a structured ClaimScope (G, A.2.6): a context slice given as a set
+// of allowed values per dimension, e.g. services={payments,billing}, env={prod}.
+// A dimension that is absent is unconstrained. A Scope with no dimensions is
+// unbounded (the claim holds everywhere); Empty marks a slice with no
+// supported context left, typically produced by intersecting disjoint scopes.
+type Scope struct {
+	Dims  map[string][]string
+	Empty bool
+}
+
+// Unbounded reports whether the scope places no constraint on any dimension
+func (s Scope) Unbounded() bool {
+	return !s.Empty && len(s.Dims) == 0
+}
+
+// ParseScope parses a scope declaration. Accepted forms:
+//
+//	global | any | * | ""                  unbounded
+//	services=payments,billing; env=prod    key=values pairs separated by ';' or newlines
+//	services: payments, billing            ':' may be used instead of '='
+//	{"services": ["payments"], "env": "prod"}
+//
+// Keys and values are case-insensitive. Free text that does not follow one of
+// these forms returns an error; callers decide how to treat it.
+func ParseScope(raw string) (Scope, error) {
+	text := strings.TrimSpace(raw)
+	switch strings.ToLower(text) {
+	case "", "global", "any", "all", "*":
+		return Scope{}, nil
+	}
+
+	if strings.HasPrefix(text, "{") {
+		return parseScopeJSON(text)
+	}
+
+	dims := make(map[string][]string)
+	segments := strings.FieldsFunc(text, func(r rune) bool { return r == ';' || r == '\n' })
+	for _, seg := range segments {
+		seg = strings.TrimSpace(seg)
+		if seg == "" {
+			continue
+		}
+		sep := strings.IndexAny(seg, "=:")
+		if sep <= 0 {
+			return Scope{}, fmt.Errorf("scope segment %q is not of the form key=value[,value]", seg)
+		}
+		key := normalizeScopeToken(seg[:sep])
+		if key == "" {
+			return Scope{}, fmt.Errorf("scope segment %q has an empty dimension", seg)
+		}
+		for _, v := range strings.Split(seg[sep+1:], ",") {
+			if v = normalizeScopeToken(v); v != "" {
+				dims[key] = append(dims[key], v)
+			}
+		}
+		if len(dims[key]) == 0 {
+			return Scope{}, fmt.Errorf("scope dimension %q has no values", key)
+		}
+	}
+
+	return newScope(dims), nil
+}
+
+func parseScopeJSON(text string) (Scope, error) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &obj); err != nil {
+		return Scope{}, fmt.Errorf("invalid scope JSON: %w", err)
+	}
+
+	dims := make(map[string][]string)
+	for k, v := range obj {
+		key := normalizeScopeToken(k)
+		switch val := v.(type) {
+		case string:
+			if n := normalizeScopeToken(val); n != "" {
+				dims[key] = append(dims[key], n)
+			}
+		case []interface{}:
+			for _, item := range val {
+				str, ok := item.(string)
+				if !ok {
+					return Scope{}, fmt.Errorf("scope dimension %q must contain strings", k)
+				}
+				if n := normalizeScopeToken(str); n != "" {
+					dims[key] = append(dims[key], n)
+				}
+			}
+		default:
+			return Scope{}, fmt.Errorf("scope dimension %q must be a string or list of strings", k)
+		}
+		if len(dims[key]) == 0 {
+			return Scope{}, fmt.Errorf("scope dimension %q has no values", k)
+		}
+	}
+
+	return newScope(dims), nil
+}
+
+func normalizeScopeToken(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// newScope sorts and de-duplicates values so equal scopes encode identically
+func newScope(dims map[string][]string) Scope {
+	if len(dims) == 0 {
+		return Scope{}
+	}
+	out := make(map[string][]string, len(dims))
+	for k, vals := range dims {
+		seen := make(map[string]bool, len(vals))
+		var uniq []string
+		for _, v := range vals {
+			if !seen[v] {
+				seen[v] = true
+				uniq = append(uniq, v)
+			}
+		}
+		sort.Strings(uniq)
+		out[k] = uniq
+	}
+	return Scope{Dims: out}
+}
+
+// Intersect narrows the scope for serial composition: a whole built from
+// parts is only supported where every part is supported.
+func (s Scope) Intersect(o Scope) Scope {
+	if s.Empty || o.Empty {
+		return Scope{Empty: true}
+	}
+
+	dims := make(map[string][]string)
+	for k, v := range s.Dims {
+		dims[k] = v
+	}
+	for k, ov := range o.Dims {
+		sv, ok := dims[k]
+		if !ok {
+			dims[k] = ov
+			continue
+		}
+		allowed := make(map[string]bool, len(ov))
+		for _, v := range ov {
+			allowed[v] = true
+		}
+		var common []string
+		for _, v := range sv {
+			if allowed[v] {
+				common = append(common, v)
+			}
+		}
+		if len(common) == 0 {
+			return Scope{Empty: true}
+		}
+		dims[k] = common
+	}
+
+	return newScope(dims)
+}
+
+// SpanUnion widens the scope for parallel alternatives: the group is
+// supported wherever at least one alternative is. The union is taken per
+// dimension, so it is the smallest slice spanning all members.
+func SpanUnion(scopes ...Scope) Scope {
+	var live []Scope
+	for _, sc := range scopes {
+		if !sc.Empty {
+			live = append(live, sc)
+		}
+	}
+	if len(live) == 0 {
+		return Scope{Empty: true}
+	}
+
+	dims := make(map[string][]string)
+	for k, v := range live[0].Dims {
+		dims[k] = append([]string(nil), v...)
+	}
+	for _, sc := range live[1:] {
+		for k := range dims {
+			v, ok := sc.Dims[k]
+			if !ok {
+				// Unconstrained in one alternative means unconstrained in the span
+				delete(dims, k)
+				continue
+			}
+			dims[k] = append(dims[k], v...)
+		}
+	}
+
+	return newScope(dims)
+}
+
+// Covers reports whether every context in o is also in s
+func (s Scope) Covers(o Scope) bool {
+	if o.Empty {
+		return true
+	}
+	if s.Empty {
+		return false
+	}
+	for k, sv := range s.Dims {
+		ov, ok := o.Dims[k]
+		if !ok {
+			return false
+		}
+		allowed := make(map[string]bool, len(sv))
+		for _, v := range sv {
+			allowed[v] = true
+		}
+		for _, v := range ov {
+			if !allowed[v] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String renders the scope in the canonical key=values form accepted by ParseScope
+func (s Scope) String() string {
+	if s.Empty {
+		return "none (no supported context)"
+	}
+	if len(s.Dims) == 0 {
+		return "global"
+	}
+	keys := make([]string, 0, len(s.Dims))
+	for k := range s.Dims {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+strings.Join(s.Dims[k], ","))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Encode returns the canonical JSON stored in holons.scope_slice
+func (s Scope) Encode() string {
+	if s.Dims == nil {
+		return "{}"
+	}
+	data, err := json.Marshal(s.Dims)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// DecodeScope reads a scope previously produced by Encode
+func DecodeScope(encoded string) (Scope, error) {
+	var dims map[string][]string
+	if err := json.Unmarshal([]byte(encoded), &dims); err != nil {
+		return Scope{}, fmt.Errorf("invalid stored scope: %w", err)
+	}
+	return newScope(dims), nil
+}
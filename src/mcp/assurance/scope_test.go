@@ -0,0 +1,104 @@
+package assurance
+
+import "testing"
+
+func TestParseScope(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"", "global", false},
+		{"global", "global", false},
+		{"services=payments,billing; env=prod", "env=prod; services=billing,payments", false},
+		{"Services: Payments\nEnv: prod", "env=prod; services=payments", false},
+		{`{"services": ["payments"], "env": "prod"}`, "env=prod; services=payments", false},
+		{"env=prod,prod", "env=prod", false},
+		{"backend", "", true},
+		{"env=", "", true},
+		{`{"env": 3}`, "", true},
+	}
+
+	for _, tt := range tests {
+		sc, err := ParseScope(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseScope(%q) expected error, got %s", tt.input, sc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseScope(%q) unexpected error: %v", tt.input, err)
+			continue
+		}
+		if sc.String() != tt.expected {
+			t.Errorf("ParseScope(%q) = %q, expected %q", tt.input, sc.String(), tt.expected)
+		}
+	}
+}
+
+func TestScope_Intersect(t *testing.T) {
+	a, _ := ParseScope("services=payments,billing; env=prod")
+	b, _ := ParseScope("services=payments,search; tier=enterprise")
+
+	got := a.Intersect(b)
+	if got.String() != "env=prod; services=payments; tier=enterprise" {
+		t.Errorf("Unexpected intersection: %s", got)
+	}
+
+	disjoint, _ := ParseScope("env=staging")
+	if !a.Intersect(disjoint).Empty {
+		t.Errorf("Expected empty intersection for disjoint env")
+	}
+
+	if a.Intersect(Scope{}).String() != a.String() {
+		t.Errorf("Intersecting with unbounded scope must not narrow")
+	}
+}
+
+func TestScope_SpanUnion(t *testing.T) {
+	a, _ := ParseScope("services=payments; env=prod")
+	b, _ := ParseScope("services=billing")
+
+	// env is unconstrained in b, so the span is unconstrained on env
+	got := SpanUnion(a, b)
+	if got.String() != "services=billing,payments" {
+		t.Errorf("Unexpected span union: %s", got)
+	}
+
+	if !SpanUnion(Scope{Empty: true}).Empty {
+		t.Errorf("Span of only empty scopes must be empty")
+	}
+	if SpanUnion(Scope{Empty: true}, a).String() != a.String() {
+		t.Errorf("Empty alternatives must not contribute to the span")
+	}
+}
+
+func TestScope_Covers(t *testing.T) {
+	wide, _ := ParseScope("services=payments,billing")
+	narrow, _ := ParseScope("services=payments; env=prod")
+
+	if !wide.Covers(narrow) {
+		t.Errorf("Expected %s to cover %s", wide, narrow)
+	}
+	if narrow.Covers(wide) {
+		t.Errorf("Expected %s not to cover %s", narrow, wide)
+	}
+	if !(Scope{}).Covers(narrow) {
+		t.Errorf("Unbounded scope covers everything")
+	}
+	if narrow.Covers(Scope{}) {
+		t.Errorf("Bounded scope cannot cover an unbounded one")
+	}
+}
+
+func TestScope_EncodeRoundTrip(t *testing.T) {
+	sc, _ := ParseScope("services=payments,billing; env=prod")
+	decoded, err := DecodeScope(sc.Encode())
+	if err != nil {
+		t.Fatalf("DecodeScope failed: %v", err)
+	}
+	if decoded.String() != sc.String() {
+		t.Errorf("Round trip mismatch: %s != %s", decoded, sc)
+	}
+}
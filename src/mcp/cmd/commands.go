@@ -4,14 +4,26 @@ import (
 	"bufio"
 	"embed"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/m0n0x41d/quint-code/internal/fpf"
 )
 
 //go:embed commands/*.md
 var embeddedCommands embed.FS
 
+// loadPrompts parses the embedded commands for the MCP prompts endpoint
+func loadPrompts() ([]fpf.Prompt, error) {
+	commands, err := fs.Sub(embeddedCommands, "commands")
+	if err != nil {
+		return nil, err
+	}
+	return fpf.LoadPrompts(commands)
+}
+
 func installCommands(projectRoot string, platform string, local bool) (string, int, error) {
 	entries, err := embeddedCommands.ReadDir("commands")
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/m0n0x41d/quint-code/db"
+	"github.com/m0n0x41d/quint-code/internal/fpf"
 
 	"github.com/spf13/cobra"
 )
@@ -19,6 +20,9 @@ var (
 	initCodex  bool
 	initAll    bool
 	initLocal  bool
+
+	initSignAudit  bool
+	initNoCommands bool
 )
 
 var initCmd = &cobra.Command{
@@ -29,13 +33,16 @@ var initCmd = &cobra.Command{
 This command creates:
   - .quint/ directory structure (knowledge base, evidence, decisions)
   - MCP configuration for selected AI tools
-  - Slash commands (global by default, or local with --local)
+  - Slash commands (global by default, or local with --local), unless
+    --no-commands leaves them to the MCP server's prompts
 
 Examples:
-  quint-code init              # Claude, global commands (~/.claude/commands/)
-  quint-code init --local      # Claude, local commands (.claude/commands/)
-  quint-code init --all        # All tools, global commands
-  quint-code init --cursor     # Cursor only`,
+  quint-code init               # Claude, global commands (~/.claude/commands/)
+  quint-code init --local       # Claude, local commands (.claude/commands/)
+  quint-code init --all         # All tools, global commands
+  quint-code init --cursor      # Cursor only
+  quint-code init --sign-audit  # Also sign every audit log entry
+  quint-code init --no-commands # Rely on the MCP prompts instead of slash command files`,
 	RunE: runInit,
 }
 
@@ -46,6 +53,8 @@ func init() {
 	initCmd.Flags().BoolVar(&initCodex, "codex", false, "Configure for Codex CLI")
 	initCmd.Flags().BoolVar(&initAll, "all", false, "Configure for all supported tools")
 	initCmd.Flags().BoolVar(&initLocal, "local", false, "Install commands in project directory instead of global")
+	initCmd.Flags().BoolVar(&initSignAudit, "sign-audit", false, "Create a local key that signs audit log entries")
+	initCmd.Flags().BoolVar(&initNoCommands, "no-commands", false, "Do not write slash commands; the MCP server serves them as prompts")
 
 	rootCmd.AddCommand(initCmd)
 }
@@ -82,6 +91,12 @@ func runInit(cmd *cobra.Command, args []string) error {
 		fmt.Println("  ✓ Database OK")
 	}
 
+	if initSignAudit {
+		if err := setupAuditSigning(quintDir); err != nil {
+			fmt.Printf("  ⚠ Failed to set up audit log signing: %v\n", err)
+		}
+	}
+
 	binaryPath, err := getBinaryPath()
 	if err != nil {
 		fmt.Printf("  ⚠ Could not determine binary path: %v\n", err)
@@ -102,10 +117,12 @@ func runInit(cmd *cobra.Command, args []string) error {
 		} else {
 			fmt.Println("  ✓ Configured MCP for Claude Code (.mcp.json)")
 		}
-		if destPath, count, err := installCommands(cwd, "claude", initLocal); err != nil {
-			fmt.Printf("  ⚠ Failed to install Claude commands: %v\n", err)
-		} else {
-			fmt.Printf("  ✓ Installed %d slash commands (%s)\n", count, destPath)
+		if !initNoCommands {
+			if destPath, count, err := installCommands(cwd, "claude", initLocal); err != nil {
+				fmt.Printf("  ⚠ Failed to install Claude commands: %v\n", err)
+			} else {
+				fmt.Printf("  ✓ Installed %d slash commands (%s)\n", count, destPath)
+			}
 		}
 	}
 
@@ -116,10 +133,12 @@ func runInit(cmd *cobra.Command, args []string) error {
 			fmt.Println("  ✓ Configured MCP for Cursor (.cursor/mcp.json)")
 			fmt.Println("    Note: Make sure quint-code MCP is enabled in Cursor settings")
 		}
-		if destPath, count, err := installCommands(cwd, "cursor", initLocal); err != nil {
-			fmt.Printf("  ⚠ Failed to install Cursor commands: %v\n", err)
-		} else {
-			fmt.Printf("  ✓ Installed %d slash commands (%s)\n", count, destPath)
+		if !initNoCommands {
+			if destPath, count, err := installCommands(cwd, "cursor", initLocal); err != nil {
+				fmt.Printf("  ⚠ Failed to install Cursor commands: %v\n", err)
+			} else {
+				fmt.Printf("  ✓ Installed %d slash commands (%s)\n", count, destPath)
+			}
 		}
 	}
 
@@ -129,10 +148,12 @@ func runInit(cmd *cobra.Command, args []string) error {
 		} else {
 			fmt.Printf("  ✓ Configured MCP for Gemini CLI (project: %s)\n", cwd)
 		}
-		if destPath, count, err := installCommands(cwd, "gemini", initLocal); err != nil {
-			fmt.Printf("  ⚠ Failed to install Gemini commands: %v\n", err)
-		} else {
-			fmt.Printf("  ✓ Installed %d slash commands (%s)\n", count, destPath)
+		if !initNoCommands {
+			if destPath, count, err := installCommands(cwd, "gemini", initLocal); err != nil {
+				fmt.Printf("  ⚠ Failed to install Gemini commands: %v\n", err)
+			} else {
+				fmt.Printf("  ✓ Installed %d slash commands (%s)\n", count, destPath)
+			}
 		}
 	}
 
@@ -142,15 +163,21 @@ func runInit(cmd *cobra.Command, args []string) error {
 		} else {
 			fmt.Printf("  ✓ Configured MCP for Codex CLI (project: %s)\n", cwd)
 		}
-		// Codex only supports global prompts
-		if destPath, count, err := installCommands(cwd, "codex", false); err != nil {
-			fmt.Printf("  ⚠ Failed to install Codex prompts: %v\n", err)
-		} else {
-			fmt.Printf("  ✓ Installed %d prompts (%s)\n", count, destPath)
-			fmt.Println("    Note: Use /prompts:q0-init to invoke")
+		if !initNoCommands {
+			// Codex only supports global prompts
+			if destPath, count, err := installCommands(cwd, "codex", false); err != nil {
+				fmt.Printf("  ⚠ Failed to install Codex prompts: %v\n", err)
+			} else {
+				fmt.Printf("  ✓ Installed %d prompts (%s)\n", count, destPath)
+				fmt.Println("    Note: Use /prompts:q0-init to invoke")
+			}
 		}
 	}
 
+	if initNoCommands {
+		fmt.Println("\nInitialization complete! Run the q0-init prompt of the quint-code MCP server to start.")
+		return nil
+	}
 	fmt.Println("\nInitialization complete! Run /q0-init to start.")
 	return nil
 }
@@ -180,6 +207,29 @@ func createDirectoryStructure(quintDir string) error {
 	return nil
 }
 
+// setupAuditSigning creates the audit signing key pair unless the project
+// already has one
+func setupAuditSigning(quintDir string) error {
+	if pub, err := fpf.LoadAuditPublicKey(quintDir); err != nil || pub != nil {
+		if err != nil {
+			return err
+		}
+		if _, err := fpf.LoadAuditSigningKey(quintDir); err != nil {
+			return err
+		}
+		fmt.Println("  ✓ Audit log signing OK")
+		return nil
+	}
+
+	keyPath, err := fpf.GenerateAuditKey(quintDir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  ✓ Created audit log signing key (%s)\n", keyPath)
+	fmt.Printf("    Commit .quint/%s; keep the private key out of the repository\n", fpf.AuditPublicKeyFile)
+	return nil
+}
+
 func initializeDatabase(quintDir string) error {
 	dbPath := filepath.Join(quintDir, "quint.db")
 	database, err := db.NewStore(dbPath)
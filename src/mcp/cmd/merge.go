@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"github.com/m0n0x41d/quint-code/internal/fpf"
+
+	"github.com/spf13/cobra"
+)
+
+var mergeDryRun bool
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the database to the .quint markdown in canonical form",
+	Long: `Rewrite .quint/knowledge, .quint/evidence and .quint/decisions from
+.quint/quint.db. Frontmatter fields are sorted, so an unchanged holon always
+exports to the same bytes and git only sees real changes.
+
+Export before committing .quint/ and import after merging it. The binary
+database itself is not meant to be merged.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reconcile(fpf.ReconcileFromDB, mergeDryRun)
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Rebuild the database from the .quint markdown after a merge",
+	Long: `Check the merged .quint markdown for conflicts and rebuild .quint/quint.db
+from it. Conflicts are reported with how to resolve each, and nothing is
+imported until they are gone:
+
+  - unresolved git conflict markers in a file
+  - a hypothesis moved to different layers on each branch
+  - decisions whose titles share a slug
+  - an ID claimed by both a hypothesis and a decision`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reconcile(fpf.ReconcileFromMarkdown, mergeDryRun)
+	},
+}
+
+func init() {
+	exportCmd.Flags().BoolVar(&mergeDryRun, "dry-run", false, "Report changes without applying them")
+	importCmd.Flags().BoolVar(&mergeDryRun, "dry-run", false, "Report conflicts and changes without applying them")
+
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+}
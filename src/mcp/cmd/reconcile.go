@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	reconcileFrom   string
+	reconcileDryRun bool
+)
+
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Reconcile the .quint markdown with the database",
+	Long: `Compare .quint/knowledge, .quint/evidence and .quint/decisions with the
+holons and evidence in .quint/quint.db and rebuild one side from the other.
+
+Without --from, only the differences are listed. All changes of one run are
+applied together or not at all.
+
+Examples:
+  quint-code reconcile                          # List differences
+  quint-code reconcile --from markdown --dry-run # Show what a rebuild would do
+  quint-code reconcile --from markdown          # Rebuild the DB, e.g. after a git merge
+  quint-code reconcile --from db                # Rebuild the markdown from the DB`,
+	RunE: runReconcile,
+}
+
+func init() {
+	reconcileCmd.Flags().StringVar(&reconcileFrom, "from", "", "Source of truth: markdown or db")
+	reconcileCmd.Flags().BoolVar(&reconcileDryRun, "dry-run", false, "Report changes without applying them")
+
+	rootCmd.AddCommand(reconcileCmd)
+}
+
+func runReconcile(cmd *cobra.Command, args []string) error {
+	return reconcile(reconcileFrom, reconcileDryRun)
+}
+
+// reconcile runs a reconciliation of the project at the project root
+func reconcile(from string, dryRun bool) error {
+	cwd, err := projectRoot()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(cwd, ".quint")); err != nil {
+		return fmt.Errorf("no .quint directory in %s: run quint-code init first", cwd)
+	}
+
+	tools, err := openTools(cwd)
+	if err != nil {
+		return err
+	}
+	if tools.DB == nil {
+		return fmt.Errorf("failed to open .quint/quint.db")
+	}
+	defer tools.DB.Close() //nolint:errcheck
+
+	report, err := tools.Reconcile(from, dryRun)
+	if err != nil {
+		return err
+	}
+	fmt.Print(report)
+	return nil
+}
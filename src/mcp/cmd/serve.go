@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 
 	"github.com/m0n0x41d/quint-code/db"
 	"github.com/m0n0x41d/quint-code/internal/fpf"
@@ -12,34 +15,104 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	serveHTTP      string
+	serveTokenFile string
+)
+
 var serveCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "Start the MCP server",
 	Long: `Start the Model Context Protocol (MCP) server for AI tool integration.
 
 The server communicates via stdio and provides FPF tools to AI assistants
-like Claude Code, Cursor, Gemini CLI, and Codex CLI.
+like Claude Code, Cursor, Gemini CLI, and Codex CLI. The slash commands are
+also served as MCP prompts, so any MCP client gets the FPF workflow.
+
+With --http the server instead listens for MCP streamable HTTP (JSON or SSE
+responses) at /mcp, so several editors and CI jobs can share one long-lived
+server per repository. Every request must carry the bearer token kept in
+--token-file; a random one is created there on first use.
 
 The project root is determined by:
   1. QUINT_PROJECT_ROOT environment variable (if set)
   2. Current working directory (default)`,
+	Example: `  quint-code serve
+  quint-code serve --http 127.0.0.1:7777
+  quint-code serve --http :7777 --token-file /run/secrets/quint-token`,
 	RunE: runServe,
 }
 
 func init() {
 	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveHTTP, "http", "", "Serve MCP over streamable HTTP on this address instead of stdio")
+	serveCmd.Flags().StringVar(&serveTokenFile, "token-file", "", "File holding the HTTP bearer token (default: quint-code/http.token in the user config directory)")
 }
 
 func runServe(cmd *cobra.Command, args []string) error {
-	cwd := os.Getenv("QUINT_PROJECT_ROOT")
-	if cwd == "" {
+	cwd, err := projectRoot()
+	if err != nil {
+		return err
+	}
+
+	tools, err := openTools(cwd)
+	if err != nil {
+		return err
+	}
+	server := fpf.NewServer(tools)
+	if prompts, err := loadPrompts(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: slash commands are not served as prompts: %v\n", err)
+	} else {
+		server.SetPrompts(prompts)
+	}
+	if serveHTTP == "" {
+		server.Start()
+		return nil
+	}
+
+	token, err := loadServeToken()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(os.Stderr, "Serving MCP for %s at http://%s%s\n", cwd, serveHTTP, fpf.MCPEndpoint)
+	return server.ListenHTTP(ctx, serveHTTP, token)
+}
+
+// loadServeToken reads the bearer token for --http, creating one on first use
+func loadServeToken() (string, error) {
+	path := serveTokenFile
+	if path == "" {
 		var err error
-		cwd, err = os.Getwd()
-		if err != nil {
-			return fmt.Errorf("failed to get working directory: %w", err)
+		if path, err = fpf.DefaultHTTPTokenFile(); err != nil {
+			return "", err
 		}
 	}
+	token, created, err := fpf.LoadHTTPToken(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to load HTTP token: %w", err)
+	}
+	if created {
+		fmt.Fprintf(os.Stderr, "Created a bearer token in %s\n", path)
+	}
+	return token, nil
+}
 
+// projectRoot is QUINT_PROJECT_ROOT if set, otherwise the working directory
+func projectRoot() (string, error) {
+	if root := os.Getenv("QUINT_PROJECT_ROOT"); root != "" {
+		return root, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	return cwd, nil
+}
+
+// openTools opens the project database and loads the active context
+func openTools(cwd string) (*fpf.Tools, error) {
 	quintDir := filepath.Join(cwd, ".quint")
 	dbPath := filepath.Join(quintDir, "quint.db")
 
@@ -52,18 +125,22 @@ func runServe(cmd *cobra.Command, args []string) error {
 	}
 
 	var rawDB *sql.DB
+	contextID := fpf.DefaultContext
 	if database != nil {
 		rawDB = database.GetRawDB()
+		if active, err := database.GetActiveContext(context.Background()); err == nil {
+			contextID = active.ID
+		}
 	}
 
-	fsm, err := fpf.LoadState("default", rawDB)
+	fsm, err := fpf.LoadState(contextID, rawDB)
 	if err != nil {
-		return fmt.Errorf("failed to load state: %w", err)
+		return nil, fmt.Errorf("failed to load state: %w", err)
 	}
 
 	tools := fpf.NewTools(fsm, cwd, database)
-	server := fpf.NewServer(tools)
-	server.Start()
-
-	return nil
+	if err := tools.ApplyConfig(contextID); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to apply %s: %v\n", fpf.ConfigFileName, err)
+	}
+	return tools, nil
 }
@@ -0,0 +1,180 @@
+package db
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AuditChainBreak is the first entry of a chain that fails verification
+type AuditChainBreak struct {
+	Seq    int64
+	ID     string
+	Reason string
+}
+
+// AuditChainReport is the result of walking one context's audit chain
+type AuditChainReport struct {
+	ContextID string
+	Entries   int    // Chained entries checked before the walk stopped
+	Signed    int    // Entries carrying a signature
+	Unchained int64  // Entries written before the chain existed
+	Head      string // entry_hash of the last entry checked
+	Broken    *AuditChainBreak
+}
+
+// SetAuditKey makes every later audit entry carry an ed25519 signature of
+// its entry_hash. A nil key writes unsigned entries.
+func (s *Store) SetAuditKey(key ed25519.PrivateKey) {
+	s.auditKey = key
+}
+
+// InsertAuditLog appends an entry to the hash chain of its context: the
+// entry records the entry_hash of the one before it and is signed when the
+// store has an audit key
+func (s *Store) InsertAuditLog(ctx context.Context, id, toolName, operation, actor, targetID, inputHash, result, details, contextID string) error {
+	entry := AuditLog{
+		ID:        id,
+		Timestamp: sql.NullTime{Time: time.Now().UTC().Truncate(time.Second), Valid: true},
+		ToolName:  toolName,
+		Operation: operation,
+		Actor:     actor,
+		TargetID:  toNullString(targetID),
+		InputHash: toNullString(inputHash),
+		Result:    result,
+		Details:   toNullString(details),
+		ContextID: contextID,
+	}
+
+	// A concurrent writer can take the next seq first; the unique index
+	// rejects the fork and the entry is chained onto the new head
+	var err error
+	for attempt := 0; attempt < 3; attempt++ {
+		if err = s.appendAuditLog(ctx, entry); err == nil || !strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return err
+		}
+	}
+	return err
+}
+
+func (s *Store) appendAuditLog(ctx context.Context, entry AuditLog) error {
+	head, err := s.q.GetAuditChainHead(ctx, s.db, entry.ContextID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to read audit chain head: %w", err)
+	}
+	entry.Seq = sql.NullInt64{Int64: head.Seq.Int64 + 1, Valid: true}
+	entry.PrevHash = sql.NullString{String: head.EntryHash.String, Valid: true}
+	entry.EntryHash = sql.NullString{String: AuditEntryHash(entry), Valid: true}
+	if s.auditKey != nil {
+		sig := ed25519.Sign(s.auditKey, []byte(entry.EntryHash.String))
+		entry.Signature = sql.NullString{String: base64.StdEncoding.EncodeToString(sig), Valid: true}
+	}
+
+	return s.q.InsertAuditLog(ctx, s.db, InsertAuditLogParams{
+		ID:        entry.ID,
+		Timestamp: entry.Timestamp,
+		ToolName:  entry.ToolName,
+		Operation: entry.Operation,
+		Actor:     entry.Actor,
+		TargetID:  entry.TargetID,
+		InputHash: entry.InputHash,
+		Result:    entry.Result,
+		Details:   entry.Details,
+		ContextID: entry.ContextID,
+		Seq:       entry.Seq,
+		PrevHash:  entry.PrevHash,
+		EntryHash: entry.EntryHash,
+		Signature: entry.Signature,
+	})
+}
+
+// AuditEntryHash is the SHA-256 of an entry's position, content and
+// prev_hash. Changing any of them, or the entry before it, changes the hash.
+func AuditEntryHash(e AuditLog) string {
+	var timestamp int64
+	if e.Timestamp.Valid {
+		timestamp = e.Timestamp.Time.Unix()
+	}
+	data, _ := json.Marshal([]any{
+		e.Seq.Int64, e.ContextID, e.PrevHash.String, e.ID, timestamp,
+		e.ToolName, e.Operation, e.Actor, e.TargetID.String, e.InputHash.String, e.Result, e.Details.String,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyAuditChain walks the chain of every context from its first entry
+// and stops at the first broken link. Signatures are checked against pub
+// when it is set; requireSigned also breaks the chain at an unsigned entry.
+func (s *Store) VerifyAuditChain(ctx context.Context, pub ed25519.PublicKey, requireSigned bool) ([]AuditChainReport, error) {
+	contexts, err := s.q.ListAuditLogContexts(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]AuditChainReport, 0, len(contexts))
+	for _, contextID := range contexts {
+		report := AuditChainReport{ContextID: contextID}
+		if report.Unchained, err = s.q.CountUnchainedAuditLog(ctx, s.db, contextID); err != nil {
+			return nil, err
+		}
+		entries, err := s.q.ListAuditChain(ctx, s.db, contextID)
+		if err != nil {
+			return nil, err
+		}
+
+		prevHash := ""
+		for i, e := range entries {
+			if reason := verifyAuditEntry(e, int64(i+1), prevHash, pub, requireSigned); reason != "" {
+				report.Broken = &AuditChainBreak{Seq: e.Seq.Int64, ID: e.ID, Reason: reason}
+				break
+			}
+			report.Entries++
+			if e.Signature.String != "" {
+				report.Signed++
+			}
+			prevHash = e.EntryHash.String
+			report.Head = prevHash
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// verifyAuditEntry explains why e cannot follow prevHash as entry seq, or
+// returns "" when it can
+func verifyAuditEntry(e AuditLog, seq int64, prevHash string, pub ed25519.PublicKey, requireSigned bool) string {
+	switch {
+	case e.Seq.Int64 > seq:
+		return fmt.Sprintf("entries #%d to #%d are missing", seq, e.Seq.Int64-1)
+	case e.Seq.Int64 != seq:
+		return fmt.Sprintf("expected entry #%d, found #%d", seq, e.Seq.Int64)
+	case e.PrevHash.String != prevHash:
+		return "prev_hash does not match the entry before it"
+	case AuditEntryHash(e) != e.EntryHash.String:
+		return "entry was modified after it was written"
+	}
+
+	if e.Signature.String == "" {
+		if requireSigned {
+			return "entry is not signed"
+		}
+		return ""
+	}
+	if pub == nil {
+		return ""
+	}
+	sig, err := base64.StdEncoding.DecodeString(e.Signature.String)
+	if err != nil || !ed25519.Verify(pub, []byte(e.EntryHash.String), sig) {
+		return "signature does not match the audit public key"
+	}
+	return ""
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newAuditStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	ctx := context.Background()
+	for _, id := range []string{"log-1", "log-2", "log-3"} {
+		if err := store.InsertAuditLog(ctx, id, "quint_propose", "create_hypothesis", "agent", "hypo-1", "", "SUCCESS", "details of "+id, "default"); err != nil {
+			t.Fatalf("InsertAuditLog failed: %v", err)
+		}
+	}
+	if err := store.InsertAuditLog(ctx, "other-1", "quint_propose", "create_hypothesis", "agent", "hypo-2", "", "SUCCESS", "", "billing"); err != nil {
+		t.Fatalf("InsertAuditLog failed: %v", err)
+	}
+	return store
+}
+
+func verifyContext(t *testing.T, store *Store, contextID string, pub ed25519.PublicKey, requireSigned bool) AuditChainReport {
+	t.Helper()
+	reports, err := store.VerifyAuditChain(context.Background(), pub, requireSigned)
+	if err != nil {
+		t.Fatalf("VerifyAuditChain failed: %v", err)
+	}
+	for _, r := range reports {
+		if r.ContextID == contextID {
+			return r
+		}
+	}
+	t.Fatalf("No report for context %s in %+v", contextID, reports)
+	return AuditChainReport{}
+}
+
+func TestAuditChain_Links(t *testing.T) {
+	store := newAuditStore(t)
+	ctx := context.Background()
+
+	chain, err := store.q.ListAuditChain(ctx, store.db, "default")
+	if err != nil {
+		t.Fatalf("ListAuditChain failed: %v", err)
+	}
+	if len(chain) != 3 {
+		t.Fatalf("Expected 3 chained entries, got %d", len(chain))
+	}
+	for i, e := range chain {
+		if e.Seq.Int64 != int64(i+1) {
+			t.Errorf("Expected seq %d, got %d", i+1, e.Seq.Int64)
+		}
+		if i > 0 && e.PrevHash.String != chain[i-1].EntryHash.String {
+			t.Errorf("Entry %d does not link to the entry before it", i+1)
+		}
+	}
+	if chain[0].PrevHash.String != "" {
+		t.Errorf("Expected the first entry to have an empty prev_hash, got %q", chain[0].PrevHash.String)
+	}
+
+	r := verifyContext(t, store, "default", nil, false)
+	if r.Broken != nil || r.Entries != 3 || r.Head != chain[2].EntryHash.String {
+		t.Errorf("Expected an intact chain of 3 ending at the last entry, got %+v", r)
+	}
+	if other := verifyContext(t, store, "billing", nil, false); other.Broken != nil || other.Entries != 1 {
+		t.Errorf("Expected contexts to have separate chains, got %+v", other)
+	}
+}
+
+func TestAuditChain_DetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		seq    int64
+		reason string
+	}{
+		{"edited", `UPDATE audit_log SET result = 'ERROR' WHERE id = 'log-2'`, 2, "modified"},
+		{"deleted", `DELETE FROM audit_log WHERE id = 'log-2'`, 3, "missing"},
+		{"deleted first", `DELETE FROM audit_log WHERE id = 'log-1'`, 2, "missing"},
+		{"relinked", `UPDATE audit_log SET prev_hash = 'forged' WHERE id = 'log-3'`, 3, "prev_hash"},
+		{"renumbered", `UPDATE audit_log SET seq = 5 WHERE id = 'log-3'`, 5, "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newAuditStore(t)
+			if _, err := store.GetRawDB().Exec(tt.sql); err != nil {
+				t.Fatalf("Failed to tamper: %v", err)
+			}
+			r := verifyContext(t, store, "default", nil, false)
+			if r.Broken == nil {
+				t.Fatalf("Expected a broken chain, got %+v", r)
+			}
+			if r.Broken.Seq != tt.seq || !strings.Contains(r.Broken.Reason, tt.reason) {
+				t.Errorf("Expected a break at #%d mentioning %q, got %+v", tt.seq, tt.reason, r.Broken)
+			}
+		})
+	}
+}
+
+func TestAuditChain_Signatures(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	store := newAuditStore(t)
+	ctx := context.Background()
+	store.SetAuditKey(priv)
+
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if err := tx.InsertAuditLog(ctx, "log-4", "quint_verify", "verify_hypothesis", "agent", "hypo-1", "", "SUCCESS", "", "default"); err != nil {
+		t.Fatalf("InsertAuditLog failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if r := verifyContext(t, store, "default", pub, false); r.Broken != nil || r.Entries != 4 || r.Signed != 1 {
+		t.Errorf("Expected 4 entries with 1 signed, got %+v", r)
+	}
+	if r := verifyContext(t, store, "default", otherPub, false); r.Broken == nil || r.Broken.Seq != 4 || !strings.Contains(r.Broken.Reason, "signature") {
+		t.Errorf("Expected the signature to fail against another key, got %+v", r.Broken)
+	}
+	if r := verifyContext(t, store, "default", pub, true); r.Broken == nil || r.Broken.Seq != 1 || !strings.Contains(r.Broken.Reason, "not signed") {
+		t.Errorf("Expected unsigned entries to break a chain that requires signatures, got %+v", r.Broken)
+	}
+}
+
+func TestAuditChain_UnchainedEntries(t *testing.T) {
+	store := newAuditStore(t)
+	_, err := store.GetRawDB().Exec(`INSERT INTO audit_log (id, tool_name, operation, actor, result, context_id)
+		VALUES ('legacy-1', 'quint_propose', 'create_hypothesis', 'agent', 'SUCCESS', 'default')`)
+	if err != nil {
+		t.Fatalf("Failed to insert legacy entry: %v", err)
+	}
+
+	r := verifyContext(t, store, "default", nil, false)
+	if r.Unchained != 1 || r.Broken != nil || r.Entries != 3 {
+		t.Errorf("Expected 1 unchained entry beside an intact chain, got %+v", r)
+	}
+}
@@ -37,6 +37,96 @@ var migrations = []struct {
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)`,
 	},
+	{
+		version:     4,
+		description: "Add formality to holons for F-G-R assurance (F0-F9)",
+		sql:         `ALTER TABLE holons ADD COLUMN formality INTEGER DEFAULT 0 CHECK(formality BETWEEN 0 AND 9)`,
+	},
+	{
+		version:     5,
+		description: "Add scope_slice to holons for structured ClaimScope (G)",
+		sql:         `ALTER TABLE holons ADD COLUMN scope_slice TEXT`,
+	},
+	{
+		version:     6,
+		description: "Add cl_penalty_profile to fpf_state for configurable Φ(CL)",
+		sql:         `ALTER TABLE fpf_state ADD COLUMN cl_penalty_profile TEXT`,
+	},
+	{
+		version:     7,
+		description: "Add evidence_decay to fpf_state for per-type evidence decay models",
+		sql:         `ALTER TABLE fpf_state ADD COLUMN evidence_decay TEXT`,
+	},
+	{
+		version:     8,
+		description: "Add evidence_aggregation to fpf_state for weighted self scores",
+		sql:         `ALTER TABLE fpf_state ADD COLUMN evidence_aggregation TEXT`,
+	},
+	{
+		version:     9,
+		description: "Add r_dirty to holons for incremental cached_r_score invalidation",
+		sql:         `ALTER TABLE holons ADD COLUMN r_dirty INTEGER DEFAULT 1`,
+	},
+	{
+		version:     10,
+		description: "Add r_computed_at to holons to record when cached_r_score was computed",
+		sql:         `ALTER TABLE holons ADD COLUMN r_computed_at DATETIME`,
+	},
+	{
+		version:     11,
+		description: "Create contexts table for bounded contexts",
+		sql: `CREATE TABLE IF NOT EXISTS contexts (
+			id TEXT PRIMARY KEY,
+			description TEXT,
+			active INTEGER DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+	},
+	{
+		version:     12,
+		description: "Register the default context and every context already referenced by holons or fpf_state",
+		sql: `INSERT OR IGNORE INTO contexts (id, active)
+			SELECT id, id = 'default' FROM (
+				SELECT 'default' AS id
+				UNION SELECT context_id FROM holons
+				UNION SELECT context_id FROM fpf_state
+			)`,
+	},
+	{
+		version:     13,
+		description: "Add phase to fpf_state so the FSM phase is persisted instead of derived",
+		sql:         `ALTER TABLE fpf_state ADD COLUMN phase TEXT`,
+	},
+	{
+		version:     14,
+		description: "Add separation_of_duties to fpf_state for the role separation policy",
+		sql:         `ALTER TABLE fpf_state ADD COLUMN separation_of_duties TEXT`,
+	},
+	{
+		version:     15,
+		description: "Add seq to audit_log for the per-context hash chain",
+		sql:         `ALTER TABLE audit_log ADD COLUMN seq INTEGER`,
+	},
+	{
+		version:     16,
+		description: "Add prev_hash to audit_log linking each entry to the previous one",
+		sql:         `ALTER TABLE audit_log ADD COLUMN prev_hash TEXT`,
+	},
+	{
+		version:     17,
+		description: "Add entry_hash to audit_log",
+		sql:         `ALTER TABLE audit_log ADD COLUMN entry_hash TEXT`,
+	},
+	{
+		version:     18,
+		description: "Add signature to audit_log for optionally signed entries",
+		sql:         `ALTER TABLE audit_log ADD COLUMN signature TEXT`,
+	},
+	{
+		version:     19,
+		description: "Keep one audit_log entry per position in each context's chain",
+		sql:         `CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_chain ON audit_log(context_id, seq)`,
+	},
 }
 
 // RunMigrations applies all pending migrations to the database.
@@ -20,6 +20,10 @@ type AuditLog struct {
 	Result    string
 	Details   sql.NullString
 	ContextID string
+	Seq       sql.NullInt64
+	PrevHash  sql.NullString
+	EntryHash sql.NullString
+	Signature sql.NullString
 }
 
 type Characteristic struct {
@@ -32,6 +36,13 @@ type Characteristic struct {
 	CreatedAt sql.NullTime
 }
 
+type Context struct {
+	ID          string
+	Description sql.NullString
+	Active      sql.NullInt64
+	CreatedAt   sql.NullTime
+}
+
 type Evidence struct {
 	ID             string
 	HolonID        string
@@ -57,6 +68,10 @@ type Holon struct {
 	CachedRScore sql.NullFloat64
 	CreatedAt    sql.NullTime
 	UpdatedAt    sql.NullTime
+	Formality    sql.NullInt64
+	ScopeSlice   sql.NullString
+	RDirty       sql.NullInt64
+	RComputedAt  sql.NullTime
 }
 
 type Relation struct {
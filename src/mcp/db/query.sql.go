@@ -131,6 +131,33 @@ func (q *Queries) CountHolonsByLayer(ctx context.Context, db DBTX, contextID str
 	return items, nil
 }
 
+const countUnchainedAuditLog = `-- name: CountUnchainedAuditLog :one
+SELECT COUNT(*) FROM audit_log WHERE context_id = ? AND seq IS NULL
+`
+
+func (q *Queries) CountUnchainedAuditLog(ctx context.Context, db DBTX, contextID string) (int64, error) {
+	row := db.QueryRowContext(ctx, countUnchainedAuditLog, contextID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createContext = `-- name: CreateContext :exec
+INSERT INTO contexts (id, description, active, created_at)
+VALUES (?, ?, 0, ?)
+`
+
+type CreateContextParams struct {
+	ID          string
+	Description sql.NullString
+	CreatedAt   sql.NullTime
+}
+
+func (q *Queries) CreateContext(ctx context.Context, db DBTX, arg CreateContextParams) error {
+	_, err := db.ExecContext(ctx, createContext, arg.ID, arg.Description, arg.CreatedAt)
+	return err
+}
+
 const createHolon = `-- name: CreateHolon :exec
 
 
@@ -224,6 +251,22 @@ func (q *Queries) CreateWaiver(ctx context.Context, db DBTX, arg CreateWaiverPar
 	return err
 }
 
+const getActiveContext = `-- name: GetActiveContext :one
+SELECT id, description, active, created_at FROM contexts WHERE active = 1 LIMIT 1
+`
+
+func (q *Queries) GetActiveContext(ctx context.Context, db DBTX) (Context, error) {
+	row := db.QueryRowContext(ctx, getActiveContext)
+	var i Context
+	err := row.Scan(
+		&i.ID,
+		&i.Description,
+		&i.Active,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getActiveWaiverForEvidence = `-- name: GetActiveWaiverForEvidence :one
 SELECT id, evidence_id, waived_by, waived_until, rationale, created_at FROM waivers
 WHERE evidence_id = ? AND waived_until > datetime('now')
@@ -278,8 +321,24 @@ func (q *Queries) GetAllActiveWaivers(ctx context.Context, db DBTX) ([]Waiver, e
 	return items, nil
 }
 
+const getAuditChainHead = `-- name: GetAuditChainHead :one
+SELECT seq, entry_hash FROM audit_log WHERE context_id = ? AND seq IS NOT NULL ORDER BY seq DESC LIMIT 1
+`
+
+type GetAuditChainHeadRow struct {
+	Seq       sql.NullInt64
+	EntryHash sql.NullString
+}
+
+func (q *Queries) GetAuditChainHead(ctx context.Context, db DBTX, contextID string) (GetAuditChainHeadRow, error) {
+	row := db.QueryRowContext(ctx, getAuditChainHead, contextID)
+	var i GetAuditChainHeadRow
+	err := row.Scan(&i.Seq, &i.EntryHash)
+	return i, err
+}
+
 const getAuditLogByContext = `-- name: GetAuditLogByContext :many
-SELECT id, timestamp, tool_name, operation, actor, target_id, input_hash, result, details, context_id FROM audit_log WHERE context_id = ? ORDER BY timestamp DESC
+SELECT id, timestamp, tool_name, operation, actor, target_id, input_hash, result, details, context_id, seq, prev_hash, entry_hash, signature FROM audit_log WHERE context_id = ? ORDER BY timestamp DESC
 `
 
 func (q *Queries) GetAuditLogByContext(ctx context.Context, db DBTX, contextID string) ([]AuditLog, error) {
@@ -302,6 +361,10 @@ func (q *Queries) GetAuditLogByContext(ctx context.Context, db DBTX, contextID s
 			&i.Result,
 			&i.Details,
 			&i.ContextID,
+			&i.Seq,
+			&i.PrevHash,
+			&i.EntryHash,
+			&i.Signature,
 		); err != nil {
 			return nil, err
 		}
@@ -317,7 +380,7 @@ func (q *Queries) GetAuditLogByContext(ctx context.Context, db DBTX, contextID s
 }
 
 const getAuditLogByTarget = `-- name: GetAuditLogByTarget :many
-SELECT id, timestamp, tool_name, operation, actor, target_id, input_hash, result, details, context_id FROM audit_log WHERE target_id = ? ORDER BY timestamp DESC
+SELECT id, timestamp, tool_name, operation, actor, target_id, input_hash, result, details, context_id, seq, prev_hash, entry_hash, signature FROM audit_log WHERE target_id = ? ORDER BY timestamp DESC
 `
 
 func (q *Queries) GetAuditLogByTarget(ctx context.Context, db DBTX, targetID sql.NullString) ([]AuditLog, error) {
@@ -340,6 +403,10 @@ func (q *Queries) GetAuditLogByTarget(ctx context.Context, db DBTX, targetID sql
 			&i.Result,
 			&i.Details,
 			&i.ContextID,
+			&i.Seq,
+			&i.PrevHash,
+			&i.EntryHash,
+			&i.Signature,
 		); err != nil {
 			return nil, err
 		}
@@ -456,6 +523,50 @@ func (q *Queries) GetComponentsOf(ctx context.Context, db DBTX, targetID string)
 	return items, nil
 }
 
+const getContext = `-- name: GetContext :one
+SELECT id, description, active, created_at FROM contexts WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetContext(ctx context.Context, db DBTX, id string) (Context, error) {
+	row := db.QueryRowContext(ctx, getContext, id)
+	var i Context
+	err := row.Scan(
+		&i.ID,
+		&i.Description,
+		&i.Active,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDecisionContexts = `-- name: GetDecisionContexts :many
+SELECT target_id FROM relations
+WHERE source_id = ? AND relation_type = 'memberOf'
+`
+
+func (q *Queries) GetDecisionContexts(ctx context.Context, db DBTX, sourceID string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, getDecisionContexts, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var target_id string
+		if err := rows.Scan(&target_id); err != nil {
+			return nil, err
+		}
+		items = append(items, target_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getDependencies = `-- name: GetDependencies :many
 SELECT target_id, relation_type, congruence_level
 FROM relations
@@ -622,7 +733,7 @@ func (q *Queries) GetEvidenceWithCarrier(ctx context.Context, db DBTX) ([]Eviden
 }
 
 const getHolon = `-- name: GetHolon :one
-SELECT id, type, kind, layer, title, content, context_id, scope, parent_id, cached_r_score, created_at, updated_at FROM holons WHERE id = ? LIMIT 1
+SELECT id, type, kind, layer, title, content, context_id, scope, parent_id, cached_r_score, created_at, updated_at, formality, scope_slice, r_dirty, r_computed_at FROM holons WHERE id = ? LIMIT 1
 `
 
 func (q *Queries) GetHolon(ctx context.Context, db DBTX, id string) (Holon, error) {
@@ -641,6 +752,10 @@ func (q *Queries) GetHolon(ctx context.Context, db DBTX, id string) (Holon, erro
 		&i.CachedRScore,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Formality,
+		&i.ScopeSlice,
+		&i.RDirty,
+		&i.RComputedAt,
 	)
 	return i, err
 }
@@ -722,7 +837,7 @@ func (q *Queries) GetHolonTitle(ctx context.Context, db DBTX, id string) (string
 }
 
 const getHolonsByParent = `-- name: GetHolonsByParent :many
-SELECT id, type, kind, layer, title, content, context_id, scope, parent_id, cached_r_score, created_at, updated_at FROM holons WHERE parent_id = ? ORDER BY created_at DESC
+SELECT id, type, kind, layer, title, content, context_id, scope, parent_id, cached_r_score, created_at, updated_at, formality, scope_slice, r_dirty, r_computed_at FROM holons WHERE parent_id = ? ORDER BY created_at DESC
 `
 
 func (q *Queries) GetHolonsByParent(ctx context.Context, db DBTX, parentID sql.NullString) ([]Holon, error) {
@@ -747,6 +862,10 @@ func (q *Queries) GetHolonsByParent(ctx context.Context, db DBTX, parentID sql.N
 			&i.CachedRScore,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Formality,
+			&i.ScopeSlice,
+			&i.RDirty,
+			&i.RComputedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -762,7 +881,7 @@ func (q *Queries) GetHolonsByParent(ctx context.Context, db DBTX, parentID sql.N
 }
 
 const getLatestHolonByContext = `-- name: GetLatestHolonByContext :one
-SELECT id, type, kind, layer, title, content, context_id, scope, parent_id, cached_r_score, created_at, updated_at FROM holons WHERE context_id = ? ORDER BY updated_at DESC LIMIT 1
+SELECT id, type, kind, layer, title, content, context_id, scope, parent_id, cached_r_score, created_at, updated_at, formality, scope_slice, r_dirty, r_computed_at FROM holons WHERE context_id = ? ORDER BY updated_at DESC LIMIT 1
 `
 
 func (q *Queries) GetLatestHolonByContext(ctx context.Context, db DBTX, contextID string) (Holon, error) {
@@ -781,12 +900,16 @@ func (q *Queries) GetLatestHolonByContext(ctx context.Context, db DBTX, contextI
 		&i.CachedRScore,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Formality,
+		&i.ScopeSlice,
+		&i.RDirty,
+		&i.RComputedAt,
 	)
 	return i, err
 }
 
 const getRecentAuditLog = `-- name: GetRecentAuditLog :many
-SELECT id, timestamp, tool_name, operation, actor, target_id, input_hash, result, details, context_id FROM audit_log ORDER BY timestamp DESC LIMIT ?
+SELECT id, timestamp, tool_name, operation, actor, target_id, input_hash, result, details, context_id, seq, prev_hash, entry_hash, signature FROM audit_log ORDER BY timestamp DESC LIMIT ?
 `
 
 func (q *Queries) GetRecentAuditLog(ctx context.Context, db DBTX, limit int64) ([]AuditLog, error) {
@@ -809,6 +932,10 @@ func (q *Queries) GetRecentAuditLog(ctx context.Context, db DBTX, limit int64) (
 			&i.Result,
 			&i.Details,
 			&i.ContextID,
+			&i.Seq,
+			&i.PrevHash,
+			&i.EntryHash,
+			&i.Signature,
 		); err != nil {
 			return nil, err
 		}
@@ -897,12 +1024,13 @@ func (q *Queries) GetWaiversByEvidence(ctx context.Context, db DBTX, evidenceID
 
 const insertAuditLog = `-- name: InsertAuditLog :exec
 
-INSERT INTO audit_log (id, tool_name, operation, actor, target_id, input_hash, result, details, context_id)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO audit_log (id, timestamp, tool_name, operation, actor, target_id, input_hash, result, details, context_id, seq, prev_hash, entry_hash, signature)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type InsertAuditLogParams struct {
 	ID        string
+	Timestamp sql.NullTime
 	ToolName  string
 	Operation string
 	Actor     string
@@ -911,12 +1039,17 @@ type InsertAuditLogParams struct {
 	Result    string
 	Details   sql.NullString
 	ContextID string
+	Seq       sql.NullInt64
+	PrevHash  sql.NullString
+	EntryHash sql.NullString
+	Signature sql.NullString
 }
 
 // Audit log queries
 func (q *Queries) InsertAuditLog(ctx context.Context, db DBTX, arg InsertAuditLogParams) error {
 	_, err := db.ExecContext(ctx, insertAuditLog,
 		arg.ID,
+		arg.Timestamp,
 		arg.ToolName,
 		arg.Operation,
 		arg.Actor,
@@ -925,10 +1058,120 @@ func (q *Queries) InsertAuditLog(ctx context.Context, db DBTX, arg InsertAuditLo
 		arg.Result,
 		arg.Details,
 		arg.ContextID,
+		arg.Seq,
+		arg.PrevHash,
+		arg.EntryHash,
+		arg.Signature,
 	)
 	return err
 }
 
+const listAuditChain = `-- name: ListAuditChain :many
+SELECT id, timestamp, tool_name, operation, actor, target_id, input_hash, result, details, context_id, seq, prev_hash, entry_hash, signature FROM audit_log WHERE context_id = ? AND seq IS NOT NULL ORDER BY seq
+`
+
+func (q *Queries) ListAuditChain(ctx context.Context, db DBTX, contextID string) ([]AuditLog, error) {
+	rows, err := db.QueryContext(ctx, listAuditChain, contextID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Timestamp,
+			&i.ToolName,
+			&i.Operation,
+			&i.Actor,
+			&i.TargetID,
+			&i.InputHash,
+			&i.Result,
+			&i.Details,
+			&i.ContextID,
+			&i.Seq,
+			&i.PrevHash,
+			&i.EntryHash,
+			&i.Signature,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditLogContexts = `-- name: ListAuditLogContexts :many
+SELECT DISTINCT context_id FROM audit_log ORDER BY context_id
+`
+
+func (q *Queries) ListAuditLogContexts(ctx context.Context, db DBTX) ([]string, error) {
+	rows, err := db.QueryContext(ctx, listAuditLogContexts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var context_id string
+		if err := rows.Scan(&context_id); err != nil {
+			return nil, err
+		}
+		items = append(items, context_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllEvidence = `-- name: ListAllEvidence :many
+SELECT id, holon_id, type, content, verdict, assurance_level, carrier_ref, valid_until, created_at FROM evidence ORDER BY id
+`
+
+func (q *Queries) ListAllEvidence(ctx context.Context, db DBTX) ([]Evidence, error) {
+	rows, err := db.QueryContext(ctx, listAllEvidence)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Evidence
+	for rows.Next() {
+		var i Evidence
+		if err := rows.Scan(
+			&i.ID,
+			&i.HolonID,
+			&i.Type,
+			&i.Content,
+			&i.Verdict,
+			&i.AssuranceLevel,
+			&i.CarrierRef,
+			&i.ValidUntil,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listAllHolonIDs = `-- name: ListAllHolonIDs :many
 SELECT id FROM holons
 `
@@ -956,8 +1199,132 @@ func (q *Queries) ListAllHolonIDs(ctx context.Context, db DBTX) ([]string, error
 	return items, nil
 }
 
+const listAllHolons = `-- name: ListAllHolons :many
+SELECT id, type, kind, layer, title, content, context_id, scope, parent_id, cached_r_score, created_at, updated_at, formality, scope_slice, r_dirty, r_computed_at FROM holons ORDER BY id
+`
+
+func (q *Queries) ListAllHolons(ctx context.Context, db DBTX) ([]Holon, error) {
+	rows, err := db.QueryContext(ctx, listAllHolons)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Holon
+	for rows.Next() {
+		var i Holon
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Kind,
+			&i.Layer,
+			&i.Title,
+			&i.Content,
+			&i.ContextID,
+			&i.Scope,
+			&i.ParentID,
+			&i.CachedRScore,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Formality,
+			&i.ScopeSlice,
+			&i.RDirty,
+			&i.RComputedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listContexts = `-- name: ListContexts :many
+SELECT id, description, active, created_at FROM contexts ORDER BY id
+`
+
+func (q *Queries) ListContexts(ctx context.Context, db DBTX) ([]Context, error) {
+	rows, err := db.QueryContext(ctx, listContexts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Context
+	for rows.Next() {
+		var i Context
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.Active,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCrossContextRelations = `-- name: ListCrossContextRelations :many
+SELECT r.source_id, s.context_id AS source_context, r.target_id, t.context_id AS target_context, r.relation_type, r.congruence_level
+FROM relations r
+JOIN holons s ON s.id = r.source_id
+JOIN holons t ON t.id = r.target_id
+WHERE s.context_id <> t.context_id AND ? IN (s.context_id, t.context_id)
+ORDER BY r.source_id, r.target_id, r.relation_type
+`
+
+type ListCrossContextRelationsRow struct {
+	SourceID        string
+	SourceContext   string
+	TargetID        string
+	TargetContext   string
+	RelationType    string
+	CongruenceLevel sql.NullInt64
+}
+
+func (q *Queries) ListCrossContextRelations(ctx context.Context, db DBTX, contextID string) ([]ListCrossContextRelationsRow, error) {
+	rows, err := db.QueryContext(ctx, listCrossContextRelations, contextID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCrossContextRelationsRow
+	for rows.Next() {
+		var i ListCrossContextRelationsRow
+		if err := rows.Scan(
+			&i.SourceID,
+			&i.SourceContext,
+			&i.TargetID,
+			&i.TargetContext,
+			&i.RelationType,
+			&i.CongruenceLevel,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listHolonsByLayer = `-- name: ListHolonsByLayer :many
-SELECT id, type, kind, layer, title, content, context_id, scope, parent_id, cached_r_score, created_at, updated_at FROM holons WHERE layer = ? ORDER BY created_at DESC
+SELECT id, type, kind, layer, title, content, context_id, scope, parent_id, cached_r_score, created_at, updated_at, formality, scope_slice, r_dirty, r_computed_at FROM holons WHERE layer = ? ORDER BY created_at DESC
 `
 
 func (q *Queries) ListHolonsByLayer(ctx context.Context, db DBTX, layer string) ([]Holon, error) {
@@ -982,6 +1349,10 @@ func (q *Queries) ListHolonsByLayer(ctx context.Context, db DBTX, layer string)
 			&i.CachedRScore,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Formality,
+			&i.ScopeSlice,
+			&i.RDirty,
+			&i.RComputedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -996,6 +1367,26 @@ func (q *Queries) ListHolonsByLayer(ctx context.Context, db DBTX, layer string)
 	return items, nil
 }
 
+const markHolonRDirty = `-- name: MarkHolonRDirty :exec
+WITH RECURSIVE affected(id) AS (
+    SELECT ?
+    UNION
+    SELECT r.target_id FROM relations r JOIN affected a ON r.source_id = a.id
+    WHERE r.relation_type IN ('componentOf', 'memberOf')
+    UNION
+    SELECT r.source_id FROM relations r JOIN affected a ON r.target_id = a.id
+    WHERE r.relation_type = 'dependsOn'
+)
+UPDATE holons SET r_dirty = 1 WHERE id IN (SELECT id FROM affected)
+`
+
+// Marks a holon and every holon whose R depends on it, transitively:
+// wholes of its componentOf, groups of its memberOf, and dependents of its dependsOn.
+func (q *Queries) MarkHolonRDirty(ctx context.Context, db DBTX, id string) error {
+	_, err := db.ExecContext(ctx, markHolonRDirty, id)
+	return err
+}
+
 const recordWork = `-- name: RecordWork :exec
 
 INSERT INTO work_records (id, method_ref, performer_ref, started_at, ended_at, resource_ledger, created_at)
@@ -1026,6 +1417,99 @@ func (q *Queries) RecordWork(ctx context.Context, db DBTX, arg RecordWorkParams)
 	return err
 }
 
+const setActiveContext = `-- name: SetActiveContext :exec
+UPDATE contexts SET active = CASE WHEN id = ? THEN 1 ELSE 0 END
+`
+
+func (q *Queries) SetActiveContext(ctx context.Context, db DBTX, id string) error {
+	_, err := db.ExecContext(ctx, setActiveContext, id)
+	return err
+}
+
+const updateEvidence = `-- name: UpdateEvidence :exec
+UPDATE evidence SET holon_id = ?, type = ?, content = ?, verdict = ?, assurance_level = ?, carrier_ref = ?, valid_until = ? WHERE id = ?
+`
+
+type UpdateEvidenceParams struct {
+	HolonID        string
+	Type           string
+	Content        string
+	Verdict        string
+	AssuranceLevel sql.NullString
+	CarrierRef     sql.NullString
+	ValidUntil     sql.NullTime
+	ID             string
+}
+
+func (q *Queries) UpdateEvidence(ctx context.Context, db DBTX, arg UpdateEvidenceParams) error {
+	_, err := db.ExecContext(ctx, updateEvidence,
+		arg.HolonID,
+		arg.Type,
+		arg.Content,
+		arg.Verdict,
+		arg.AssuranceLevel,
+		arg.CarrierRef,
+		arg.ValidUntil,
+		arg.ID,
+	)
+	return err
+}
+
+const updateHolonContent = `-- name: UpdateHolonContent :exec
+UPDATE holons SET title = ?, content = ?, kind = ?, scope = ?, updated_at = ? WHERE id = ?
+`
+
+type UpdateHolonContentParams struct {
+	Title     string
+	Content   string
+	Kind      sql.NullString
+	Scope     sql.NullString
+	UpdatedAt sql.NullTime
+	ID        string
+}
+
+func (q *Queries) UpdateHolonContent(ctx context.Context, db DBTX, arg UpdateHolonContentParams) error {
+	_, err := db.ExecContext(ctx, updateHolonContent,
+		arg.Title,
+		arg.Content,
+		arg.Kind,
+		arg.Scope,
+		arg.UpdatedAt,
+		arg.ID,
+	)
+	return err
+}
+
+const updateHolonFormality = `-- name: UpdateHolonFormality :exec
+UPDATE holons SET formality = ?, updated_at = ? WHERE id = ?
+`
+
+type UpdateHolonFormalityParams struct {
+	Formality sql.NullInt64
+	UpdatedAt sql.NullTime
+	ID        string
+}
+
+func (q *Queries) UpdateHolonFormality(ctx context.Context, db DBTX, arg UpdateHolonFormalityParams) error {
+	_, err := db.ExecContext(ctx, updateHolonFormality, arg.Formality, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const updateHolonScopeSlice = `-- name: UpdateHolonScopeSlice :exec
+UPDATE holons SET scope_slice = ?, updated_at = ? WHERE id = ?
+`
+
+type UpdateHolonScopeSliceParams struct {
+	ScopeSlice sql.NullString
+	UpdatedAt  sql.NullTime
+	ID         string
+}
+
+func (q *Queries) UpdateHolonScopeSlice(ctx context.Context, db DBTX, arg UpdateHolonScopeSliceParams) error {
+	_, err := db.ExecContext(ctx, updateHolonScopeSlice, arg.ScopeSlice, arg.UpdatedAt, arg.ID)
+	return err
+}
+
 const updateHolonLayer = `-- name: UpdateHolonLayer :exec
 UPDATE holons SET layer = ?, updated_at = ? WHERE id = ?
 `
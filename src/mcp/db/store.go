@@ -2,7 +2,9 @@ package db
 
 import (
 	"context"
+	"crypto/ed25519"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -22,7 +24,11 @@ CREATE TABLE IF NOT EXISTS holons (
 	parent_id TEXT REFERENCES holons(id),
 	cached_r_score REAL DEFAULT 0.0 CHECK(cached_r_score BETWEEN 0.0 AND 1.0),
 	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	formality INTEGER DEFAULT 0 CHECK(formality BETWEEN 0 AND 9),
+	scope_slice TEXT,
+	r_dirty INTEGER DEFAULT 1,
+	r_computed_at DATETIME
 );
 CREATE TABLE IF NOT EXISTS evidence (
 	id TEXT PRIMARY KEY,
@@ -72,7 +78,11 @@ CREATE TABLE IF NOT EXISTS audit_log (
 	input_hash TEXT,
 	result TEXT NOT NULL,
 	details TEXT,
-	context_id TEXT NOT NULL DEFAULT 'default'
+	context_id TEXT NOT NULL DEFAULT 'default',
+	seq INTEGER,
+	prev_hash TEXT,
+	entry_hash TEXT,
+	signature TEXT
 );
 CREATE TABLE IF NOT EXISTS waivers (
 	id TEXT PRIMARY KEY,
@@ -83,18 +93,36 @@ CREATE TABLE IF NOT EXISTS waivers (
 	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 	FOREIGN KEY(evidence_id) REFERENCES evidence(id)
 );
+CREATE TABLE IF NOT EXISTS contexts (
+	id TEXT PRIMARY KEY,
+	description TEXT,
+	active INTEGER DEFAULT 0,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
 CREATE INDEX IF NOT EXISTS idx_relations_target ON relations(target_id, relation_type);
 CREATE INDEX IF NOT EXISTS idx_relations_source ON relations(source_id, relation_type);
 CREATE INDEX IF NOT EXISTS idx_waivers_evidence ON waivers(evidence_id);
 `
 
 type Store struct {
-	conn *sql.DB
-	q    *Queries
+	conn     *sql.DB
+	db       DBTX // conn, or the transaction of a Store returned by BeginTx
+	q        *Queries
+	auditKey ed25519.PrivateKey // Signs audit entries when set
 }
 
+// Tx is a Store whose reads and writes all go through one transaction
+type Tx struct {
+	*Store
+	tx *sql.Tx
+}
+
+// busyTimeout makes a connection wait for another connection's write
+// transaction instead of failing with SQLITE_BUSY
+const busyTimeout = "?_pragma=busy_timeout(5000)"
+
 func NewStore(dbPath string) (*Store, error) {
-	conn, err := sql.Open("sqlite", dbPath)
+	conn, err := sql.Open("sqlite", dbPath+busyTimeout)
 	if err != nil {
 		return nil, err
 	}
@@ -109,21 +137,49 @@ func NewStore(dbPath string) (*Store, error) {
 
 	return &Store{
 		conn: conn,
+		db:   conn,
 		q:    New(),
 	}, nil
 }
 
+// BeginTx starts a unit of work. Nothing written through the returned Tx is
+// visible to the Store until Commit.
+func (s *Store) BeginTx(ctx context.Context) (*Tx, error) {
+	if _, nested := s.db.(*sql.Tx); nested {
+		return nil, errors.New("transaction already in progress")
+	}
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return &Tx{Store: &Store{conn: s.conn, db: tx, q: s.q, auditKey: s.auditKey}, tx: tx}, nil
+}
+
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
+}
+
 func (s *Store) GetRawDB() *sql.DB {
 	return s.conn
 }
 
+// DBTX is what the Store reads and writes through: the connection, or the
+// transaction of a Store returned by BeginTx
+func (s *Store) DBTX() DBTX {
+	return s.db
+}
+
 func (s *Store) Close() error {
 	return s.conn.Close()
 }
 
 func (s *Store) CreateHolon(ctx context.Context, id, typ, kind, layer, title, content, contextID, scope, parentID string) error {
 	now := sql.NullTime{Time: time.Now(), Valid: true}
-	return s.q.CreateHolon(ctx, s.conn, CreateHolonParams{
+	return s.q.CreateHolon(ctx, s.db, CreateHolonParams{
 		ID:        id,
 		Type:      typ,
 		Kind:      toNullString(kind),
@@ -139,27 +195,76 @@ func (s *Store) CreateHolon(ctx context.Context, id, typ, kind, layer, title, co
 }
 
 func (s *Store) GetHolon(ctx context.Context, id string) (Holon, error) {
-	return s.q.GetHolon(ctx, s.conn, id)
+	return s.q.GetHolon(ctx, s.db, id)
 }
 
 func (s *Store) GetHolonTitle(ctx context.Context, id string) (string, error) {
-	return s.q.GetHolonTitle(ctx, s.conn, id)
+	return s.q.GetHolonTitle(ctx, s.db, id)
 }
 
 func (s *Store) ListAllHolonIDs(ctx context.Context) ([]string, error) {
-	return s.q.ListAllHolonIDs(ctx, s.conn)
+	return s.q.ListAllHolonIDs(ctx, s.db)
+}
+
+func (s *Store) ListAllHolons(ctx context.Context) ([]Holon, error) {
+	return s.q.ListAllHolons(ctx, s.db)
+}
+
+// UpdateHolonContent overwrites the descriptive fields of a holon
+func (s *Store) UpdateHolonContent(ctx context.Context, id, title, content, kind, scope string) error {
+	err := s.q.UpdateHolonContent(ctx, s.db, UpdateHolonContentParams{
+		Title:     title,
+		Content:   content,
+		Kind:      toNullString(kind),
+		Scope:     toNullString(scope),
+		UpdatedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		ID:        id,
+	})
+	if err != nil {
+		return err
+	}
+	return s.InvalidateR(ctx, id)
 }
 
 func (s *Store) UpdateHolonLayer(ctx context.Context, id, layer string) error {
-	return s.q.UpdateHolonLayer(ctx, s.conn, UpdateHolonLayerParams{
+	err := s.q.UpdateHolonLayer(ctx, s.db, UpdateHolonLayerParams{
 		ID:        id,
 		Layer:     layer,
 		UpdatedAt: sql.NullTime{Time: time.Now(), Valid: true},
 	})
+	if err != nil {
+		return err
+	}
+	return s.InvalidateR(ctx, id)
+}
+
+// InvalidateR marks the cached R of a holon and of every holon depending on
+// it as dirty, so the next reader recomputes it
+func (s *Store) InvalidateR(ctx context.Context, holonID string) error {
+	if err := s.q.MarkHolonRDirty(ctx, s.db, holonID); err != nil {
+		return fmt.Errorf("failed to invalidate cached R for %s: %w", holonID, err)
+	}
+	return nil
+}
+
+func (s *Store) UpdateHolonFormality(ctx context.Context, id string, formality int) error {
+	return s.q.UpdateHolonFormality(ctx, s.db, UpdateHolonFormalityParams{
+		ID:        id,
+		Formality: sql.NullInt64{Int64: int64(formality), Valid: true},
+		UpdatedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	})
+}
+
+func (s *Store) UpdateHolonScopeSlice(ctx context.Context, id, scopeSlice string) error {
+	return s.q.UpdateHolonScopeSlice(ctx, s.db, UpdateHolonScopeSliceParams{
+		ID:         id,
+		ScopeSlice: toNullString(scopeSlice),
+		UpdatedAt:  sql.NullTime{Time: time.Now(), Valid: true},
+	})
 }
 
 func (s *Store) RecordWork(ctx context.Context, id, methodRef, performerRef string, startedAt, endedAt time.Time, ledger string) error {
-	return s.q.RecordWork(ctx, s.conn, RecordWorkParams{
+	return s.q.RecordWork(ctx, s.db, RecordWorkParams{
 		ID:             id,
 		MethodRef:      methodRef,
 		PerformerRef:   performerRef,
@@ -171,18 +276,7 @@ func (s *Store) RecordWork(ctx context.Context, id, methodRef, performerRef stri
 }
 
 func (s *Store) AddEvidence(ctx context.Context, id, holonID, typ, content, verdict, assuranceLevel, carrierRef, validUntil string) error {
-	var vUntil sql.NullTime
-	if validUntil != "" {
-		t, err := time.Parse(time.RFC3339, validUntil)
-		if err != nil {
-			t, err = time.Parse("2006-01-02", validUntil)
-		}
-		if err == nil {
-			vUntil = sql.NullTime{Time: t, Valid: true}
-		}
-	}
-
-	return s.q.AddEvidence(ctx, s.conn, AddEvidenceParams{
+	err := s.q.AddEvidence(ctx, s.db, AddEvidenceParams{
 		ID:             id,
 		HolonID:        holonID,
 		Type:           typ,
@@ -190,93 +284,145 @@ func (s *Store) AddEvidence(ctx context.Context, id, holonID, typ, content, verd
 		Verdict:        verdict,
 		AssuranceLevel: toNullString(assuranceLevel),
 		CarrierRef:     toNullString(carrierRef),
-		ValidUntil:     vUntil,
+		ValidUntil:     parseValidUntil(validUntil),
 		CreatedAt:      sql.NullTime{Time: time.Now(), Valid: true},
 	})
+	if err != nil {
+		return err
+	}
+	return s.InvalidateR(ctx, holonID)
+}
+
+// UpdateEvidence overwrites an evidence record, e.g. from its markdown file
+func (s *Store) UpdateEvidence(ctx context.Context, id, holonID, typ, content, verdict, assuranceLevel, carrierRef, validUntil string) error {
+	err := s.q.UpdateEvidence(ctx, s.db, UpdateEvidenceParams{
+		HolonID:        holonID,
+		Type:           typ,
+		Content:        content,
+		Verdict:        verdict,
+		AssuranceLevel: toNullString(assuranceLevel),
+		CarrierRef:     toNullString(carrierRef),
+		ValidUntil:     parseValidUntil(validUntil),
+		ID:             id,
+	})
+	if err != nil {
+		return err
+	}
+	return s.InvalidateR(ctx, holonID)
+}
+
+// parseValidUntil accepts RFC 3339 or a plain date; anything else means no expiry
+func parseValidUntil(validUntil string) sql.NullTime {
+	if validUntil == "" {
+		return sql.NullTime{}
+	}
+	t, err := time.Parse(time.RFC3339, validUntil)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", validUntil)
+	}
+	if err != nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t, Valid: true}
+}
+
+func (s *Store) ListAllEvidence(ctx context.Context) ([]Evidence, error) {
+	return s.q.ListAllEvidence(ctx, s.db)
 }
 
 func (s *Store) GetEvidence(ctx context.Context, holonID string) ([]Evidence, error) {
-	return s.q.GetEvidenceByHolon(ctx, s.conn, holonID)
+	return s.q.GetEvidenceByHolon(ctx, s.db, holonID)
 }
 
 func (s *Store) GetEvidenceWithCarrier(ctx context.Context) ([]Evidence, error) {
-	return s.q.GetEvidenceWithCarrier(ctx, s.conn)
+	return s.q.GetEvidenceWithCarrier(ctx, s.db)
 }
 
 func (s *Store) Link(ctx context.Context, source, target, relType string) error {
-	return s.q.AddRelation(ctx, s.conn, AddRelationParams{
+	err := s.q.AddRelation(ctx, s.db, AddRelationParams{
 		SourceID:     source,
 		TargetID:     target,
 		RelationType: relType,
 		CreatedAt:    sql.NullTime{Time: time.Now(), Valid: true},
 	})
+	if err != nil {
+		return err
+	}
+	return s.InvalidateR(ctx, relationDependent(source, relType, target))
 }
 
 func (s *Store) CreateRelation(ctx context.Context, sourceID, relationType, targetID string, cl int) error {
-	return s.q.CreateRelation(ctx, s.conn, CreateRelationParams{
+	err := s.q.CreateRelation(ctx, s.db, CreateRelationParams{
 		SourceID:        sourceID,
 		RelationType:    relationType,
 		TargetID:        targetID,
 		CongruenceLevel: sql.NullInt64{Int64: int64(cl), Valid: true},
 	})
+	if err != nil {
+		return err
+	}
+	return s.InvalidateR(ctx, relationDependent(sourceID, relationType, targetID))
+}
+
+// relationDependent returns the side of a relation whose R is computed from the other:
+// the dependent of dependsOn, the whole of componentOf, the group of memberOf
+func relationDependent(sourceID, relationType, targetID string) string {
+	if relationType == "dependsOn" {
+		return sourceID
+	}
+	return targetID
 }
 
 func (s *Store) GetComponentsOf(ctx context.Context, targetID string) ([]GetComponentsOfRow, error) {
-	return s.q.GetComponentsOf(ctx, s.conn, targetID)
+	return s.q.GetComponentsOf(ctx, s.db, targetID)
 }
 
 func (s *Store) GetCollectionMembers(ctx context.Context, targetID string) ([]GetCollectionMembersRow, error) {
-	return s.q.GetCollectionMembers(ctx, s.conn, targetID)
+	return s.q.GetCollectionMembers(ctx, s.db, targetID)
+}
+
+func (s *Store) GetDecisionContexts(ctx context.Context, sourceID string) ([]string, error) {
+	return s.q.GetDecisionContexts(ctx, s.db, sourceID)
 }
 
 func (s *Store) GetDependencies(ctx context.Context, sourceID string) ([]GetDependenciesRow, error) {
-	return s.q.GetDependencies(ctx, s.conn, sourceID)
+	return s.q.GetDependencies(ctx, s.db, sourceID)
+}
+
+func (s *Store) GetDependents(ctx context.Context, targetID string) ([]GetDependentsRow, error) {
+	return s.q.GetDependents(ctx, s.db, targetID)
 }
 
 func (s *Store) GetHolonsByParent(ctx context.Context, parentID string) ([]Holon, error) {
-	return s.q.GetHolonsByParent(ctx, s.conn, toNullString(parentID))
+	return s.q.GetHolonsByParent(ctx, s.db, toNullString(parentID))
 }
 
 func (s *Store) GetHolonLineage(ctx context.Context, id string) ([]GetHolonLineageRow, error) {
-	return s.q.GetHolonLineage(ctx, s.conn, id)
+	return s.q.GetHolonLineage(ctx, s.db, id)
 }
 
 func (s *Store) CountHolonsByLayer(ctx context.Context, contextID string) ([]CountHolonsByLayerRow, error) {
-	return s.q.CountHolonsByLayer(ctx, s.conn, contextID)
+	return s.q.CountHolonsByLayer(ctx, s.db, contextID)
 }
 
 func (s *Store) GetLatestHolonByContext(ctx context.Context, contextID string) (Holon, error) {
-	return s.q.GetLatestHolonByContext(ctx, s.conn, contextID)
-}
-
-func (s *Store) InsertAuditLog(ctx context.Context, id, toolName, operation, actor, targetID, inputHash, result, details, contextID string) error {
-	return s.q.InsertAuditLog(ctx, s.conn, InsertAuditLogParams{
-		ID:        id,
-		ToolName:  toolName,
-		Operation: operation,
-		Actor:     actor,
-		TargetID:  toNullString(targetID),
-		InputHash: toNullString(inputHash),
-		Result:    result,
-		Details:   toNullString(details),
-		ContextID: contextID,
-	})
+	return s.q.GetLatestHolonByContext(ctx, s.db, contextID)
 }
 
 func (s *Store) GetAuditLogByContext(ctx context.Context, contextID string) ([]AuditLog, error) {
-	return s.q.GetAuditLogByContext(ctx, s.conn, contextID)
+	return s.q.GetAuditLogByContext(ctx, s.db, contextID)
 }
 
 func (s *Store) GetAuditLogByTarget(ctx context.Context, targetID string) ([]AuditLog, error) {
-	return s.q.GetAuditLogByTarget(ctx, s.conn, toNullString(targetID))
+	return s.q.GetAuditLogByTarget(ctx, s.db, toNullString(targetID))
 }
 
 func (s *Store) GetRecentAuditLog(ctx context.Context, limit int64) ([]AuditLog, error) {
-	return s.q.GetRecentAuditLog(ctx, s.conn, limit)
+	return s.q.GetRecentAuditLog(ctx, s.db, limit)
 }
 
 func (s *Store) CreateWaiver(ctx context.Context, id, evidenceID, waivedBy string, waivedUntil time.Time, rationale string) error {
-	return s.q.CreateWaiver(ctx, s.conn, CreateWaiverParams{
+	err := s.q.CreateWaiver(ctx, s.db, CreateWaiverParams{
 		ID:          id,
 		EvidenceID:  evidenceID,
 		WaivedBy:    waivedBy,
@@ -284,18 +430,60 @@ func (s *Store) CreateWaiver(ctx context.Context, id, evidenceID, waivedBy strin
 		Rationale:   rationale,
 		CreatedAt:   sql.NullTime{Time: time.Now(), Valid: true},
 	})
+	if err != nil {
+		return err
+	}
+	evidence, err := s.q.GetEvidenceByID(ctx, s.db, evidenceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil // Waiver on unknown evidence affects no score
+	}
+	if err != nil {
+		return err
+	}
+	return s.InvalidateR(ctx, evidence.HolonID)
 }
 
 func (s *Store) GetActiveWaiverForEvidence(ctx context.Context, evidenceID string) (Waiver, error) {
-	return s.q.GetActiveWaiverForEvidence(ctx, s.conn, evidenceID)
+	return s.q.GetActiveWaiverForEvidence(ctx, s.db, evidenceID)
 }
 
 func (s *Store) GetAllActiveWaivers(ctx context.Context) ([]Waiver, error) {
-	return s.q.GetAllActiveWaivers(ctx, s.conn)
+	return s.q.GetAllActiveWaivers(ctx, s.db)
 }
 
 func (s *Store) GetEvidenceByID(ctx context.Context, id string) (Evidence, error) {
-	return s.q.GetEvidenceByID(ctx, s.conn, id)
+	return s.q.GetEvidenceByID(ctx, s.db, id)
+}
+
+func (s *Store) CreateContext(ctx context.Context, id, description string) error {
+	return s.q.CreateContext(ctx, s.db, CreateContextParams{
+		ID:          id,
+		Description: toNullString(description),
+		CreatedAt:   sql.NullTime{Time: time.Now(), Valid: true},
+	})
+}
+
+func (s *Store) GetContext(ctx context.Context, id string) (Context, error) {
+	return s.q.GetContext(ctx, s.db, id)
+}
+
+func (s *Store) ListContexts(ctx context.Context) ([]Context, error) {
+	return s.q.ListContexts(ctx, s.db)
+}
+
+// GetActiveContext returns the context selected by the last switch
+func (s *Store) GetActiveContext(ctx context.Context) (Context, error) {
+	return s.q.GetActiveContext(ctx, s.db)
+}
+
+// SetActiveContext makes id the only active context
+func (s *Store) SetActiveContext(ctx context.Context, id string) error {
+	return s.q.SetActiveContext(ctx, s.db, id)
+}
+
+// ListCrossContextRelations returns relations with exactly one end in contextID
+func (s *Store) ListCrossContextRelations(ctx context.Context, contextID string) ([]ListCrossContextRelationsRow, error) {
+	return s.q.ListCrossContextRelations(ctx, s.db, contextID)
 }
 
 func toNullString(s string) sql.NullString {
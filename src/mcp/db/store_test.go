@@ -138,6 +138,85 @@ func TestStore_RelationsCRUD(t *testing.T) {
 	}
 }
 
+func TestStore_InvalidateR(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "test.db")
+
+	store, err := NewStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	// part componentOf whole, client dependsOn whole, part memberOf group; other is unrelated
+	for _, id := range []string{"part", "whole", "client", "group", "other"} {
+		if err := store.CreateHolon(ctx, id, "hypothesis", "system", "L1", id, "Content", "ctx1", "", ""); err != nil {
+			t.Fatalf("CreateHolon %s failed: %v", id, err)
+		}
+	}
+	_ = store.CreateRelation(ctx, "part", "componentOf", "whole", 3)
+	_ = store.CreateRelation(ctx, "client", "dependsOn", "whole", 3)
+	_ = store.CreateRelation(ctx, "part", "memberOf", "group", 3)
+
+	dirty := func() map[string]bool {
+		rows, err := store.conn.Query("SELECT id, r_dirty FROM holons")
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		defer rows.Close()
+		result := make(map[string]bool)
+		for rows.Next() {
+			var id string
+			var flag int
+			_ = rows.Scan(&id, &flag)
+			result[id] = flag != 0
+		}
+		return result
+	}
+	markClean := func() {
+		if _, err := store.conn.Exec("UPDATE holons SET r_dirty = 0, r_computed_at = ?", time.Now()); err != nil {
+			t.Fatalf("Failed to reset flags: %v", err)
+		}
+	}
+
+	markClean()
+	if err := store.AddEvidence(ctx, "e1", "part", "test", "Passing", "pass", "L2", "test-runner", ""); err != nil {
+		t.Fatalf("AddEvidence failed: %v", err)
+	}
+	got := dirty()
+	for id, want := range map[string]bool{"part": true, "whole": true, "client": true, "group": true, "other": false} {
+		if got[id] != want {
+			t.Errorf("After evidence on part: expected %s dirty=%v, got %v", id, want, got[id])
+		}
+	}
+
+	markClean()
+	if err := store.CreateWaiver(ctx, "w1", "e1", "user", time.Now().Add(time.Hour), "Accepted"); err != nil {
+		t.Fatalf("CreateWaiver failed: %v", err)
+	}
+	if got := dirty(); !got["part"] || !got["client"] || got["other"] {
+		t.Errorf("Waiver should invalidate part and its dependents only, got %v", got)
+	}
+
+	markClean()
+	if err := store.CreateRelation(ctx, "other", "dependsOn", "client", 3); err != nil {
+		t.Fatalf("CreateRelation failed: %v", err)
+	}
+	if got := dirty(); !got["other"] || got["client"] || got["whole"] {
+		t.Errorf("New dependsOn should invalidate only the dependent side, got %v", got)
+	}
+
+	markClean()
+	if err := store.UpdateHolonLayer(ctx, "whole", "L2"); err != nil {
+		t.Fatalf("UpdateHolonLayer failed: %v", err)
+	}
+	if got := dirty(); !got["whole"] || !got["client"] || !got["other"] || got["part"] {
+		t.Errorf("Layer move should invalidate whole and its transitive dependents, got %v", got)
+	}
+}
+
 func TestStore_WorkRecords(t *testing.T) {
 	tempDir := t.TempDir()
 	dbPath := filepath.Join(tempDir, "test.db")
@@ -242,6 +321,114 @@ func TestStore_AuditLog(t *testing.T) {
 	}
 }
 
+func TestStore_Contexts(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "test.db")
+
+	store, err := NewStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	active, err := store.GetActiveContext(ctx)
+	if err != nil {
+		t.Fatalf("GetActiveContext failed: %v", err)
+	}
+	if active.ID != "default" {
+		t.Errorf("Expected default context to be active on a fresh store, got %q", active.ID)
+	}
+
+	if err := store.CreateContext(ctx, "billing", "Billing subsystem"); err != nil {
+		t.Fatalf("CreateContext failed: %v", err)
+	}
+	if err := store.CreateContext(ctx, "billing", ""); err == nil {
+		t.Error("Expected duplicate context to fail")
+	}
+
+	if err := store.SetActiveContext(ctx, "billing"); err != nil {
+		t.Fatalf("SetActiveContext failed: %v", err)
+	}
+	contexts, err := store.ListContexts(ctx)
+	if err != nil {
+		t.Fatalf("ListContexts failed: %v", err)
+	}
+	if len(contexts) != 2 {
+		t.Fatalf("Expected 2 contexts, got %d", len(contexts))
+	}
+	for _, c := range contexts {
+		wantActive := c.ID == "billing"
+		if (c.Active.Int64 == 1) != wantActive {
+			t.Errorf("Context %s: active = %d", c.ID, c.Active.Int64)
+		}
+	}
+
+	_ = store.CreateHolon(ctx, "h-default", "hypothesis", "system", "L0", "Default", "Content", "default", "", "")
+	_ = store.CreateHolon(ctx, "h-billing", "hypothesis", "system", "L0", "Billing", "Content", "billing", "", "")
+	_ = store.CreateHolon(ctx, "h-billing-2", "hypothesis", "system", "L0", "Billing 2", "Content", "billing", "", "")
+	_ = store.CreateRelation(ctx, "h-billing", "dependsOn", "h-default", 2)
+	_ = store.CreateRelation(ctx, "h-billing", "dependsOn", "h-billing-2", 3)
+
+	cross, err := store.ListCrossContextRelations(ctx, "billing")
+	if err != nil {
+		t.Fatalf("ListCrossContextRelations failed: %v", err)
+	}
+	if len(cross) != 1 || cross[0].TargetID != "h-default" || cross[0].TargetContext != "default" {
+		t.Errorf("Expected only the h-billing → h-default relation, got %+v", cross)
+	}
+	cross, _ = store.ListCrossContextRelations(ctx, "default")
+	if len(cross) != 1 {
+		t.Errorf("Expected the cross-context relation to be visible from default, got %d", len(cross))
+	}
+}
+
+func TestStore_BeginTx(t *testing.T) {
+	tempDir := t.TempDir()
+	store, err := NewStore(filepath.Join(tempDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if _, err := tx.BeginTx(ctx); err == nil {
+		t.Error("Expected nested BeginTx to be rejected")
+	}
+	if err := tx.CreateHolon(ctx, "rolled-back", "hypothesis", "system", "L0", "Rolled Back", "Content", "default", "", ""); err != nil {
+		t.Fatalf("CreateHolon in tx failed: %v", err)
+	}
+	if _, err := tx.GetHolon(ctx, "rolled-back"); err != nil {
+		t.Errorf("Expected the tx to read its own write: %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if _, err := store.GetHolon(ctx, "rolled-back"); err == nil {
+		t.Error("Expected rolled back holon to be absent")
+	}
+
+	tx, err = store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if err := tx.CreateHolon(ctx, "committed", "hypothesis", "system", "L0", "Committed", "Content", "default", "", ""); err != nil {
+		t.Fatalf("CreateHolon in tx failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if _, err := store.GetHolon(ctx, "committed"); err != nil {
+		t.Errorf("Expected committed holon to be visible: %v", err)
+	}
+}
+
 func TestStore_FileCleanup(t *testing.T) {
 	tempDir := t.TempDir()
 	dbPath := filepath.Join(tempDir, "test.db")
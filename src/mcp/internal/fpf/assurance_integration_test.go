@@ -225,3 +225,53 @@ func TestAuditVisualization_ReturnsTree(t *testing.T) {
 
 	t.Logf("Audit tree:\n%s", tree)
 }
+
+func TestAssuranceGuard_WaiverUnblocksExpiredEvidence(t *testing.T) {
+	fsm, database, tempDir := setupAssuranceTestEnv(t)
+	rawDB := database.GetRawDB()
+	ctx := context.Background()
+
+	l2Dir := filepath.Join(tempDir, ".quint", "knowledge", "L2")
+	os.MkdirAll(l2Dir, 0755)
+	l2File := filepath.Join(l2Dir, "waived-holon.md")
+	os.WriteFile(l2File, []byte("Waived hypothesis"), 0644)
+
+	_, err := rawDB.Exec("INSERT INTO holons (id, type, layer, title, content, context_id) VALUES ('waived-holon', 'hypothesis', 'L2', 'Waived', 'Content', 'ctx')")
+	if err != nil {
+		t.Fatalf("Failed to insert holon: %v", err)
+	}
+	_, err = rawDB.Exec("INSERT INTO evidence (id, holon_id, type, content, verdict, valid_until) VALUES ('e1', 'waived-holon', 'test', 'Old test', 'pass', ?)", time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to insert evidence: %v", err)
+	}
+
+	ra := fpf.RoleAssignment{Role: fpf.RoleDecider, SessionID: "test", Context: "test"}
+	ev := &fpf.EvidenceStub{URI: l2File, Type: "hypothesis", HolonID: "waived-holon"}
+
+	if ok, _ := fsm.CanTransition(fpf.PhaseOperation, ra, ev); ok {
+		t.Fatalf("Expected expired evidence to block the transition")
+	}
+
+	if err := database.CreateWaiver(ctx, "w1", "e1", "alice", time.Now().Add(7*24*time.Hour), "Re-run scheduled"); err != nil {
+		t.Fatalf("CreateWaiver failed: %v", err)
+	}
+
+	if ok, msg := fsm.CanTransition(fpf.PhaseOperation, ra, ev); !ok {
+		t.Errorf("Expected waiver to restore R and allow the transition, got: %s", msg)
+	}
+
+	calc := assurance.New(rawDB)
+	report, err := calc.CalculateReliability(ctx, "waived-holon")
+	if err != nil {
+		t.Fatalf("CalculateReliability failed: %v", err)
+	}
+	hasWaiverFactor := false
+	for _, f := range report.Factors {
+		if strings.HasPrefix(f, "Evidence e1 waived by alice until ") {
+			hasWaiverFactor = true
+		}
+	}
+	if !hasWaiverFactor {
+		t.Errorf("Expected waiver factor in report, got: %v", report.Factors)
+	}
+}
@@ -0,0 +1,130 @@
+package fpf
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// AuditPublicKeyFile is the public half of the audit signing key inside
+// .quint/. It is committed with the project so anyone can verify the log.
+const AuditPublicKeyFile = "audit.pub"
+
+// AuditKeyEnv overrides where the private audit signing key is read from
+const AuditKeyEnv = "QUINT_AUDIT_KEY"
+
+// GenerateAuditKey creates an ed25519 key pair for signing the audit log.
+// The public key goes to .quint/audit.pub; the private key is kept outside
+// the repository, in the user's config directory. It returns the private
+// key's path.
+func GenerateAuditKey(fpfDir string) (string, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	keyPath, err := auditKeyPath(pub)
+	if err != nil {
+		return "", err
+	}
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0700); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(keyPath), err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0600); err != nil {
+		return "", fmt.Errorf("failed to write signing key: %w", err)
+	}
+
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(fpfDir, AuditPublicKeyFile), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", AuditPublicKeyFile, err)
+	}
+	return keyPath, nil
+}
+
+// LoadAuditPublicKey reads .quint/audit.pub. It returns nil when the
+// project does not sign its audit log.
+func LoadAuditPublicKey(fpfDir string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(filepath.Join(fpfDir, AuditPublicKeyFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM public key", AuditPublicKeyFile)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", AuditPublicKeyFile, err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ed25519 key", AuditPublicKeyFile)
+	}
+	return pub, nil
+}
+
+// LoadAuditSigningKey reads the private key matching .quint/audit.pub from
+// QUINT_AUDIT_KEY or the user's config directory. It returns nil when the
+// project does not sign its audit log.
+func LoadAuditSigningKey(fpfDir string) (ed25519.PrivateKey, error) {
+	pub, err := LoadAuditPublicKey(fpfDir)
+	if pub == nil || err != nil {
+		return nil, err
+	}
+	keyPath, err := auditKeyPath(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("audit signing key not found: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM private key", keyPath)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", keyPath, err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ed25519 key", keyPath)
+	}
+	if !bytes.Equal(priv.Public().(ed25519.PublicKey), pub) {
+		return nil, fmt.Errorf("%s does not match %s", keyPath, AuditPublicKeyFile)
+	}
+	return priv, nil
+}
+
+// auditKeyPath is where the private key for pub is kept, named by the
+// key's fingerprint so keys of several projects can live side by side
+func auditKeyPath(pub ed25519.PublicKey) (string, error) {
+	if path := os.Getenv(AuditKeyEnv); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no config directory for the audit signing key (set %s): %w", AuditKeyEnv, err)
+	}
+	fingerprint := sha256.Sum256(pub)
+	return filepath.Join(dir, "quint-code", "keys", "audit-"+hex.EncodeToString(fingerprint[:8])+".pem"), nil
+}
@@ -0,0 +1,59 @@
+package fpf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditKey_SignsToolAudits(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+	quintDir := filepath.Join(tempDir, ".quint")
+	keyPath := filepath.Join(t.TempDir(), "audit.pem")
+	t.Setenv(AuditKeyEnv, keyPath)
+
+	if key, err := LoadAuditSigningKey(quintDir); key != nil || err != nil {
+		t.Fatalf("Expected no signing key before one is generated, got %v, %v", key, err)
+	}
+	generated, err := GenerateAuditKey(quintDir)
+	if err != nil {
+		t.Fatalf("GenerateAuditKey failed: %v", err)
+	}
+	if generated != keyPath {
+		t.Errorf("Expected the key at %s, got %s", keyPath, generated)
+	}
+	if info, err := os.Stat(keyPath); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected a private key readable only by its owner, got %v (%v)", info, err)
+	}
+
+	signed := NewTools(tools.FSM, tempDir, tools.DB)
+	if _, err := signed.ProposeHypothesis("Signed", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+
+	pub, err := LoadAuditPublicKey(quintDir)
+	if err != nil || pub == nil {
+		t.Fatalf("LoadAuditPublicKey failed: %v", err)
+	}
+	reports, err := signed.DB.VerifyAuditChain(context.Background(), pub, false)
+	if err != nil {
+		t.Fatalf("VerifyAuditChain failed: %v", err)
+	}
+	if len(reports) != 1 || reports[0].Broken != nil || reports[0].Signed == 0 {
+		t.Errorf("Expected a signed, intact chain, got %+v", reports)
+	}
+
+	other := filepath.Join(t.TempDir(), "other")
+	if err := os.MkdirAll(other, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(AuditKeyEnv, filepath.Join(other, "audit.pem"))
+	if _, err := GenerateAuditKey(other); err != nil {
+		t.Fatalf("GenerateAuditKey failed: %v", err)
+	}
+	if _, err := LoadAuditSigningKey(quintDir); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Expected a key for another project to be rejected, got %v", err)
+	}
+}
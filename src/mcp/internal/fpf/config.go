@@ -0,0 +1,278 @@
+package fpf
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/m0n0x41d/quint-code/assurance"
+)
+
+// ConfigFileName is the project-level configuration file inside .quint/
+const ConfigFileName = "config.json"
+
+// Config is the project-level configuration read from .quint/config.json.
+// Every section is optional; a missing file yields an empty Config.
+type Config struct {
+	Assurance  AssuranceConfig  `json:"assurance"`
+	Governance GovernanceConfig `json:"governance"`
+}
+
+// GovernanceConfig constrains who may perform which step
+type GovernanceConfig struct {
+	// SeparationOfDuties keeps one performer from running successive steps on a holon
+	SeparationOfDuties *DutiesConfig `json:"separation_of_duties,omitempty"`
+}
+
+// DutiesConfig is the separation-of-duties policy as written in config.json.
+// Rules map a guarded tool to the earlier steps its performer must not have
+// done and are merged over the built-in rules; an empty list drops a rule.
+type DutiesConfig struct {
+	Mode  string              `json:"mode"`
+	Rules map[string][]string `json:"rules,omitempty"`
+}
+
+// AssuranceConfig tunes the trust calculus (B.3)
+type AssuranceConfig struct {
+	// CLPenaltyProfile names a built-in Φ(CL) profile: default, fpf-normative, lenient, strict
+	CLPenaltyProfile string `json:"cl_penalty_profile,omitempty"`
+	// CLPenaltyTable sets custom penalties for CL0..CL3 and takes precedence over the profile
+	CLPenaltyTable []float64 `json:"cl_penalty_table,omitempty"`
+	// EvidenceDecay maps an evidence type (or "default") to its decay model
+	EvidenceDecay map[string]DecayConfig `json:"evidence_decay,omitempty"`
+	// EvidenceAggregation selects how evidence scores combine into the self score
+	EvidenceAggregation *AggregationConfig `json:"evidence_aggregation,omitempty"`
+}
+
+// DecayConfig is one evidence decay model as written in config.json.
+// Durations accept Go syntax plus d/w units, e.g. "90d", "2w", "36h".
+type DecayConfig struct {
+	Model    string   `json:"model"`
+	HalfLife string   `json:"half_life,omitempty"`
+	Ramp     string   `json:"ramp,omitempty"`
+	Floor    *float64 `json:"floor,omitempty"`
+}
+
+// LoadConfig reads .quint/config.json from fpfDir
+func LoadConfig(fpfDir string) (*Config, error) {
+	cfg := &Config{}
+	data, err := os.ReadFile(filepath.Join(fpfDir, ConfigFileName))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ConfigFileName, err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ConfigFileName, err)
+	}
+	return cfg, nil
+}
+
+// PenaltyProfile resolves the configured Φ(CL). The boolean is false when
+// the config does not mention a penalty, so the stored profile stays in force.
+func (c *Config) PenaltyProfile() (assurance.PenaltyProfile, bool, error) {
+	a := c.Assurance
+	if len(a.CLPenaltyTable) > 0 {
+		if len(a.CLPenaltyTable) != len(assurance.PenaltyProfile{}.Table) {
+			return assurance.PenaltyProfile{}, false, fmt.Errorf("cl_penalty_table must have 4 entries (CL0..CL3), got %d", len(a.CLPenaltyTable))
+		}
+		p := assurance.PenaltyProfile{Name: "custom"}
+		copy(p.Table[:], a.CLPenaltyTable)
+		if err := p.Validate(); err != nil {
+			return assurance.PenaltyProfile{}, false, err
+		}
+		return p, true, nil
+	}
+
+	if a.CLPenaltyProfile != "" {
+		p, ok := assurance.PenaltyProfileByName(a.CLPenaltyProfile)
+		if !ok {
+			return assurance.PenaltyProfile{}, false, fmt.Errorf("unknown cl_penalty_profile %q (available: %s)", a.CLPenaltyProfile, strings.Join(assurance.PenaltyProfileNames(), ", "))
+		}
+		return p, true, nil
+	}
+
+	return assurance.PenaltyProfile{}, false, nil
+}
+
+// AggregationConfig is the evidence aggregation as written in config.json.
+// Weights are merged over the built-in ones; prior is [α, β] for the beta strategy.
+type AggregationConfig struct {
+	Strategy       string             `json:"strategy"`
+	LevelWeights   map[string]float64 `json:"assurance_level_weights,omitempty"`
+	CarrierWeights map[string]float64 `json:"carrier_weights,omitempty"`
+	Prior          []float64          `json:"prior,omitempty"`
+}
+
+// AggregationPolicy resolves the configured evidence aggregation. The boolean
+// is false when the config has no evidence_aggregation section.
+func (c *Config) AggregationPolicy() (assurance.AggregationPolicy, bool, error) {
+	ac := c.Assurance.EvidenceAggregation
+	if ac == nil {
+		return assurance.AggregationPolicy{}, false, nil
+	}
+
+	policy := assurance.DefaultAggregationPolicy()
+	if ac.Strategy != "" {
+		policy.Strategy = strings.ToLower(ac.Strategy)
+	}
+	for k, w := range ac.LevelWeights {
+		policy.LevelWeights[strings.ToLower(k)] = w
+	}
+	for k, w := range ac.CarrierWeights {
+		policy.CarrierWeights[strings.ToLower(k)] = w
+	}
+	if len(ac.Prior) > 0 {
+		if len(ac.Prior) != 2 {
+			return assurance.AggregationPolicy{}, false, fmt.Errorf("evidence_aggregation.prior must be [alpha, beta], got %d values", len(ac.Prior))
+		}
+		policy.Prior = [2]float64{ac.Prior[0], ac.Prior[1]}
+	}
+	if err := policy.Validate(); err != nil {
+		return assurance.AggregationPolicy{}, false, fmt.Errorf("evidence_aggregation: %w", err)
+	}
+	return policy, true, nil
+}
+
+// DutiesPolicy resolves the configured separation-of-duties policy. The
+// boolean is false when the config has no separation_of_duties section.
+func (c *Config) DutiesPolicy() (DutiesPolicy, bool, error) {
+	dc := c.Governance.SeparationOfDuties
+	if dc == nil {
+		return DutiesPolicy{}, false, nil
+	}
+
+	policy := DefaultDutiesPolicy()
+	if dc.Mode != "" {
+		policy.Mode = strings.ToLower(dc.Mode)
+	}
+	for tool, earlier := range dc.Rules {
+		if len(earlier) == 0 {
+			delete(policy.Rules, tool)
+			continue
+		}
+		policy.Rules[tool] = earlier
+	}
+	if err := policy.Validate(); err != nil {
+		return DutiesPolicy{}, false, err
+	}
+	return policy, true, nil
+}
+
+// DecayPolicy resolves the configured evidence decay. The boolean is false
+// when the config has no evidence_decay section.
+func (c *Config) DecayPolicy() (assurance.DecayPolicy, bool, error) {
+	if len(c.Assurance.EvidenceDecay) == 0 {
+		return assurance.DecayPolicy{}, false, nil
+	}
+
+	policy := assurance.DefaultDecayPolicy()
+	for typ, dc := range c.Assurance.EvidenceDecay {
+		model, err := dc.model()
+		if err != nil {
+			return assurance.DecayPolicy{}, false, fmt.Errorf("evidence_decay.%s: %w", typ, err)
+		}
+		if strings.EqualFold(typ, "default") {
+			policy.Default = model
+			continue
+		}
+		if policy.ByType == nil {
+			policy.ByType = make(map[string]assurance.DecayModel)
+		}
+		policy.ByType[strings.ToLower(typ)] = model
+	}
+	return policy, true, nil
+}
+
+func (dc DecayConfig) model() (assurance.DecayModel, error) {
+	m := assurance.DecayModel{Model: strings.ToLower(dc.Model), Floor: assurance.DefaultDecayFloor}
+	if m.Model == "" {
+		m.Model = assurance.DecayStep
+	}
+	if dc.Floor != nil {
+		m.Floor = *dc.Floor
+	}
+	var err error
+	if dc.HalfLife != "" {
+		if m.HalfLife, err = assurance.ParseDuration(dc.HalfLife); err != nil {
+			return m, err
+		}
+	}
+	if dc.Ramp != "" {
+		if m.Ramp, err = assurance.ParseDuration(dc.Ramp); err != nil {
+			return m, err
+		}
+	}
+	return m, m.Validate()
+}
+
+// ApplyConfig loads .quint/config.json and persists any configured settings
+// into the context's fpf_state row, so later runs and reports use them.
+func (t *Tools) ApplyConfig(contextID string) error {
+	cfg, err := LoadConfig(t.GetFPFDir())
+	if err != nil {
+		return err
+	}
+
+	// Each setting that changed is audited once the state has been saved
+	var applied []func()
+
+	profile, ok, err := cfg.PenaltyProfile()
+	if err != nil {
+		return err
+	}
+	if ok && profile != t.FSM.PenaltyProfile() {
+		t.FSM.State.Penalty = profile
+		applied = append(applied, func() {
+			t.AuditLog("config", "set_cl_penalty", "system", "", "SUCCESS", map[string]string{"profile": profile.String()}, "")
+		})
+	}
+
+	policy, ok, err := cfg.DecayPolicy()
+	if err != nil {
+		return err
+	}
+	if ok && !reflect.DeepEqual(policy, t.FSM.DecayPolicy()) {
+		t.FSM.State.Decay = &policy
+		applied = append(applied, func() {
+			t.AuditLog("config", "set_evidence_decay", "system", "", "SUCCESS", map[string]string{"default": policy.Default.String()}, "")
+		})
+	}
+
+	aggregation, ok, err := cfg.AggregationPolicy()
+	if err != nil {
+		return err
+	}
+	if ok && !reflect.DeepEqual(aggregation, t.FSM.AggregationPolicy()) {
+		t.FSM.State.Aggregation = &aggregation
+		applied = append(applied, func() {
+			t.AuditLog("config", "set_evidence_aggregation", "system", "", "SUCCESS", map[string]string{"aggregation": aggregation.String()}, "")
+		})
+	}
+
+	duties, ok, err := cfg.DutiesPolicy()
+	if err != nil {
+		return err
+	}
+	if ok && !reflect.DeepEqual(duties, t.FSM.DutiesPolicy()) {
+		t.FSM.State.Duties = &duties
+		applied = append(applied, func() {
+			t.AuditLog("config", "set_separation_of_duties", "system", "", "SUCCESS", map[string]string{"policy": duties.String()}, "")
+		})
+	}
+
+	if len(applied) == 0 || t.FSM.DB == nil {
+		return nil
+	}
+	if err := t.FSM.SaveState(contextID); err != nil {
+		return err
+	}
+	for _, audit := range applied {
+		audit()
+	}
+	return nil
+}
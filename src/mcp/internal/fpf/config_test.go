@@ -0,0 +1,263 @@
+package fpf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m0n0x41d/quint-code/assurance"
+)
+
+func writeConfig(t *testing.T, tempDir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(tempDir, ".quint", ConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+}
+
+func TestLoadConfig_Missing(t *testing.T) {
+	cfg, err := LoadConfig(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if _, ok, _ := cfg.PenaltyProfile(); ok {
+		t.Errorf("Expected no penalty profile when config is missing")
+	}
+}
+
+func TestConfig_PenaltyProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      AssuranceConfig
+		expected string
+		wantErr  bool
+	}{
+		{"named", AssuranceConfig{CLPenaltyProfile: "strict"}, "strict", false},
+		{"custom table wins", AssuranceConfig{CLPenaltyProfile: "strict", CLPenaltyTable: []float64{0.8, 0.3, 0.1, 0}}, "custom", false},
+		{"unknown name", AssuranceConfig{CLPenaltyProfile: "harsh"}, "", true},
+		{"short table", AssuranceConfig{CLPenaltyTable: []float64{0.8, 0.3}}, "", true},
+		{"non-monotone table", AssuranceConfig{CLPenaltyTable: []float64{0.1, 0.3, 0.1, 0}}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Assurance: tt.cfg}
+			p, ok, err := cfg.PenaltyProfile()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got profile %s", p)
+				}
+				return
+			}
+			if err != nil || !ok {
+				t.Fatalf("Expected profile, got ok=%v err=%v", ok, err)
+			}
+			if p.Name != tt.expected {
+				t.Errorf("Expected profile %s, got %s", tt.expected, p.Name)
+			}
+		})
+	}
+}
+
+func TestApplyConfig_PersistsPenaltyProfile(t *testing.T) {
+	tools, fsm, tempDir := setupTools(t)
+	ctx := context.Background()
+
+	writeConfig(t, tempDir, `{"assurance": {"cl_penalty_profile": "fpf-normative"}}`)
+	if err := tools.ApplyConfig("default"); err != nil {
+		t.Fatalf("ApplyConfig failed: %v", err)
+	}
+
+	reloaded, err := LoadState("default", fsm.DB)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if reloaded.PenaltyProfile().Name != "fpf-normative" {
+		t.Errorf("Expected persisted profile fpf-normative, got %s", reloaded.PenaltyProfile().Name)
+	}
+
+	if err := tools.DB.CreateHolon(ctx, "base", "hypothesis", "system", "L1", "Base", "Content", "default", "global", ""); err != nil {
+		t.Fatalf("CreateHolon failed: %v", err)
+	}
+	if err := tools.DB.CreateHolon(ctx, "top", "hypothesis", "system", "L1", "Top", "Content", "default", "global", ""); err != nil {
+		t.Fatalf("CreateHolon failed: %v", err)
+	}
+	_ = tools.DB.AddEvidence(ctx, "e-base", "base", "test", "ok", "pass", "L2", "test-runner", "2099-12-31")
+	_ = tools.DB.AddEvidence(ctx, "e-top", "top", "test", "ok", "pass", "L2", "test-runner", "2099-12-31")
+	if err := tools.DB.CreateRelation(ctx, "base", "componentOf", "top", 2); err != nil {
+		t.Fatalf("CreateRelation failed: %v", err)
+	}
+
+	result, err := tools.CalculateR("top", "")
+	if err != nil {
+		t.Fatalf("CalculateR failed: %v", err)
+	}
+	if !strings.Contains(result, "R_eff: 0.50") {
+		t.Errorf("Expected R_eff 0.50 under fpf-normative, got: %s", result)
+	}
+	if !strings.Contains(result, "CL Penalty Profile: fpf-normative") {
+		t.Errorf("Expected profile in report, got: %s", result)
+	}
+}
+
+func TestApplyConfig_InvalidProfile(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+	writeConfig(t, tempDir, `{"assurance": {"cl_penalty_profile": "harsh"}}`)
+	if err := tools.ApplyConfig("default"); err == nil {
+		t.Errorf("Expected error for unknown profile")
+	}
+}
+
+func TestConfig_DecayPolicy(t *testing.T) {
+	floor := 0.2
+	cfg := &Config{Assurance: AssuranceConfig{EvidenceDecay: map[string]DecayConfig{
+		"default": {Model: "linear"},
+		"Test":    {Model: "half_life", HalfLife: "30d", Floor: &floor},
+	}}}
+
+	policy, ok, err := cfg.DecayPolicy()
+	if err != nil || !ok {
+		t.Fatalf("Expected decay policy, got ok=%v err=%v", ok, err)
+	}
+	if policy.Default.Model != assurance.DecayLinear || policy.Default.Floor != assurance.DefaultDecayFloor {
+		t.Errorf("Unexpected default model: %+v", policy.Default)
+	}
+	m := policy.ModelFor("test")
+	if m.Model != assurance.DecayHalfLife || m.HalfLife != 30*24*time.Hour || m.Floor != 0.2 {
+		t.Errorf("Unexpected test model: %+v", m)
+	}
+
+	bad := &Config{Assurance: AssuranceConfig{EvidenceDecay: map[string]DecayConfig{"test": {Model: "half_life"}}}}
+	if _, _, err := bad.DecayPolicy(); err == nil {
+		t.Errorf("Expected error for half_life without duration")
+	}
+}
+
+func TestCalculateR_AsOfWithDecay(t *testing.T) {
+	tools, fsm, tempDir := setupTools(t)
+	ctx := context.Background()
+
+	writeConfig(t, tempDir, `{"assurance": {"evidence_decay": {"test": {"model": "half_life", "half_life": "30d"}}}}`)
+	if err := tools.ApplyConfig("default"); err != nil {
+		t.Fatalf("ApplyConfig failed: %v", err)
+	}
+	reloaded, err := LoadState("default", fsm.DB)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if reloaded.DecayPolicy().ModelFor("test").Model != assurance.DecayHalfLife {
+		t.Errorf("Expected persisted half_life model, got %+v", reloaded.DecayPolicy())
+	}
+
+	if err := tools.DB.CreateHolon(ctx, "aging", "hypothesis", "system", "L2", "Aging", "Content", "default", "global", ""); err != nil {
+		t.Fatalf("CreateHolon failed: %v", err)
+	}
+	_ = tools.DB.AddEvidence(ctx, "e-aging", "aging", "test", "ok", "pass", "L2", "test-runner", "2099-12-31")
+
+	result, err := tools.CalculateR("aging", "")
+	if err != nil {
+		t.Fatalf("CalculateR failed: %v", err)
+	}
+	if !strings.Contains(result, "Projected to fall below threshold") {
+		t.Errorf("Expected threshold projection, got: %s", result)
+	}
+
+	result, err = tools.CalculateR("aging", "+60d")
+	if err != nil {
+		t.Fatalf("CalculateR failed: %v", err)
+	}
+	if !strings.Contains(result, "(as of") || !strings.Contains(result, "R_eff: 0.25") {
+		t.Errorf("Expected R_eff 0.25 two half-lives ahead, got: %s", result)
+	}
+	if !strings.Contains(result, "(below)") {
+		t.Errorf("Expected projection to be below threshold, got: %s", result)
+	}
+
+	if _, err := tools.CalculateR("aging", "someday"); err == nil {
+		t.Errorf("Expected error for invalid as_of")
+	}
+}
+
+func TestApplyConfig_EvidenceAggregation(t *testing.T) {
+	tools, fsm, tempDir := setupTools(t)
+	ctx := context.Background()
+
+	writeConfig(t, tempDir, `{"assurance": {"evidence_aggregation": {"strategy": "weighted_mean", "carrier_weights": {"internal-logic": 0.1}}}}`)
+	if err := tools.ApplyConfig("default"); err != nil {
+		t.Fatalf("ApplyConfig failed: %v", err)
+	}
+
+	reloaded, err := LoadState("default", fsm.DB)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	policy := reloaded.AggregationPolicy()
+	if policy.Strategy != assurance.AggregateWeightedMean {
+		t.Errorf("Expected persisted weighted_mean, got %s", policy.Strategy)
+	}
+	if policy.CarrierWeights["internal-logic"] != 0.1 || policy.CarrierWeights["test-runner"] != 1.0 {
+		t.Errorf("Expected configured weights merged over defaults, got %v", policy.CarrierWeights)
+	}
+
+	if err := tools.DB.CreateHolon(ctx, "weighted", "hypothesis", "system", "L2", "Weighted", "Content", "default", "global", ""); err != nil {
+		t.Fatalf("CreateHolon failed: %v", err)
+	}
+	_ = tools.DB.AddEvidence(ctx, "e-run", "weighted", "test", "ok", "pass", "L2", "test-runner", "2099-12-31")
+	_ = tools.DB.AddEvidence(ctx, "e-logic", "weighted", "verification", "hunch", "fail", "L1", "internal-logic", "2099-12-31")
+
+	result, err := tools.CalculateR("weighted", "")
+	if err != nil {
+		t.Fatalf("CalculateR failed: %v", err)
+	}
+	if !strings.Contains(result, "Self Score: 0.95") {
+		t.Errorf("Expected weighted self score 0.95, got: %s", result)
+	}
+	if !strings.Contains(result, "Evidence Aggregation: weighted_mean") {
+		t.Errorf("Expected strategy in report, got: %s", result)
+	}
+
+	writeConfig(t, tempDir, `{"assurance": {"evidence_aggregation": {"strategy": "median"}}}`)
+	if err := tools.ApplyConfig("default"); err == nil {
+		t.Errorf("Expected error for unknown strategy")
+	}
+}
+
+func TestApplyConfig_SeparationOfDuties(t *testing.T) {
+	tools, fsm, tempDir := setupTools(t)
+
+	if fsm.DutiesPolicy().Mode != DutiesOff {
+		t.Errorf("Expected separation of duties to be off by default, got %s", fsm.DutiesPolicy().Mode)
+	}
+
+	writeConfig(t, tempDir, `{"governance": {"separation_of_duties": {"mode": "override", "rules": {"quint_decide": []}}}}`)
+	if err := tools.ApplyConfig("default"); err != nil {
+		t.Fatalf("ApplyConfig failed: %v", err)
+	}
+
+	reloaded, err := LoadState("default", fsm.DB)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	policy := reloaded.DutiesPolicy()
+	if policy.Mode != DutiesOverride {
+		t.Errorf("Expected persisted mode override, got %s", policy.Mode)
+	}
+	if _, ok := policy.Rules["quint_decide"]; ok {
+		t.Errorf("Expected an empty rule to drop quint_decide, got %v", policy.Rules)
+	}
+	if len(policy.Rules["quint_audit"]) != 3 {
+		t.Errorf("Expected the built-in quint_audit rule to remain, got %v", policy.Rules)
+	}
+
+	writeConfig(t, tempDir, `{"governance": {"separation_of_duties": {"mode": "strict"}}}`)
+	if err := tools.ApplyConfig("default"); err == nil {
+		t.Errorf("Expected error for unknown mode")
+	}
+	writeConfig(t, tempDir, `{"governance": {"separation_of_duties": {"mode": "block", "rules": {"quint_audit": ["quint_review"]}}}}`)
+	if err := tools.ApplyConfig("default"); err == nil {
+		t.Errorf("Expected error for unknown step")
+	}
+}
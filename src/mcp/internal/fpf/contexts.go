@@ -0,0 +1,313 @@
+package fpf
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var contextIDRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// holonIDSeparator joins a context and a slug into the ID of a holon outside
+// the default context. Neither context IDs nor slugs contain it.
+const holonIDSeparator = "."
+
+var contextPathRegex = regexp.MustCompile(`/contexts/([a-z0-9][a-z0-9_-]*)/(knowledge|evidence|decisions)/`)
+
+// contextID returns the bounded context the tools currently operate in
+func (t *Tools) contextID() string {
+	if t.FSM == nil {
+		return DefaultContext
+	}
+	return t.FSM.Context()
+}
+
+// contextFile is the vocabulary/invariants file of the current context.
+// The default context keeps the original .quint/context.md location.
+func (t *Tools) contextFile() string {
+	if id := t.contextID(); id != DefaultContext {
+		return filepath.Join(t.GetFPFDir(), "contexts", id+".md")
+	}
+	return filepath.Join(t.GetFPFDir(), "context.md")
+}
+
+// qualifyID is the ID of the holon slug names in contextID. The default
+// context keeps bare slugs, so a project with one context is unchanged.
+func qualifyID(contextID, slug string) string {
+	if contextID == "" || contextID == DefaultContext {
+		return slug
+	}
+	return contextID + holonIDSeparator + slug
+}
+
+// splitHolonID returns the context a holon ID is namespaced to and its slug
+func splitHolonID(id string) (contextID, slug string) {
+	if c, s, ok := strings.Cut(id, holonIDSeparator); ok && contextIDRegex.MatchString(c) {
+		return c, s
+	}
+	return DefaultContext, id
+}
+
+// holonID namespaces the slug of a new holon to the current context
+func (t *Tools) holonID(slug string) string {
+	return qualifyID(t.contextID(), slug)
+}
+
+// resolveID maps a holon reference passed to a tool to the stored ID. A
+// qualified reference is taken as is ("default.<slug>" names a default
+// context holon); a bare slug names the current context's holon if there is
+// one, and the default context's otherwise.
+func (t *Tools) resolveID(ref string) string {
+	if c, slug, ok := strings.Cut(ref, holonIDSeparator); ok {
+		return qualifyID(c, slug)
+	}
+	if ref == "" || t.contextID() == DefaultContext || t.DB == nil {
+		return ref
+	}
+	id := t.holonID(ref)
+	if _, err := t.DB.GetHolon(t.callContext(), id); err == nil {
+		return id
+	}
+	return ref
+}
+
+// projectionDir holds the knowledge, evidence and decisions of a context:
+// .quint itself for the default context, .quint/contexts/<id> for the others
+func (t *Tools) projectionDir(contextID string) string {
+	if contextID == "" || contextID == DefaultContext {
+		return t.GetFPFDir()
+	}
+	return filepath.Join(t.GetFPFDir(), "contexts", contextID)
+}
+
+// holonPath is the knowledge file of a holon in layer
+func (t *Tools) holonPath(layer, id string) string {
+	contextID, slug := splitHolonID(id)
+	return filepath.Join(t.projectionDir(contextID), "knowledge", layer, slug+".md")
+}
+
+// evidencePath is the file of an evidence record on targetID
+func (t *Tools) evidencePath(targetID, evidenceID string) string {
+	contextID, _ := splitHolonID(targetID)
+	return filepath.Join(t.projectionDir(contextID), "evidence", evidenceID)
+}
+
+// decisionPath is the file of a DRR created on date
+func (t *Tools) decisionPath(id string, date time.Time) string {
+	contextID, slug := splitHolonID(id)
+	return filepath.Join(t.projectionDir(contextID), "decisions", fmt.Sprintf("DRR-%s-%s.md", date.Format("2006-01-02"), slug))
+}
+
+// pathContext is the context whose projection holds path
+func pathContext(path string) string {
+	if m := contextPathRegex.FindStringSubmatch(filepath.ToSlash(path)); m != nil {
+		return m[1]
+	}
+	return DefaultContext
+}
+
+func validateContextID(id string) error {
+	if !contextIDRegex.MatchString(id) {
+		return fmt.Errorf("invalid context_id %q: use lowercase letters, digits, '-' or '_'", id)
+	}
+	return nil
+}
+
+// contextStates keeps one FSM per loaded context, so the sessions of a server
+// working in the same context see each other's phase and role changes
+type contextStates struct {
+	mu   sync.Mutex
+	fsms map[string]*FSM
+}
+
+// loadContext reads the FSM of an existing context, or shares the one already
+// loaded for the server's sessions
+func (t *Tools) loadContext(ctx context.Context, id string) (*FSM, error) {
+	if err := validateContextID(id); err != nil {
+		return nil, err
+	}
+	if _, err := t.DB.GetContext(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("context %q does not exist (create it with quint_create_context)", id)
+		}
+		return nil, err
+	}
+	if t.states == nil {
+		return LoadState(id, t.DB.GetRawDB())
+	}
+
+	t.states.mu.Lock()
+	defer t.states.mu.Unlock()
+	if fsm := t.states.fsms[id]; fsm != nil {
+		return fsm, nil
+	}
+	fsm, err := LoadState(id, t.DB.GetRawDB())
+	if err != nil {
+		return nil, err
+	}
+	t.states.fsms[id] = fsm
+	return fsm, nil
+}
+
+// CreateContext registers a new bounded context. The context inherits the
+// project configuration but starts with its own phase and holon counts.
+func (t *Tools) CreateContext(id, description string) (string, error) {
+	defer t.RecordWork("CreateContext", time.Now())
+	if t.DB == nil {
+		return "", fmt.Errorf("DB not initialized")
+	}
+	if err := validateContextID(id); err != nil {
+		return "", err
+	}
+
+	ctx := t.callContext()
+	if _, err := t.DB.GetContext(ctx, id); err == nil {
+		return "", fmt.Errorf("context %q already exists", id)
+	}
+	if err := t.DB.CreateContext(ctx, id, description); err != nil {
+		t.AuditLog("quint_create_context", "create_context", t.actor(), id, "ERROR", map[string]string{"description": description}, err.Error())
+		return "", err
+	}
+
+	restore, err := t.UseContext(id)
+	if err != nil {
+		return "", err
+	}
+	configErr := t.ApplyConfig(id)
+	restore()
+	if configErr != nil {
+		return "", fmt.Errorf("context %q created but config could not be applied: %w", id, configErr)
+	}
+
+	t.AuditLog("quint_create_context", "create_context", t.actor(), id, "SUCCESS", map[string]string{"description": description}, "")
+	return fmt.Sprintf("Context %q created. Switch to it with quint_switch_context or pass context_id to any tool.", id), nil
+}
+
+// SwitchContext makes id the context of this session. It is also saved as the
+// context the next server starts in; other running sessions keep theirs.
+func (t *Tools) SwitchContext(id string) (string, error) {
+	defer t.RecordWork("SwitchContext", time.Now())
+	if t.DB == nil {
+		return "", fmt.Errorf("DB not initialized")
+	}
+
+	ctx := t.callContext()
+	fsm, err := t.loadContext(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if err := t.DB.SetActiveContext(ctx, id); err != nil {
+		return "", err
+	}
+
+	previous := t.contextID()
+	t.FSM = fsm
+	t.AuditLog("quint_switch_context", "switch_context", t.actor(), id, "SUCCESS", map[string]string{"from": previous}, "")
+	t.resourceListChanged() // quint://context now names the new context's file
+	t.resourceUpdated(ResourceURI(ResourceContext, ""))
+	t.toolListChanged()
+	return fmt.Sprintf("Switched context %s → %s (phase %s)", previous, id, fsm.GetPhase()), nil
+}
+
+// UseContext points the tools at another context until restore is called.
+// It serves per-call context_id overrides without changing the active context.
+func (t *Tools) UseContext(id string) (restore func(), err error) {
+	if id == t.contextID() {
+		return func() {}, nil
+	}
+	if t.DB == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	fsm, err := t.loadContext(t.callContext(), id)
+	if err != nil {
+		return nil, err
+	}
+	previous := t.FSM
+	t.FSM = fsm
+	return func() { t.FSM = previous }, nil
+}
+
+// ListContexts reports every context with its phase, layer counts
+// and the relations that cross into other contexts
+func (t *Tools) ListContexts() (string, error) {
+	defer t.RecordWork("ListContexts", time.Now())
+	if t.DB == nil {
+		return "", fmt.Errorf("DB not initialized")
+	}
+
+	ctx := t.callContext()
+	contexts, err := t.DB.ListContexts(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Contexts\n\n")
+	for _, c := range contexts {
+		marker := ""
+		if c.ID == t.contextID() {
+			marker = " (current)"
+		}
+		fsm, err := LoadState(c.ID, t.DB.GetRawDB())
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(fmt.Sprintf("### %s%s\n", c.ID, marker))
+		if c.Description.Valid && c.Description.String != "" {
+			sb.WriteString(fmt.Sprintf("%s\n", c.Description.String))
+		}
+		sb.WriteString(fmt.Sprintf("- Phase: %s\n", fsm.GetPhase()))
+
+		counts, err := t.DB.CountHolonsByLayer(ctx, c.ID)
+		if err != nil {
+			return "", err
+		}
+		byLayer := make(map[string]int64)
+		for _, row := range counts {
+			byLayer[row.Layer] = row.Count
+		}
+		sb.WriteString(fmt.Sprintf("- Holons: L0 %d, L1 %d, L2 %d, invalid %d, DRR %d\n",
+			byLayer["L0"], byLayer["L1"], byLayer["L2"], byLayer["invalid"], byLayer["DRR"]))
+
+		cross, err := t.DB.ListCrossContextRelations(ctx, c.ID)
+		if err != nil {
+			return "", err
+		}
+		for _, r := range cross {
+			sb.WriteString(fmt.Sprintf("- Cross-context: %s (%s) --%s--> %s (%s)\n",
+				r.SourceID, r.SourceContext, r.RelationType, r.TargetID, r.TargetContext))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// crossContext returns the context of the other holon when a relation
+// between sourceID and targetID leaves the source's context
+func (t *Tools) crossContext(ctx context.Context, sourceID, targetID string) (string, string, bool) {
+	source, err := t.DB.GetHolon(ctx, sourceID)
+	if err != nil {
+		return "", "", false
+	}
+	target, err := t.DB.GetHolon(ctx, targetID)
+	if err != nil {
+		return "", "", false
+	}
+	return source.ContextID, target.ContextID, source.ContextID != target.ContextID
+}
+
+// warnCrossContext flags a relation spanning two contexts on stderr and
+// returns the audit detail for it
+func warnCrossContext(sourceID, sourceContext, relationType, targetID, targetContext string) string {
+	fmt.Fprintf(os.Stderr, "Warning: %s relation %s (%s) → %s (%s) crosses contexts; consider a CL below 3\n",
+		relationType, sourceID, sourceContext, targetID, targetContext)
+	return fmt.Sprintf("cross-context: %s → %s", sourceContext, targetContext)
+}
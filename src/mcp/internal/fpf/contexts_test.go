@@ -0,0 +1,228 @@
+package fpf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestContexts_CreateSwitchAndScope(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+	ctx := context.Background()
+
+	if _, err := tools.CreateContext("Billing Service", ""); err == nil {
+		t.Error("Expected invalid context ID to be rejected")
+	}
+	if _, err := tools.CreateContext("billing", "Billing subsystem"); err != nil {
+		t.Fatalf("CreateContext failed: %v", err)
+	}
+	if _, err := tools.CreateContext("billing", ""); err == nil {
+		t.Error("Expected duplicate context to be rejected")
+	}
+	if tools.contextID() != DefaultContext {
+		t.Errorf("CreateContext should not switch contexts, now in %q", tools.contextID())
+	}
+
+	if _, err := tools.ProposeHypothesis("Shared Cache", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+
+	if _, err := tools.SwitchContext("missing"); err == nil {
+		t.Error("Expected switching to an unknown context to fail")
+	}
+	if _, err := tools.SwitchContext("billing"); err != nil {
+		t.Fatalf("SwitchContext failed: %v", err)
+	}
+	if tools.FSM.GetPhase() != PhaseIdle {
+		t.Errorf("New context should derive IDLE, got %s", tools.FSM.GetPhase())
+	}
+
+	if _, err := tools.ProposeHypothesis("Invoice Retry", "Content", "global", "system", "R", "", []string{"shared-cache"}, 2, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	holon, err := tools.DB.GetHolon(ctx, "billing.invoice-retry")
+	if err != nil {
+		t.Fatalf("GetHolon failed: %v", err)
+	}
+	if holon.ContextID != "billing" {
+		t.Errorf("Expected holon in billing context, got %q", holon.ContextID)
+	}
+	if phase := tools.FSM.DerivePhase("billing"); phase != PhaseAbduction {
+		t.Errorf("Expected billing holons to derive ABDUCTION, got %s", phase)
+	}
+	if phase := tools.FSM.DerivePhase(DefaultContext); phase != PhaseAbduction {
+		t.Errorf("Expected default holons to derive ABDUCTION, got %s", phase)
+	}
+
+	active, err := tools.DB.GetActiveContext(ctx)
+	if err != nil || active.ID != "billing" {
+		t.Errorf("Expected billing to be persisted as active, got %q (%v)", active.ID, err)
+	}
+
+	logs, _ := tools.DB.GetAuditLogByContext(ctx, "billing")
+	var crossFlagged bool
+	for _, l := range logs {
+		if l.Operation == "create_relation" && strings.Contains(l.Details.String, "cross-context: default → billing") {
+			crossFlagged = true
+		}
+	}
+	if !crossFlagged {
+		t.Errorf("Expected the cross-context relation to be flagged in the billing audit log")
+	}
+
+	list, err := tools.ListContexts()
+	if err != nil {
+		t.Fatalf("ListContexts failed: %v", err)
+	}
+	for _, want := range []string{"### billing (current)", "### default\n", "Holons: L0 1,", "Cross-context: shared-cache (default) --componentOf--> billing.invoice-retry (billing)"} {
+		if !strings.Contains(list, want) {
+			t.Errorf("Expected context list to contain %q, got:\n%s", want, list)
+		}
+	}
+
+	tree, err := tools.VisualizeAudit("billing.invoice-retry")
+	if err != nil {
+		t.Fatalf("VisualizeAudit failed: %v", err)
+	}
+	if !strings.Contains(tree, "CL:2, cross-context: default") {
+		t.Errorf("Expected audit tree to mark the cross-context edge, got:\n%s", tree)
+	}
+
+	if _, err := tools.RecordContext("Invoice: A bill.", "1. Totals match."); err != nil {
+		t.Fatalf("RecordContext failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".quint", "contexts", "billing.md")); err != nil {
+		t.Errorf("Expected billing context file: %v", err)
+	}
+}
+
+func TestContexts_UseContextRestores(t *testing.T) {
+	tools, fsm, _ := setupTools(t)
+
+	if _, err := tools.CreateContext("search", ""); err != nil {
+		t.Fatalf("CreateContext failed: %v", err)
+	}
+
+	restore, err := tools.UseContext("search")
+	if err != nil {
+		t.Fatalf("UseContext failed: %v", err)
+	}
+	if _, err := tools.ProposeHypothesis("Ranking Model", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	restore()
+
+	if tools.FSM != fsm {
+		t.Error("Expected restore to reinstate the original FSM")
+	}
+	counts, _ := tools.DB.CountHolonsByLayer(context.Background(), DefaultContext)
+	if len(counts) != 0 {
+		t.Errorf("Expected no holons in the default context, got %v", counts)
+	}
+	counts, _ = tools.DB.CountHolonsByLayer(context.Background(), "search")
+	if len(counts) != 1 || counts[0].Count != 1 {
+		t.Errorf("Expected one holon in the search context, got %v", counts)
+	}
+
+	if _, err := tools.UseContext("unknown"); err == nil {
+		t.Error("Expected UseContext on an unknown context to fail")
+	}
+}
+
+func TestContexts_HolonsAreNamespaced(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+	if _, err := tools.CreateContext("billing", ""); err != nil {
+		t.Fatalf("CreateContext failed: %v", err)
+	}
+	if _, err := tools.ProposeHypothesis("Retry Policy", "Default content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	restore, err := tools.UseContext("billing")
+	if err != nil {
+		t.Fatalf("UseContext failed: %v", err)
+	}
+	defer restore()
+	if _, err := tools.ProposeHypothesis("Retry Policy", "Billing content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis with a title taken in another context failed: %v", err)
+	}
+
+	for id, path := range map[string]string{
+		"retry-policy":         filepath.Join(tempDir, ".quint", "knowledge", "L0", "retry-policy.md"),
+		"billing.retry-policy": filepath.Join(tempDir, ".quint", "contexts", "billing", "knowledge", "L0", "retry-policy.md"),
+	} {
+		if _, err := tools.DB.GetHolon(context.Background(), id); err != nil {
+			t.Errorf("Expected holon %s: %v", id, err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be projected: %v", id, err)
+		}
+	}
+	if got := tools.resolveID("retry-policy"); got != "billing.retry-policy" {
+		t.Errorf("Expected a bare slug to name the current context's holon, got %s", got)
+	}
+	if got := tools.resolveID("default.retry-policy"); got != "retry-policy" {
+		t.Errorf("Expected a qualified default reference to name the bare ID, got %s", got)
+	}
+
+	resources, err := tools.ListResources()
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+	var uris []string
+	for _, r := range resources {
+		uris = append(uris, r.URI)
+	}
+	if got := strings.Join(uris, " "); got != ResourceURI(ResourceHolon, "billing.retry-policy") {
+		t.Errorf("Expected only the billing holon to be listed, got %s", got)
+	}
+
+	for _, contextID := range []string{"billing", DefaultContext} {
+		use, err := tools.UseContext(contextID)
+		if err != nil {
+			t.Fatalf("UseContext failed: %v", err)
+		}
+		report, err := tools.Reconcile("", false)
+		use()
+		if err != nil {
+			t.Fatalf("Reconcile failed: %v", err)
+		}
+		if !strings.Contains(report, "Markdown and DB are in sync.") {
+			t.Errorf("Expected the %s context to be in sync, got:\n%s", contextID, report)
+		}
+	}
+}
+
+func TestServer_ContextIsPerSession(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	if _, err := tools.CreateContext("billing", ""); err != nil {
+		t.Fatalf("CreateContext failed: %v", err)
+	}
+	s := NewServer(tools)
+	billing := s.openSession("billing-session", nil)
+	other := s.openSession("other-session", nil)
+
+	callTool(t, s, billing, "quint_switch_context", `{"context_id":"billing"}`)
+	if status := callTool(t, s, other, "quint_status", `{}`); !strings.Contains(status, "context: default") {
+		t.Errorf("Expected a switch in one session to leave the other alone, got %s", status)
+	}
+	if status := callTool(t, s, billing, "quint_status", `{}`); !strings.Contains(status, "context: billing") {
+		t.Errorf("Expected the switching session to stay in billing, got %s", status)
+	}
+
+	propose := `{"title":"Retry Policy","content":"Content","scope":"global","kind":"system","rationale":"R"}`
+	callTool(t, s, other, "quint_propose", propose)
+	callTool(t, s, billing, "quint_propose", propose)
+	callTool(t, s, billing, "quint_verify", `{"hypothesis_id":"retry-policy","checks_json":"{}","verdict":"PASS"}`)
+
+	for id, layer := range map[string]string{"retry-policy": "L0", "billing.retry-policy": "L1"} {
+		holon, err := tools.DB.GetHolon(context.Background(), id)
+		if err != nil {
+			t.Fatalf("GetHolon(%s) failed: %v", id, err)
+		}
+		if holon.Layer != layer {
+			t.Errorf("Expected %s in %s, got %s", id, layer, holon.Layer)
+		}
+	}
+}
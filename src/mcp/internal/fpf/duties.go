@@ -0,0 +1,162 @@
+package fpf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Separation-of-duties modes
+const (
+	DutiesOff      = "off"      // No check
+	DutiesWarn     = "warn"     // Conflicts are reported in the tool output
+	DutiesOverride = "override" // Conflicts block unless override_rationale is given
+	DutiesBlock    = "block"    // Conflicts always block
+)
+
+// stepOperations is the audit_log operation that records each step on a holon
+var stepOperations = map[string]string{
+	"quint_propose": "create_hypothesis",
+	"quint_verify":  "verify_hypothesis",
+	"quint_test":    "add_evidence",
+	"quint_audit":   "add_evidence",
+	"quint_decide":  "finalize_decision",
+}
+
+// DutiesPolicy is the separation-of-duties policy: whoever performed one of
+// the earlier steps listed for a guarded tool may not run that tool on the
+// same holon.
+type DutiesPolicy struct {
+	Mode  string              `json:"mode"`
+	Rules map[string][]string `json:"rules"`
+}
+
+// DefaultDutiesPolicy keeps the check off. Its rules keep the auditor apart
+// from the author and testers, and the decider apart from the author and auditor.
+func DefaultDutiesPolicy() DutiesPolicy {
+	return DutiesPolicy{
+		Mode: DutiesOff,
+		Rules: map[string][]string{
+			"quint_audit":  {"quint_propose", "quint_verify", "quint_test"},
+			"quint_decide": {"quint_propose", "quint_audit"},
+		},
+	}
+}
+
+// Validate checks the mode and that every rule names known steps
+func (p DutiesPolicy) Validate() error {
+	switch p.Mode {
+	case DutiesOff, DutiesWarn, DutiesOverride, DutiesBlock:
+	default:
+		return fmt.Errorf("unknown separation_of_duties mode %q (expected off, warn, override or block)", p.Mode)
+	}
+	for tool, earlier := range p.Rules {
+		if _, ok := stepOperations[tool]; !ok {
+			return fmt.Errorf("separation_of_duties rule for unknown step %q", tool)
+		}
+		for _, step := range earlier {
+			if _, ok := stepOperations[step]; !ok {
+				return fmt.Errorf("separation_of_duties rule for %s names unknown step %q", tool, step)
+			}
+		}
+	}
+	return nil
+}
+
+// String summarises the policy for audit entries
+func (p DutiesPolicy) String() string {
+	tools := make([]string, 0, len(p.Rules))
+	for tool := range p.Rules {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	rules := make([]string, len(tools))
+	for i, tool := range tools {
+		rules[i] = fmt.Sprintf("%s≠%s", tool, strings.Join(p.Rules[tool], "|"))
+	}
+	return fmt.Sprintf("%s [%s]", p.Mode, strings.Join(rules, ", "))
+}
+
+// performerIdentity reduces an audit actor to the performer behind it:
+// Role@session becomes session, so a session switching roles is still itself
+func performerIdentity(actor string) string {
+	if i := strings.Index(actor, "@"); i >= 0 {
+		return actor[i+1:]
+	}
+	return actor
+}
+
+// dutyTarget is the holon a guarded tool acts on
+func dutyTarget(args map[string]string) string {
+	if id := args["hypothesis_id"]; id != "" {
+		return id
+	}
+	return args["winner_id"]
+}
+
+// CheckDuties enforces the separation-of-duties policy before tool runs. It
+// returns a notice for the tool output when the policy warns or is overridden.
+func (t *Tools) CheckDuties(tool string, args map[string]string) (string, error) {
+	if t.DB == nil || t.FSM == nil {
+		return "", nil
+	}
+	policy := t.FSM.DutiesPolicy()
+	earlier := policy.Rules[tool]
+	holonID := dutyTarget(args)
+	if policy.Mode == DutiesOff || len(earlier) == 0 || holonID == "" {
+		return "", nil
+	}
+
+	logs, err := t.DB.GetAuditLogByTarget(t.callContext(), holonID)
+	if err != nil {
+		return "", err
+	}
+
+	me := performerIdentity(t.actor())
+	seen := make(map[string]bool)
+	var conflicts []string
+	for _, step := range earlier {
+		for _, l := range logs {
+			if l.ToolName != step || l.Operation != stepOperations[step] || l.Result != "SUCCESS" {
+				continue
+			}
+			if performerIdentity(l.Actor) != me || seen[step] {
+				continue
+			}
+			seen[step] = true
+			conflicts = append(conflicts, fmt.Sprintf("%s (as %s)", step, l.Actor))
+		}
+	}
+	if len(conflicts) == 0 {
+		return "", nil
+	}
+
+	condition := fmt.Sprintf("separation of duties: %s already performed %s on %s", me, strings.Join(conflicts, ", "), holonID)
+	details := map[string]string{"holon": holonID, "performer": me, "mode": policy.Mode}
+
+	switch policy.Mode {
+	case DutiesWarn:
+		t.AuditLog(tool, "duties_warning", t.actor(), holonID, "SUCCESS", details, condition)
+		return "WARNING: " + condition, nil
+
+	case DutiesOverride:
+		if rationale := strings.TrimSpace(args["override_rationale"]); rationale != "" {
+			t.AuditLog(tool, "duties_override", t.actor(), holonID, "SUCCESS", details, rationale)
+			return fmt.Sprintf("OVERRIDE: %s. Rationale recorded: %s", condition, rationale), nil
+		}
+		t.AuditLog(tool, "duties_check", t.actor(), holonID, "BLOCKED", details, condition)
+		return "", &PreconditionError{
+			Tool:       tool,
+			Condition:  condition,
+			Suggestion: "Have a different session perform this step, or pass override_rationale to accept the conflict on record",
+		}
+
+	default:
+		t.AuditLog(tool, "duties_check", t.actor(), holonID, "BLOCKED", details, condition)
+		return "", &PreconditionError{
+			Tool:       tool,
+			Condition:  condition,
+			Suggestion: "Have a different session assume the role (quint_assume_role) and perform this step",
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package fpf
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// proposeAndTestAs records a hypothesis proposed and tested by session
+func proposeAndTestAs(t *testing.T, tools *Tools, session string) {
+	t.Helper()
+	tools.Session = session
+	if _, err := tools.ProposeHypothesis("Guarded Claim", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	if _, err := tools.ManageEvidence(PhaseInduction, "add", "guarded-claim", "test", "ok", "pass", "L1", "test-runner", ""); err != nil {
+		t.Fatalf("ManageEvidence failed: %v", err)
+	}
+}
+
+func TestCheckDuties_Modes(t *testing.T) {
+	args := map[string]string{"hypothesis_id": "guarded-claim"}
+
+	t.Run("off", func(t *testing.T) {
+		tools, _, _ := setupTools(t)
+		proposeAndTestAs(t, tools, "alice")
+		if notice, err := tools.CheckDuties("quint_audit", args); err != nil || notice != "" {
+			t.Errorf("Expected no check when off, got %q, %v", notice, err)
+		}
+	})
+
+	t.Run("block", func(t *testing.T) {
+		tools, fsm, _ := setupTools(t)
+		fsm.State.Duties = &DutiesPolicy{Mode: DutiesBlock, Rules: DefaultDutiesPolicy().Rules}
+		proposeAndTestAs(t, tools, "alice")
+
+		// Switching roles does not make alice someone else
+		tools.FSM.State.ActiveRole = RoleAssignment{Role: RoleAuditor, SessionID: "alice"}
+		_, err := tools.CheckDuties("quint_audit", args)
+		if err == nil {
+			t.Fatal("Expected alice to be blocked from auditing her own hypothesis")
+		}
+		for _, want := range []string{"alice already performed quint_propose (as alice)", "quint_test (as alice)"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+			}
+		}
+
+		tools.Session = "bob"
+		tools.FSM.State.ActiveRole = RoleAssignment{Role: RoleAuditor, SessionID: "bob"}
+		if _, err := tools.CheckDuties("quint_audit", args); err != nil {
+			t.Errorf("Expected bob to audit alice's hypothesis, got %v", err)
+		}
+
+		// Unguarded tools are never checked
+		tools.Session = "alice"
+		if _, err := tools.CheckDuties("quint_verify", args); err != nil {
+			t.Errorf("Expected quint_verify to be unguarded, got %v", err)
+		}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		tools, fsm, _ := setupTools(t)
+		fsm.State.Duties = &DutiesPolicy{Mode: DutiesOverride, Rules: DefaultDutiesPolicy().Rules}
+		proposeAndTestAs(t, tools, "alice")
+
+		if _, err := tools.CheckDuties("quint_audit", args); err == nil || !strings.Contains(err.Error(), "override_rationale") {
+			t.Errorf("Expected override mode to require a rationale, got %v", err)
+		}
+
+		overridden := map[string]string{"hypothesis_id": "guarded-claim", "override_rationale": "Solo project, no second reviewer"}
+		notice, err := tools.CheckDuties("quint_audit", overridden)
+		if err != nil {
+			t.Fatalf("Expected override with rationale to pass, got %v", err)
+		}
+		if !strings.Contains(notice, "OVERRIDE") {
+			t.Errorf("Expected override notice, got %q", notice)
+		}
+
+		logs, _ := tools.DB.GetAuditLogByTarget(context.Background(), "guarded-claim")
+		var recorded bool
+		for _, l := range logs {
+			if l.Operation == "duties_override" && l.Details.String == "Solo project, no second reviewer" {
+				recorded = true
+			}
+		}
+		if !recorded {
+			t.Error("Expected the override rationale in the audit log")
+		}
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		tools, fsm, _ := setupTools(t)
+		fsm.State.Duties = &DutiesPolicy{Mode: DutiesWarn, Rules: DefaultDutiesPolicy().Rules}
+		proposeAndTestAs(t, tools, "alice")
+
+		notice, err := tools.CheckDuties("quint_decide", map[string]string{"winner_id": "guarded-claim"})
+		if err != nil || !strings.HasPrefix(notice, "WARNING: separation of duties") {
+			t.Errorf("Expected a warning for deciding on own proposal, got %q, %v", notice, err)
+		}
+	})
+}
+
+func TestServer_AssumedSessionIDDoesNotChangeThePerformer(t *testing.T) {
+	tools, fsm, _ := setupTools(t)
+	fsm.State.Duties = &DutiesPolicy{Mode: DutiesBlock, Rules: DefaultDutiesPolicy().Rules}
+	s := NewServer(tools)
+	sess := s.openSession("0123456789abcdef", nil)
+	s.handle(context.Background(), sess, JSONRPCRequest{JSONRPC: "2.0", ID: 0, Method: "initialize", Params: json.RawMessage(`{"clientInfo":{"name":"claude-code","version":"1.0.0"}}`)}, nil)
+
+	callTool(t, s, sess, "quint_propose", `{"title":"Guarded Claim","content":"Content","scope":"global","kind":"system","rationale":"R"}`)
+	callTool(t, s, sess, "quint_verify", `{"hypothesis_id":"guarded-claim","checks_json":"{}","verdict":"PASS"}`)
+	callTool(t, s, sess, "quint_test", `{"hypothesis_id":"guarded-claim","test_type":"internal","result":"ok","verdict":"PASS"}`)
+
+	// Claiming to be someone else only labels the assignment
+	callTool(t, s, sess, "quint_assume_role", `{"role":"Auditor","session_id":"bob"}`)
+	if holder := s.tools.FSM.State.ActiveRole.SessionID; holder != "claude-code/1.0.0#01234567" {
+		t.Errorf("Expected the role bound to the server's session, got %q", holder)
+	}
+
+	resp := s.handle(context.Background(), sess, toolCall(1, "quint_audit", `{"hypothesis_id":"guarded-claim","risks":"none"}`), nil)
+	res := resp.Result.(CallToolResult)
+	if !res.IsError || !strings.Contains(res.Content[0].Text, "separation of duties") {
+		t.Errorf("Expected the audit of its own hypothesis to be refused, got %+v", res)
+	}
+
+	// Naming the holder does not let another session release the role
+	other := s.openSession("fedcba9876543210", nil)
+	s.handle(context.Background(), other, JSONRPCRequest{JSONRPC: "2.0", ID: 0, Method: "initialize", Params: json.RawMessage(`{"clientInfo":{"name":"claude-code","version":"1.0.0"}}`)}, nil)
+	resp = s.handle(context.Background(), other, toolCall(2, "quint_release_role", `{"session_id":"claude-code/1.0.0#01234567"}`), nil)
+	if res := resp.Result.(CallToolResult); !res.IsError {
+		t.Errorf("Expected another session's release to be refused, got %+v", res)
+	}
+	if role := s.tools.FSM.State.ActiveRole.Role; role != RoleAuditor {
+		t.Errorf("Expected the Auditor role to stay bound, got %q", role)
+	}
+}
@@ -0,0 +1,388 @@
+package fpf
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Frontmatter is the YAML header of a holon, evidence or DRR file. Values
+// are strings, string lists ([]string) or dates (time.Time); other values
+// are written as their fmt.Sprint string.
+type Frontmatter map[string]any
+
+const dateLayout = "2006-01-02"
+
+var (
+	frontmatterKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+	dateScalarRegex     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}([Tt ]\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})?)?$`)
+	numberScalarRegex   = regexp.MustCompile(`^[-+]?(\.?[0-9][0-9_]*(\.[0-9_]*)?([eE][-+]?[0-9]+)?|0x[0-9a-fA-F_]+|0o[0-7_]+|\.(inf|Inf|INF)|\.(nan|NaN|NAN))$`)
+)
+
+// reservedScalars resolve to null or booleans when written unquoted
+var reservedScalars = map[string]bool{
+	"~": true, "null": true, "true": true, "false": true,
+	"yes": true, "no": true, "on": true, "off": true, "y": true, "n": true,
+}
+
+// String returns the value at key as text: dates in the form they are
+// written, lists joined with ", ", and "" when the key is missing
+func (f Frontmatter) String(key string) string {
+	switch v := f[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return formatDate(v)
+	case []string:
+		return strings.Join(v, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// List returns the value at key as a list; a single string is a list of one
+func (f Frontmatter) List(key string) []string {
+	switch v := f[key].(type) {
+	case []string:
+		return v
+	case nil:
+		return nil
+	default:
+		if s := f.String(key); s != "" {
+			return []string{s}
+		}
+		return nil
+	}
+}
+
+// Date returns the value at key as a date, parsing strings when needed
+func (f Frontmatter) Date(key string) (time.Time, bool) {
+	switch v := f[key].(type) {
+	case time.Time:
+		return v, true
+	case string:
+		return parseDate(v)
+	default:
+		return time.Time{}, false
+	}
+}
+
+// dateField types a date given as text: a parseable date becomes a
+// time.Time, anything else stays a string
+func dateField(s string) any {
+	if t, ok := parseDate(s); ok {
+		return t
+	}
+	return s
+}
+
+func parseDate(s string) (time.Time, bool) {
+	for _, layout := range []string{dateLayout, time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05Z07:00", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// formatDate writes midnight UTC as a plain date and anything else as RFC 3339
+func formatDate(t time.Time) string {
+	if _, offset := t.Zone(); offset == 0 && t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return t.Format(dateLayout)
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// EncodeFrontmatter renders f as YAML, one key per line in sorted order, so
+// the same fields always produce the same bytes
+func EncodeFrontmatter(f Frontmatter) string {
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(encodeKey(k))
+		b.WriteString(":")
+		switch v := f[k].(type) {
+		case []string:
+			if len(v) == 0 {
+				b.WriteString(" []\n")
+				continue
+			}
+			b.WriteString("\n")
+			for _, item := range v {
+				b.WriteString("  - ")
+				b.WriteString(encodeScalar(item))
+				b.WriteString("\n")
+			}
+		case time.Time:
+			b.WriteString(" ")
+			b.WriteString(formatDate(v))
+			b.WriteString("\n")
+		default:
+			b.WriteString(" ")
+			b.WriteString(encodeScalar(f.String(k)))
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func encodeKey(k string) string {
+	if frontmatterKeyRegex.MatchString(k) && !reservedScalars[strings.ToLower(k)] {
+		return k
+	}
+	return quoteScalar(k)
+}
+
+// encodeScalar writes s plain when YAML would read it back as the same
+// string, and double-quoted otherwise
+func encodeScalar(s string) string {
+	if plainSafe(s) {
+		return s
+	}
+	return quoteScalar(s)
+}
+
+func plainSafe(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) || !utf8.ValidString(s) {
+		return false
+	}
+	if reservedScalars[strings.ToLower(s)] || numberScalarRegex.MatchString(s) || dateScalarRegex.MatchString(s) {
+		return false
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f || r == '\u2028' || r == '\u2029' || r == '\uFEFF' {
+			return false
+		}
+	}
+	return true
+}
+
+func quoteScalar(s string) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\u2028':
+			b.WriteString(`\L`)
+		case '\u2029':
+			b.WriteString(`\P`)
+		case '\uFEFF':
+			b.WriteString(`\uFEFF`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\x%02x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// DecodeFrontmatter parses the YAML between the --- lines of a projection
+// file. It reads what EncodeFrontmatter writes and nothing more: one
+// "key: value" per line, plain or double-quoted values, string lists as
+// "  - item" lines or [], and unquoted dates, which decode to time.Time.
+// A plain value is the rest of its line, so files written before values
+// were quoted still read. Other YAML is an error.
+func DecodeFrontmatter(src string) (Frontmatter, error) {
+	f := make(Frontmatter)
+	list := "" // Key whose "  - item" lines follow
+	for i, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if item, ok := strings.CutPrefix(line, "  - "); ok {
+			if list == "" {
+				return nil, fmt.Errorf("line %d: list item without a key", i+1)
+			}
+			value, _, err := decodeScalar(item)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			items, _ := f[list].([]string)
+			f[list] = append(items, value)
+			continue
+		}
+
+		key, rest, err := splitKey(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if _, dup := f[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", i+1, key)
+		}
+
+		list = ""
+		switch rest {
+		case "":
+			f[key] = ""
+			list = key
+		case "[]":
+			f[key] = []string{}
+		default:
+			value, quoted, err := decodeScalar(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if !quoted && dateScalarRegex.MatchString(value) {
+				if t, ok := parseDate(value); ok {
+					f[key] = t
+					continue
+				}
+			}
+			f[key] = value
+		}
+	}
+	return f, nil
+}
+
+// splitKey separates "key: rest" into the key and the trimmed rest
+func splitKey(line string) (key, rest string, err error) {
+	var after string
+	if line[0] == '"' {
+		value, end, err := readQuoted(line)
+		if err != nil {
+			return "", "", err
+		}
+		key, after = value, line[end:]
+	} else {
+		idx := strings.IndexByte(line, ':')
+		if idx < 0 {
+			return "", "", fmt.Errorf("expected 'key: value', got %q", line)
+		}
+		key, after = line[:idx], line[idx:]
+		if !frontmatterKeyRegex.MatchString(key) {
+			return "", "", fmt.Errorf("invalid key %q", key)
+		}
+	}
+	if after != ":" && !strings.HasPrefix(after, ": ") {
+		return "", "", fmt.Errorf("expected ': ' after key %q", key)
+	}
+	return key, strings.TrimSpace(after[1:]), nil
+}
+
+// decodeScalar parses a plain or double-quoted value and reports whether it
+// was quoted. Plain values that start like other YAML forms are refused
+// rather than misread.
+func decodeScalar(s string) (string, bool, error) {
+	if s[0] == '"' {
+		value, end, err := readQuoted(s)
+		if err != nil {
+			return "", true, err
+		}
+		if end != len(s) {
+			return "", true, fmt.Errorf("unexpected %q after quoted value", s[end:])
+		}
+		return value, true, nil
+	}
+	if strings.ContainsAny(s[:1], "'[{|>") {
+		return "", false, fmt.Errorf("unsupported value %q: write it plain or double-quoted", s)
+	}
+	return s, false, nil
+}
+
+// readQuoted reads the double-quoted value at the start of s, undoing the
+// escapes quoteScalar writes, and returns the index just past the closing
+// quote
+func readQuoted(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '"' {
+			return b.String(), i + 1, nil
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		if i+1 >= len(s) {
+			break
+		}
+		i++
+		switch s[i] {
+		case '"', '\\':
+			b.WriteByte(s[i])
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'L':
+			b.WriteRune('\u2028')
+		case 'P':
+			b.WriteRune('\u2029')
+		case 'x', 'u':
+			width := map[byte]int{'x': 2, 'u': 4}[s[i]]
+			if i+width >= len(s) {
+				return "", 0, fmt.Errorf("short escape in %q", s)
+			}
+			r, err := strconv.ParseUint(s[i+1:i+1+width], 16, 32)
+			if err != nil {
+				return "", 0, fmt.Errorf("invalid escape in %q", s)
+			}
+			b.WriteRune(rune(r))
+			i += width
+		default:
+			return "", 0, fmt.Errorf("unknown escape \\%c in %q", s[i], s)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated double-quoted value %q", s)
+}
+
+// splitFrontmatter separates a projection file into the YAML between its
+// leading --- lines and the body after them
+func splitFrontmatter(content string) (header, body string, ok bool) {
+	first, rest, found := strings.Cut(content, "\n")
+	if !found || strings.TrimRight(first, "\r") != "---" {
+		return "", content, false
+	}
+	offset := len(first) + 1
+	for rest != "" {
+		line, next, more := strings.Cut(rest, "\n")
+		if strings.TrimRight(line, "\r") == "---" {
+			header = content[len(first)+1 : offset]
+			if !more {
+				return header, "", true
+			}
+			return header, next, true
+		}
+		offset += len(line) + 1
+		if !more {
+			break
+		}
+		rest = next
+	}
+	return "", content, false
+}
@@ -0,0 +1,258 @@
+package fpf
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFrontmatter_RoundTrip(t *testing.T) {
+	strs := []string{
+		"plain",
+		"",
+		" leading",
+		"trailing ",
+		"key: value",
+		"ends with colon:",
+		"# not a comment",
+		"value #with hash",
+		"- dash",
+		"[bracketed]",
+		"{braced}",
+		"'single'",
+		`"double"`,
+		`back\slash`,
+		"line\nbreak",
+		"tab\there",
+		"crlf\r\n",
+		"bell\a",
+		"true",
+		"No",
+		"null",
+		"~",
+		"42",
+		"3.14",
+		"0x1F",
+		"2025-01-01",
+		"2025-01-01T10:00:00Z",
+		"@mention",
+		"`code`",
+		"*alias",
+		"&anchor",
+		"!tag",
+		"| pipe",
+		"> fold",
+		"% percent",
+		"ünïcødé — dash",
+		"para\u2028sep",
+		"a:b",
+	}
+
+	for _, s := range strs {
+		f := Frontmatter{"field": s}
+		got, err := DecodeFrontmatter(EncodeFrontmatter(f))
+		if err != nil {
+			t.Errorf("%q: decode failed: %v\n%s", s, err, EncodeFrontmatter(f))
+			continue
+		}
+		if v, ok := got["field"].(string); !ok || v != s {
+			t.Errorf("%q: round-tripped to %#v via\n%s", s, got["field"], EncodeFrontmatter(f))
+		}
+	}
+
+	f := Frontmatter{
+		"depends_on":  []string{"b-holon", "a: colon", "2025-01-01", ""},
+		"empty_list":  []string{},
+		"valid_until": time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+		"created":     time.Date(2025, 3, 31, 14, 5, 6, 0, time.FixedZone("", 2*3600)),
+		"title":       strings.Join(strs, "\n"),
+		"no":          "a key YAML reads as a bool",
+	}
+	got, err := DecodeFrontmatter(EncodeFrontmatter(f))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.List("depends_on"), f["depends_on"]) {
+		t.Errorf("depends_on round-tripped to %#v", got["depends_on"])
+	}
+	if list, ok := got["empty_list"].([]string); !ok || len(list) != 0 {
+		t.Errorf("empty_list round-tripped to %#v", got["empty_list"])
+	}
+	for _, key := range []string{"valid_until", "created"} {
+		date, ok := got[key].(time.Time)
+		if !ok || !date.Equal(f[key].(time.Time)) {
+			t.Errorf("%s round-tripped to %#v", key, got[key])
+		}
+	}
+	if got.String("no") != f["no"] {
+		t.Errorf("Quoted key round-tripped to %#v", got)
+	}
+	if got.String("title") != f["title"] {
+		t.Errorf("title round-tripped to %q", got.String("title"))
+	}
+	if again := EncodeFrontmatter(got); again != EncodeFrontmatter(f) {
+		t.Errorf("Expected re-encoding to be stable, got:\n%s\nwant:\n%s", again, EncodeFrontmatter(f))
+	}
+}
+
+func TestEncodeFrontmatter_Stable(t *testing.T) {
+	f := Frontmatter{
+		"verdict":     "pass",
+		"target":      "redis-caching",
+		"depends_on":  []string{"a", "b"},
+		"valid_until": dateField("2025-06-30"),
+		"carrier_ref": "tests/cache_test.go: TestEviction",
+	}
+	want := "carrier_ref: \"tests/cache_test.go: TestEviction\"\n" +
+		"depends_on:\n  - a\n  - b\n" +
+		"target: redis-caching\n" +
+		"valid_until: 2025-06-30\n" +
+		"verdict: pass\n"
+	for i := 0; i < 10; i++ {
+		if got := EncodeFrontmatter(f); got != want {
+			t.Fatalf("Expected:\n%s\ngot:\n%s", want, got)
+		}
+	}
+}
+
+func TestDecodeFrontmatter_EarlierFiles(t *testing.T) {
+	// Written before values were quoted, with Windows line endings
+	src := "kind: system\r\n" +
+		"scope: global: all teams\r\n" +
+		"title: Cache #1\r\n" +
+		"valid_until: 2025-12-31\r\n" +
+		"date: \"2025-12-31\"\r\n" +
+		"carrier_ref:\r\n" +
+		"\"true\": yes\r\n" +
+		"related:\r\n" +
+		"  - one\r\n" +
+		"  - \"two, three\"\r\n" +
+		"escaped: \"tab\\there \\u00e9 \\x41\"\r\n"
+
+	f, err := DecodeFrontmatter(src)
+	if err != nil {
+		t.Fatalf("DecodeFrontmatter failed: %v", err)
+	}
+
+	checks := map[string]string{
+		"kind":        "system",
+		"scope":       "global: all teams",
+		"title":       "Cache #1",
+		"valid_until": "2025-12-31",
+		"date":        "2025-12-31",
+		"carrier_ref": "",
+		"true":        "yes",
+		"escaped":     "tab\there é A",
+	}
+	for key, want := range checks {
+		if got := f.String(key); got != want {
+			t.Errorf("%s: expected %q, got %q", key, want, got)
+		}
+	}
+	if want := []string{"one", "two, three"}; !reflect.DeepEqual(f.List("related"), want) {
+		t.Errorf("related: expected %v, got %#v", want, f["related"])
+	}
+	if _, ok := f["valid_until"].(time.Time); !ok {
+		t.Errorf("Expected unquoted valid_until to decode as a date, got %T", f["valid_until"])
+	}
+	if _, ok := f["date"].(string); !ok {
+		t.Errorf("Expected quoted date to stay a string, got %T", f["date"])
+	}
+}
+
+func TestDecodeFrontmatter_Errors(t *testing.T) {
+	for _, src := range []string{
+		"no colon here",
+		"kind: \"unterminated",
+		"kind: \"quoted\" trailing",
+		"kind: system\nkind: episteme",
+		"kind:system",
+		"  indented: key",
+		"  - orphan item",
+		"bad: \"\\q\"",
+		"# comment",
+		"scope: 'single'",
+		"depends_on: [a, b]",
+		"map: {a: b}",
+		"notes: |\n  literal",
+		"notes: >\n  folded",
+		"<<<<<<< HEAD\nkind: system\n=======\nkind: episteme\n>>>>>>> branch",
+	} {
+		if _, err := DecodeFrontmatter(src); err == nil {
+			t.Errorf("Expected an error for %q", src)
+		}
+	}
+}
+
+func TestSplitFrontmatter(t *testing.T) {
+	tests := []struct {
+		content, header, body string
+		ok                    bool
+	}{
+		{"---\nkind: system\n---\n\n# Body", "kind: system\n", "\n# Body", true},
+		{"---\r\nkind: system\r\n---\r\nBody", "kind: system\r\n", "Body", true},
+		{"---\n---\nBody", "", "Body", true},
+		{"---\nkind: system\n---", "kind: system\n", "", true},
+		{"# No frontmatter\n---\n", "", "# No frontmatter\n---\n", false},
+		{"---\nkind: system\nno end", "", "---\nkind: system\nno end", false},
+	}
+	for _, tt := range tests {
+		header, body, ok := splitFrontmatter(tt.content)
+		if header != tt.header || body != tt.body || ok != tt.ok {
+			t.Errorf("splitFrontmatter(%q) = %q, %q, %v; want %q, %q, %v", tt.content, header, body, ok, tt.header, tt.body, tt.ok)
+		}
+	}
+}
+
+func TestProjectionFiles_RoundTrip(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+
+	if _, err := tools.ProposeHypothesis("Base Layer", "Base", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	if _, err := tools.ProposeHypothesis("Cache: \"Hot\" Path", "Uses base", "scope: tricky #1", "system", "R", "", []string{"base-layer"}, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+
+	f, err := readProjectionFile(filepath.Join(tempDir, ".quint", "knowledge", "L0", "cache-hot-path.md"))
+	if err != nil {
+		t.Fatalf("readProjectionFile failed: %v", err)
+	}
+	if f.tampered {
+		t.Error("Expected a freshly written file to validate")
+	}
+	if got := f.fields.String("scope"); got != "scope: tricky #1" {
+		t.Errorf("Expected scope to round-trip, got %q", got)
+	}
+	if got := f.fields.List("depends_on"); !reflect.DeepEqual(got, []string{"base-layer"}) {
+		t.Errorf("Expected depends_on [base-layer], got %#v", f.fields["depends_on"])
+	}
+
+	if _, err := tools.ManageEvidence(PhaseInduction, "add", "cache-hot-path", "external", "Vendor docs", "pass", "L1", "https://example.com/a: b", "2030-01-15"); err != nil {
+		t.Fatalf("ManageEvidence failed: %v", err)
+	}
+	evidence, err := filepath.Glob(filepath.Join(tempDir, ".quint", "evidence", "*.md"))
+	if err != nil || len(evidence) != 1 {
+		t.Fatalf("Expected one evidence file, got %v (%v)", evidence, err)
+	}
+	e, err := readProjectionFile(evidence[0])
+	if err != nil {
+		t.Fatalf("readProjectionFile failed: %v", err)
+	}
+	if date, ok := e.fields["valid_until"].(time.Time); !ok || date.Format("2006-01-02") != "2030-01-15" {
+		t.Errorf("Expected valid_until to be a date, got %#v", e.fields["valid_until"])
+	}
+	if got := e.fields.String("carrier_ref"); got != "https://example.com/a: b" {
+		t.Errorf("Expected carrier_ref to round-trip, got %q", got)
+	}
+
+	report, err := tools.Reconcile(ReconcileFromMarkdown, true)
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if !strings.Contains(report, "in sync") {
+		t.Errorf("Expected written files to match the DB, got:\n%s", report)
+	}
+}
@@ -3,6 +3,7 @@ package fpf
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -53,10 +54,14 @@ type EvidenceStub struct {
 
 // State represents the persistent state of the FPF session
 type State struct {
-	Phase              Phase          `json:"phase"`
-	ActiveRole         RoleAssignment `json:"active_role,omitempty"`
-	LastCommit         string         `json:"last_commit,omitempty"`
-	AssuranceThreshold float64        `json:"assurance_threshold,omitempty"`
+	Phase              Phase                        `json:"phase"`
+	ActiveRole         RoleAssignment               `json:"active_role,omitempty"`
+	LastCommit         string                       `json:"last_commit,omitempty"`
+	AssuranceThreshold float64                      `json:"assurance_threshold,omitempty"`
+	Penalty            assurance.PenaltyProfile     `json:"cl_penalty_profile,omitempty"`
+	Decay              *assurance.DecayPolicy       `json:"evidence_decay,omitempty"`
+	Aggregation        *assurance.AggregationPolicy `json:"evidence_aggregation,omitempty"`
+	Duties             *DutiesPolicy                `json:"separation_of_duties,omitempty"`
 }
 
 // TransitionRule defines a valid state change
@@ -66,10 +71,51 @@ type TransitionRule struct {
 	Role Role
 }
 
+// transitionRules is the FPF cycle: every phase change must match one rule.
+// Deduction may fall back to abduction when verification shows the
+// candidates are not enough, and operation ends in IDLE so a new cycle can
+// start. Once testing has begun, new candidates wait for the next cycle.
+var transitionRules = []TransitionRule{
+	{PhaseIdle, PhaseAbduction, RoleAbductor},
+	{PhaseAbduction, PhaseDeduction, RoleDeductor},
+	{PhaseDeduction, PhaseAbduction, RoleAbductor},
+	{PhaseDeduction, PhaseInduction, RoleInductor},
+	{PhaseInduction, PhaseDeduction, RoleDeductor},
+	{PhaseInduction, PhaseAudit, RoleAuditor},
+	{PhaseInduction, PhaseDecision, RoleDecider},
+	{PhaseAudit, PhaseDecision, RoleDecider},
+	{PhaseDecision, PhaseIdle, RoleDecider},
+	{PhaseDecision, PhaseOperation, RoleDecider},
+	{PhaseOperation, PhaseIdle, RoleDecider},
+}
+
+// AllowedTransitions returns the rules that leave the given phase
+func AllowedTransitions(from Phase) []TransitionRule {
+	var rules []TransitionRule
+	for _, rule := range transitionRules {
+		if rule.From == from {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// DefaultContext is the bounded context used when none has been selected
+const DefaultContext = "default"
+
 // FSM manages the state transitions
 type FSM struct {
-	State State
-	DB    *sql.DB
+	State     State
+	DB        *sql.DB
+	ContextID string // Bounded context the state belongs to; empty means DefaultContext
+}
+
+// Context returns the bounded context this FSM operates in
+func (f *FSM) Context() string {
+	if f.ContextID == "" {
+		return DefaultContext
+	}
+	return f.ContextID
 }
 
 // LoadState reads state from fpf_state table in SQLite
@@ -79,7 +125,8 @@ func LoadState(contextID string, db *sql.DB) (*FSM, error) {
 			Phase:              PhaseIdle,
 			AssuranceThreshold: 0.8,
 		},
-		DB: db,
+		DB:        db,
+		ContextID: contextID,
 	}
 
 	if db == nil {
@@ -87,20 +134,28 @@ func LoadState(contextID string, db *sql.DB) (*FSM, error) {
 	}
 
 	row := db.QueryRow(`
-		SELECT active_role, active_session_id, active_role_context, last_commit, assurance_threshold
+		SELECT phase, active_role, active_session_id, active_role_context, last_commit, assurance_threshold, cl_penalty_profile, evidence_decay, evidence_aggregation, separation_of_duties
 		FROM fpf_state WHERE context_id = ?`, contextID)
 
-	var activeRole, activeSessionID, activeRoleContext, lastCommit sql.NullString
+	var phase, activeRole, activeSessionID, activeRoleContext, lastCommit, penalty, decay, aggregation, duties sql.NullString
 	var threshold sql.NullFloat64
 
-	err := row.Scan(&activeRole, &activeSessionID, &activeRoleContext, &lastCommit, &threshold)
+	err := row.Scan(&phase, &activeRole, &activeSessionID, &activeRoleContext, &lastCommit, &threshold, &penalty, &decay, &aggregation, &duties)
 	if err == sql.ErrNoRows {
+		fsm.State.Phase = fsm.DerivePhase(contextID)
 		return fsm, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to load state: %w", err)
 	}
 
+	// States saved before the phase was persisted fall back to the holon data
+	if phase.Valid && phase.String != "" {
+		fsm.State.Phase = Phase(phase.String)
+	} else {
+		fsm.State.Phase = fsm.DerivePhase(contextID)
+	}
+
 	if activeRole.Valid {
 		fsm.State.ActiveRole = RoleAssignment{
 			Role:      Role(activeRole.String),
@@ -114,19 +169,52 @@ func LoadState(contextID string, db *sql.DB) (*FSM, error) {
 	if threshold.Valid {
 		fsm.State.AssuranceThreshold = threshold.Float64
 	}
+	if penalty.Valid && penalty.String != "" {
+		var profile assurance.PenaltyProfile
+		if err := json.Unmarshal([]byte(penalty.String), &profile); err != nil {
+			return nil, fmt.Errorf("failed to decode CL penalty profile: %w", err)
+		}
+		fsm.State.Penalty = profile
+	}
+	if decay.Valid && decay.String != "" {
+		var policy assurance.DecayPolicy
+		if err := json.Unmarshal([]byte(decay.String), &policy); err != nil {
+			return nil, fmt.Errorf("failed to decode evidence decay policy: %w", err)
+		}
+		fsm.State.Decay = &policy
+	}
+	if aggregation.Valid && aggregation.String != "" {
+		var policy assurance.AggregationPolicy
+		if err := json.Unmarshal([]byte(aggregation.String), &policy); err != nil {
+			return nil, fmt.Errorf("failed to decode evidence aggregation policy: %w", err)
+		}
+		fsm.State.Aggregation = &policy
+	}
+	if duties.Valid && duties.String != "" {
+		var policy DutiesPolicy
+		if err := json.Unmarshal([]byte(duties.String), &policy); err != nil {
+			return nil, fmt.Errorf("failed to decode separation of duties policy: %w", err)
+		}
+		fsm.State.Duties = &policy
+	}
 
 	return fsm, nil
 }
 
-// GetPhase returns the current phase, deriving from DB if available
+// GetPhase returns the persisted phase, deriving it from the DB only when
+// none has been recorded yet
 func (f *FSM) GetPhase() Phase {
+	if f.State.Phase != "" {
+		return f.State.Phase
+	}
 	if f.DB != nil {
-		return f.DerivePhase("default")
+		return f.DerivePhase(f.Context())
 	}
-	return f.State.Phase
+	return PhaseIdle
 }
 
-// DerivePhase computes the current phase from holons data in the database
+// DerivePhase estimates the phase from holons data in the database. It seeds
+// contexts whose phase was never persisted.
 func (f *FSM) DerivePhase(contextID string) Phase {
 	if f.DB == nil {
 		return PhaseIdle
@@ -193,23 +281,79 @@ func (f *FSM) SaveState(contextID string) error {
 	if f.DB == nil {
 		return fmt.Errorf("database connection required for SaveState")
 	}
+	return f.SaveStateWith(context.Background(), f.DB, contextID)
+}
 
-	_, err := f.DB.Exec(`
-		INSERT INTO fpf_state (context_id, active_role, active_session_id, active_role_context, last_commit, assurance_threshold, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+// stateWriter is a connection or transaction SaveStateWith writes through
+type stateWriter interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// SaveStateWith writes state through w, so the write can join a transaction
+func (f *FSM) SaveStateWith(ctx context.Context, w stateWriter, contextID string) error {
+
+	var penalty sql.NullString
+	if f.State.Penalty.Name != "" {
+		data, err := json.Marshal(f.State.Penalty)
+		if err != nil {
+			return fmt.Errorf("failed to encode CL penalty profile: %w", err)
+		}
+		penalty = sql.NullString{String: string(data), Valid: true}
+	}
+
+	var decay sql.NullString
+	if f.State.Decay != nil {
+		data, err := json.Marshal(f.State.Decay)
+		if err != nil {
+			return fmt.Errorf("failed to encode evidence decay policy: %w", err)
+		}
+		decay = sql.NullString{String: string(data), Valid: true}
+	}
+
+	var aggregation sql.NullString
+	if f.State.Aggregation != nil {
+		data, err := json.Marshal(f.State.Aggregation)
+		if err != nil {
+			return fmt.Errorf("failed to encode evidence aggregation policy: %w", err)
+		}
+		aggregation = sql.NullString{String: string(data), Valid: true}
+	}
+
+	var duties sql.NullString
+	if f.State.Duties != nil {
+		data, err := json.Marshal(f.State.Duties)
+		if err != nil {
+			return fmt.Errorf("failed to encode separation of duties policy: %w", err)
+		}
+		duties = sql.NullString{String: string(data), Valid: true}
+	}
+
+	_, err := w.ExecContext(ctx, `
+		INSERT INTO fpf_state (context_id, phase, active_role, active_session_id, active_role_context, last_commit, assurance_threshold, cl_penalty_profile, evidence_decay, evidence_aggregation, separation_of_duties, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(context_id) DO UPDATE SET
+			phase = excluded.phase,
 			active_role = excluded.active_role,
 			active_session_id = excluded.active_session_id,
 			active_role_context = excluded.active_role_context,
 			last_commit = excluded.last_commit,
 			assurance_threshold = excluded.assurance_threshold,
+			cl_penalty_profile = excluded.cl_penalty_profile,
+			evidence_decay = excluded.evidence_decay,
+			evidence_aggregation = excluded.evidence_aggregation,
+			separation_of_duties = excluded.separation_of_duties,
 			updated_at = excluded.updated_at`,
 		contextID,
+		string(f.GetPhase()),
 		string(f.State.ActiveRole.Role),
 		f.State.ActiveRole.SessionID,
 		f.State.ActiveRole.Context,
 		f.State.LastCommit,
 		f.State.AssuranceThreshold,
+		penalty,
+		decay,
+		aggregation,
+		duties,
 		time.Now().UTC(),
 	)
 	if err != nil {
@@ -226,6 +370,38 @@ func (f *FSM) GetAssuranceThreshold() float64 {
 	return f.State.AssuranceThreshold
 }
 
+// PenaltyProfile returns the configured Φ(CL), defaulting to the built-in table
+func (f *FSM) PenaltyProfile() assurance.PenaltyProfile {
+	if f.State.Penalty.Name == "" {
+		return assurance.DefaultPenaltyProfile()
+	}
+	return f.State.Penalty
+}
+
+// DecayPolicy returns the configured evidence decay, defaulting to the step model
+func (f *FSM) DecayPolicy() assurance.DecayPolicy {
+	if f.State.Decay == nil {
+		return assurance.DefaultDecayPolicy()
+	}
+	return *f.State.Decay
+}
+
+// AggregationPolicy returns the configured evidence aggregation, defaulting to the plain mean
+func (f *FSM) AggregationPolicy() assurance.AggregationPolicy {
+	if f.State.Aggregation == nil {
+		return assurance.DefaultAggregationPolicy()
+	}
+	return *f.State.Aggregation
+}
+
+// DutiesPolicy returns the configured separation-of-duties policy, defaulting to off
+func (f *FSM) DutiesPolicy() DutiesPolicy {
+	if f.State.Duties == nil {
+		return DefaultDutiesPolicy()
+	}
+	return *f.State.Duties
+}
+
 // CanTransition checks if a role can move the system to a target phase
 func (f *FSM) CanTransition(target Phase, assignment RoleAssignment, evidence *EvidenceStub) (bool, string) {
 	if assignment.Role == "" {
@@ -241,30 +417,17 @@ func (f *FSM) CanTransition(target Phase, assignment RoleAssignment, evidence *E
 		return false, fmt.Sprintf("Role %s is not active in %s phase", assignment.Role, currentPhase)
 	}
 
-	valid := []TransitionRule{
-		{PhaseIdle, PhaseAbduction, RoleAbductor},
-		{PhaseAbduction, PhaseDeduction, RoleDeductor},
-		{PhaseDeduction, PhaseInduction, RoleInductor},
-		{PhaseInduction, PhaseDeduction, RoleDeductor},
-		{PhaseInduction, PhaseAudit, RoleAuditor},
-		{PhaseInduction, PhaseDecision, RoleDecider},
-		{PhaseAudit, PhaseDecision, RoleDecider},
-		{PhaseDecision, PhaseIdle, RoleDecider},
-		{PhaseDecision, PhaseOperation, RoleDecider},
-	}
-
 	isValidTransition := false
-	for _, rule := range valid {
-		if rule.From == currentPhase && rule.To == target {
-			if rule.Role == assignment.Role {
-				isValidTransition = true
-				break
-			}
+	for _, rule := range AllowedTransitions(currentPhase) {
+		if rule.To == target && rule.Role == assignment.Role {
+			isValidTransition = true
+			break
 		}
 	}
 
 	if !isValidTransition {
-		return false, fmt.Sprintf("Invalid transition: %s -> %s by %s", currentPhase, target, assignment.Role)
+		return false, fmt.Sprintf("Invalid transition: %s -> %s by %s. Allowed from %s: %s",
+			currentPhase, target, assignment.Role, currentPhase, describeTransitions(currentPhase))
 	}
 
 	if !validateEvidence(currentPhase, target, evidence) {
@@ -277,6 +440,9 @@ func (f *FSM) CanTransition(target Phase, assignment RoleAssignment, evidence *E
 		}
 
 		calc := assurance.New(f.DB)
+		calc.Penalty = f.PenaltyProfile()
+		calc.Decay = f.DecayPolicy()
+		calc.Aggregation = f.AggregationPolicy()
 		report, err := calc.CalculateReliability(context.Background(), evidence.HolonID)
 		if err != nil {
 			return false, fmt.Sprintf("Failed to calculate assurance: %v", err)
@@ -291,6 +457,19 @@ func (f *FSM) CanTransition(target Phase, assignment RoleAssignment, evidence *E
 	return true, "OK"
 }
 
+// describeTransitions lists the phases reachable from a phase and who may move there
+func describeTransitions(from Phase) string {
+	rules := AllowedTransitions(from)
+	if len(rules) == 0 {
+		return "none"
+	}
+	steps := make([]string, len(rules))
+	for i, rule := range rules {
+		steps[i] = fmt.Sprintf("%s by %s", rule.To, rule.Role)
+	}
+	return strings.Join(steps, ", ")
+}
+
 func validateEvidence(fromPhase, toPhase Phase, evidence *EvidenceStub) bool {
 	if evidence == nil || evidence.URI == "" {
 		return false
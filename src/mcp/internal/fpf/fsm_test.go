@@ -1,8 +1,10 @@
 package fpf
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/m0n0x41d/quint-code/db"
@@ -78,6 +80,45 @@ func TestSaveState(t *testing.T) {
 	}
 }
 
+func TestPhasePersisted(t *testing.T) {
+	tempDir := t.TempDir()
+	database, err := db.NewStore(filepath.Join(tempDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer database.Close()
+
+	ctx := context.Background()
+	// An L0 holon would derive ABDUCTION; the persisted phase must win
+	if err := database.CreateHolon(ctx, "h1", "hypothesis", "system", "L0", "H1", "Content", "default", "", ""); err != nil {
+		t.Fatalf("CreateHolon failed: %v", err)
+	}
+
+	fsm := &FSM{State: State{Phase: PhaseInduction}, DB: database.GetRawDB()}
+	if err := fsm.SaveState("default"); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+	loaded, err := LoadState("default", database.GetRawDB())
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if loaded.GetPhase() != PhaseInduction {
+		t.Errorf("Expected persisted phase INDUCTION, got %s", loaded.GetPhase())
+	}
+
+	// States saved before the phase column existed derive it once
+	if _, err := database.GetRawDB().Exec("UPDATE fpf_state SET phase = NULL WHERE context_id = 'default'"); err != nil {
+		t.Fatalf("failed to clear phase: %v", err)
+	}
+	legacy, err := LoadState("default", database.GetRawDB())
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if legacy.GetPhase() != PhaseAbduction {
+		t.Errorf("Expected legacy state to derive ABDUCTION, got %s", legacy.GetPhase())
+	}
+}
+
 func TestSaveStateWithoutDB(t *testing.T) {
 	fsm := &FSM{State: State{Phase: PhaseDeduction}, DB: nil}
 	err := fsm.SaveState("default")
@@ -142,6 +183,8 @@ func TestCanTransition(t *testing.T) {
 		// So l0Dir works.
 		{"InductionToDecision", PhaseInduction, PhaseDecision, RoleDecider, l2File, true, "OK"},
 		{"DecisionToIdle", PhaseDecision, PhaseIdle, RoleDecider, "any", true, "OK"},
+		{"DeductionBackToAbduction", PhaseDeduction, PhaseAbduction, RoleAbductor, "any", true, "OK"},
+		{"OperationToIdle", PhaseOperation, PhaseIdle, RoleDecider, "any", true, "OK"},
 		{"SelfLoopValid", PhaseAbduction, PhaseAbduction, RoleAbductor, "", true, "OK"},
 	}
 
@@ -168,7 +211,10 @@ func TestCanTransition(t *testing.T) {
 		expectedOk  bool
 	}{
 		{"AbductionToInductionDirect", PhaseAbduction, PhaseInduction, RoleInductor, "", false},
-		{"DeductionToAbduction", PhaseDeduction, PhaseAbduction, RoleAbductor, "", false},
+		{"DeductionToAbductionByDeductor", PhaseDeduction, PhaseAbduction, RoleDeductor, "any", false},
+		{"InductionToAbduction", PhaseInduction, PhaseAbduction, RoleAbductor, "any", false},
+		{"OperationToAbduction", PhaseOperation, PhaseAbduction, RoleAbductor, "any", false},
+		{"OperationToIdleByAbductor", PhaseOperation, PhaseIdle, RoleAbductor, "any", false},
 		{"AbductorInDeduction", PhaseDeduction, PhaseDeduction, RoleAbductor, "", false},
 		{"InvalidRoleForTransition", PhaseAbduction, PhaseDeduction, RoleAbductor, l0Dir, false},
 		{"InvalidPhaseTransition", PhaseDecision, PhaseAbduction, RoleDecider, "", false},
@@ -184,6 +230,12 @@ func TestCanTransition(t *testing.T) {
 			}
 		})
 	}
+
+	fsm.State.Phase = PhaseAbduction
+	_, msg := fsm.CanTransition(PhaseInduction, ra(RoleInductor), ev(l1File))
+	if !strings.Contains(msg, "Allowed from ABDUCTION: DEDUCTION by Deductor") {
+		t.Errorf("Expected the denial to list allowed transitions, got %q", msg)
+	}
 }
 
 func TestIsValidRoleForPhase(t *testing.T) {
@@ -0,0 +1,484 @@
+package fpf
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MCPEndpoint is the path of the streamable HTTP endpoint
+const MCPEndpoint = "/mcp"
+
+// SessionHeader carries the session ID the server assigns at initialize
+const SessionHeader = "Mcp-Session-Id"
+
+// sseKeepAlive is how often an idle event stream gets a comment so proxies
+// keep it open
+const sseKeepAlive = 30 * time.Second
+
+const (
+	sessionIdleTimeout = 30 * time.Minute // Sessions unused this long are closed
+	sessionSweep       = time.Minute      // How often idle sessions are looked for
+	maxHTTPSessions    = 256              // Open sessions beyond which initialize is refused
+)
+
+var errTooManySessions = errors.New("too many open sessions; end one with DELETE or retry later")
+
+// httpTransport serves the MCP streamable HTTP transport: JSON-RPC is POSTed
+// to MCPEndpoint and answered as JSON or an SSE stream, a GET opens a stream
+// for notifications, and a DELETE ends the session
+type httpTransport struct {
+	server *Server
+	token  string
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+// httpSession routes a session's notifications to the POST in progress, or
+// else to its open GET stream. Without either they are dropped.
+type httpSession struct {
+	*session
+
+	mu       sync.Mutex
+	collect  chan interface{} // SSE response of the POST in progress
+	stream   chan interface{} // Open GET stream
+	active   int              // Requests and streams in progress
+	lastSeen time.Time        // When the last of them ended
+}
+
+// use marks the session busy until the returned func is called
+func (hs *httpSession) use() (done func()) {
+	hs.mu.Lock()
+	hs.active++
+	hs.mu.Unlock()
+	return func() {
+		hs.mu.Lock()
+		hs.active--
+		hs.lastSeen = time.Now()
+		hs.mu.Unlock()
+	}
+}
+
+// idleSince reports whether the session has been unused since before cutoff
+func (hs *httpSession) idleSince(cutoff time.Time) bool {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	return hs.active == 0 && hs.lastSeen.Before(cutoff)
+}
+
+func (hs *httpSession) deliver(msg interface{}) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	target := hs.collect
+	if target == nil {
+		target = hs.stream
+	}
+	if target == nil {
+		return
+	}
+	select {
+	case target <- msg:
+	default: // A client that stopped reading misses notifications
+	}
+}
+
+// HTTPHandler serves MCP over streamable HTTP at MCPEndpoint. Every request
+// must carry token as its bearer token. Sessions only end on DELETE; see
+// ListenHTTP for a server that also closes idle ones.
+func (s *Server) HTTPHandler(token string) http.Handler {
+	return s.newHTTPTransport(token).handler()
+}
+
+func (s *Server) newHTTPTransport(token string) *httpTransport {
+	return &httpTransport{server: s, token: token, sessions: make(map[string]*httpSession)}
+}
+
+func (t *httpTransport) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(MCPEndpoint, t)
+	return mux
+}
+
+// ListenHTTP serves MCP over streamable HTTP on addr until ctx is done.
+// Sessions left unused for sessionIdleTimeout are closed.
+func (s *Server) ListenHTTP(ctx context.Context, addr, token string) error {
+	if token == "" {
+		return fmt.Errorf("refusing to serve HTTP without a bearer token")
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go s.watchWaivers(done)
+
+	transport := s.newHTTPTransport(token)
+	go transport.expireSessions(done)
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           transport.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdown)
+	}
+}
+
+func (t *httpTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !t.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="quint-code"`)
+		http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+		return
+	}
+	if !loopbackOrigin(r.Header.Get("Origin")) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		t.handlePost(w, r)
+	case http.MethodGet:
+		t.handleStream(w, r)
+	case http.MethodDelete:
+		if hs := t.lookup(w, r); hs != nil {
+			t.closeSession(hs.id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (t *httpTransport) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(t.token)) == 1
+}
+
+// loopbackOrigin guards against DNS rebinding: browsers may only reach the
+// server from pages served on this machine. Non-browser clients send no
+// Origin.
+func loopbackOrigin(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if u.Hostname() == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(u.Hostname())
+	return ip != nil && ip.IsLoopback()
+}
+
+// lookup finds the session a request names, answering 400 or 404 when it
+// names none or an unknown one
+func (t *httpTransport) lookup(w http.ResponseWriter, r *http.Request) *httpSession {
+	id := r.Header.Get(SessionHeader)
+	if id == "" {
+		http.Error(w, SessionHeader+" header is required after initialize", http.StatusBadRequest)
+		return nil
+	}
+	t.mu.Lock()
+	hs := t.sessions[id]
+	t.mu.Unlock()
+	if hs == nil {
+		http.Error(w, "unknown or expired session; initialize again", http.StatusNotFound)
+	}
+	return hs
+}
+
+func (t *httpTransport) handlePost(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	reqs, batch, err := parseMessage(data)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, rpcError(nil, -32700, "Parse error"))
+		return
+	}
+
+	var hs *httpSession
+	if reqs[0].Method == "initialize" {
+		if len(reqs) > 1 {
+			writeJSON(w, http.StatusBadRequest, rpcError(reqs[0].ID, -32600, "initialize must not be batched"))
+			return
+		}
+		if hs, err = t.openSession(); errors.Is(err, errTooManySessions) {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set(SessionHeader, hs.id)
+	} else if hs = t.lookup(w, r); hs == nil {
+		return
+	}
+	defer hs.use()()
+
+	hasRequests := false
+	for _, req := range reqs {
+		hasRequests = hasRequests || req.ID != nil
+	}
+	// The requests are cancelled when the client hangs up
+	ctx := r.Context()
+	if !hasRequests {
+		t.server.handleBatch(ctx, hs.session, reqs, batch, hs.deliver)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if !acceptsEventStream(r) {
+		resps := t.server.handleBatch(ctx, hs.session, reqs, batch, hs.deliver)
+		if len(resps) == 0 {
+			w.WriteHeader(http.StatusAccepted) // Cancelled
+			return
+		}
+		writeJSON(w, http.StatusOK, resps[0])
+		return
+	}
+
+	// Stream progress and the notifications raised while the requests run,
+	// then the responses
+	events := make(chan interface{}, 256)
+	send := func(msg interface{}) {
+		select {
+		case events <- msg:
+		default:
+		}
+	}
+	hs.mu.Lock()
+	hs.collect = events
+	hs.mu.Unlock()
+	go func() {
+		resps := t.server.handleBatch(ctx, hs.session, reqs, batch, send)
+		hs.mu.Lock()
+		if hs.collect == events { // Another POST may have taken over
+			hs.collect = nil
+		}
+		hs.mu.Unlock()
+		for _, resp := range resps {
+			events <- resp
+		}
+		close(events)
+	}()
+
+	flusher := startEventStream(w)
+	for msg := range events {
+		if err := writeEvent(w, msg); err != nil {
+			continue // Drain so the handler goroutine finishes
+		}
+		flusher.Flush()
+	}
+}
+
+// handleStream holds a GET open as an SSE stream of the session's
+// notifications
+func (t *httpTransport) handleStream(w http.ResponseWriter, r *http.Request) {
+	if !acceptsEventStream(r) {
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "GET opens an SSE stream; send Accept: text/event-stream", http.StatusMethodNotAllowed)
+		return
+	}
+	hs := t.lookup(w, r)
+	if hs == nil {
+		return
+	}
+
+	stream := make(chan interface{}, 256)
+	hs.mu.Lock()
+	if hs.stream != nil {
+		hs.mu.Unlock()
+		http.Error(w, "session already has an open stream", http.StatusConflict)
+		return
+	}
+	hs.stream = stream
+	hs.mu.Unlock()
+	defer hs.use()()
+	defer func() {
+		hs.mu.Lock()
+		hs.stream = nil
+		hs.mu.Unlock()
+	}()
+
+	flusher := startEventStream(w)
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg := <-stream:
+			if err := writeEvent(w, msg); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func (t *httpTransport) openSession() (*httpSession, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.sessions) >= maxHTTPSessions {
+		return nil, errTooManySessions
+	}
+	hs := &httpSession{lastSeen: time.Now()}
+	hs.session = t.server.openSession(id, hs.deliver)
+	t.sessions[id] = hs
+	return hs, nil
+}
+
+// closeSession ends a session and the server session behind it
+func (t *httpTransport) closeSession(id string) {
+	t.mu.Lock()
+	delete(t.sessions, id)
+	t.mu.Unlock()
+	t.server.closeSession(id)
+}
+
+// closeIdle closes the sessions unused since before cutoff and returns how
+// many it closed
+func (t *httpTransport) closeIdle(cutoff time.Time) int {
+	t.mu.Lock()
+	var idle []string
+	for id, hs := range t.sessions {
+		if hs.idleSince(cutoff) {
+			idle = append(idle, id)
+			delete(t.sessions, id)
+		}
+	}
+	t.mu.Unlock()
+	for _, id := range idle {
+		t.server.closeSession(id)
+	}
+	return len(idle)
+}
+
+// expireSessions closes idle sessions until done is closed, so clients that
+// hang up without a DELETE do not hold their session forever
+func (t *httpTransport) expireSessions(done <-chan struct{}) {
+	ticker := time.NewTicker(sessionSweep)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			t.closeIdle(now.Add(-sessionIdleTimeout))
+		}
+	}
+}
+
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+func writeJSON(w http.ResponseWriter, status int, msg interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(msg)
+}
+
+// noFlush stands in for writers that cannot flush
+type noFlush struct{}
+
+func (noFlush) Flush() {}
+
+func startEventStream(w http.ResponseWriter) http.Flusher {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return noFlush{}
+	}
+	flusher.Flush()
+	return flusher
+}
+
+func writeEvent(w io.Writer, msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+	return err
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// DefaultHTTPTokenFile is where serve --http keeps its bearer token unless
+// told otherwise: outside the repository, in the user's config directory
+func DefaultHTTPTokenFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no config directory for the HTTP token (pass --token-file): %w", err)
+	}
+	return filepath.Join(dir, "quint-code", "http.token"), nil
+}
+
+// LoadHTTPToken reads the bearer token from path, creating a random one
+// readable only by the user when the file does not exist yet
+func LoadHTTPToken(path string) (token string, created bool, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if token, err = randomHex(32); err != nil {
+			return "", false, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return "", false, err
+		}
+		if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+			return "", false, fmt.Errorf("failed to write HTTP token: %w", err)
+		}
+		return token, true, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if token = strings.TrimSpace(string(data)); token == "" {
+		return "", false, fmt.Errorf("%s is empty", path)
+	}
+	return token, false, nil
+}
@@ -0,0 +1,202 @@
+package fpf
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mcpPost sends body to the endpoint as the given session, with token as
+// bearer token
+func mcpPost(t *testing.T, url, token, sessionID, accept, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url+MCPEndpoint, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest failed: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if sessionID != "" {
+		req.Header.Set(SessionHeader, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	return string(data)
+}
+
+func TestHTTP_SessionsAndAuth(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	srv := httptest.NewServer(NewServer(tools).HTTPHandler("secret"))
+	defer srv.Close()
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`
+	if resp := mcpPost(t, srv.URL, "", "", "application/json", initialize); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", resp.StatusCode)
+	}
+	if resp := mcpPost(t, srv.URL, "wrong", "", "application/json", initialize); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a wrong token, got %d", resp.StatusCode)
+	}
+
+	resp := mcpPost(t, srv.URL, "secret", "", "application/json", initialize)
+	sessionID := resp.Header.Get(SessionHeader)
+	if resp.StatusCode != http.StatusOK || sessionID == "" {
+		t.Fatalf("Expected initialize to open a session, got %d %q", resp.StatusCode, sessionID)
+	}
+	if body := readBody(t, resp); !strings.Contains(body, `"protocolVersion":"2025-03-26"`) {
+		t.Errorf("Expected the client's protocol version, got %s", body)
+	}
+
+	status := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"quint_status","arguments":{}}}`
+	if resp := mcpPost(t, srv.URL, "secret", "", "application/json", status); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a session, got %d", resp.StatusCode)
+	}
+	if resp := mcpPost(t, srv.URL, "secret", "unknown", "application/json", status); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown session, got %d", resp.StatusCode)
+	}
+
+	resp = mcpPost(t, srv.URL, "secret", sessionID, "application/json", status)
+	if body := readBody(t, resp); resp.StatusCode != http.StatusOK || !strings.Contains(body, "IDLE") {
+		t.Errorf("Expected quint_status over HTTP, got %d %s", resp.StatusCode, body)
+	}
+
+	initialized := `{"jsonrpc":"2.0","method":"notifications/initialized"}`
+	if resp := mcpPost(t, srv.URL, "secret", sessionID, "application/json", initialized); resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected 202 for a notification, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+MCPEndpoint, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set(SessionHeader, sessionID)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected DELETE to end the session (err %v)", err)
+	}
+	if resp := mcpPost(t, srv.URL, "secret", sessionID, "application/json", status); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 after DELETE, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTP_StreamsNotifications(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	srv := httptest.NewServer(NewServer(tools).HTTPHandler("secret"))
+	defer srv.Close()
+
+	resp := mcpPost(t, srv.URL, "secret", "", "application/json", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	sessionID := resp.Header.Get(SessionHeader)
+	resp = mcpPost(t, srv.URL, "secret", sessionID, "application/json", `{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"quint://context"}}`)
+	if body := readBody(t, resp); strings.Contains(body, "error") {
+		t.Fatalf("Subscribe failed: %s", body)
+	}
+
+	call := `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"quint_record_context","arguments":{"vocabulary":"Cache: A fast store.","invariants":"1. Reads are idempotent."}}}`
+	resp = mcpPost(t, srv.URL, "secret", sessionID, "application/json, text/event-stream", call)
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected an SSE response, got %s", ct)
+	}
+	var events []string
+	for _, event := range strings.Split(strings.TrimSpace(readBody(t, resp)), "\n\n") {
+		events = append(events, strings.TrimPrefix(event, "event: message\ndata: "))
+	}
+	if len(events) != 3 ||
+		!strings.Contains(events[0], NotifyResourceListChanged) ||
+		!strings.Contains(events[1], `"uri":"quint://context"`) ||
+		!strings.Contains(events[2], `"id":3`) {
+		t.Errorf("Expected the notifications before the response, got:\n%s", strings.Join(events, "\n"))
+	}
+}
+
+func TestLoadHTTPToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quint-code", "http.token")
+	token, created, err := LoadHTTPToken(path)
+	if err != nil || !created || len(token) != 64 {
+		t.Fatalf("Expected a new token, got %q created=%v (err %v)", token, created, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the token file to be private, got %v (err %v)", info.Mode(), err)
+	}
+
+	again, created, err := LoadHTTPToken(path)
+	if err != nil || created || again != token {
+		t.Errorf("Expected the stored token back, got %q created=%v (err %v)", again, created, err)
+	}
+
+	if err := os.WriteFile(path, []byte("\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LoadHTTPToken(path); err == nil {
+		t.Error("Expected an empty token file to be rejected")
+	}
+}
+
+func TestHTTP_IdleSessionsExpire(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	s := NewServer(tools)
+	transport := s.newHTTPTransport("secret")
+	srv := httptest.NewServer(transport.handler())
+	defer srv.Close()
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`
+	idle := mcpPost(t, srv.URL, "secret", "", "application/json", initialize).Header.Get(SessionHeader)
+	busy := mcpPost(t, srv.URL, "secret", "", "application/json", initialize).Header.Get(SessionHeader)
+	transport.mu.Lock()
+	release := transport.sessions[busy].use() // A request or stream still open
+	transport.mu.Unlock()
+	defer release()
+
+	if closed := transport.closeIdle(time.Now().Add(-time.Minute)); closed != 0 {
+		t.Errorf("Expected recently used sessions to stay open, closed %d", closed)
+	}
+	if closed := transport.closeIdle(time.Now().Add(time.Minute)); closed != 1 {
+		t.Fatalf("Expected the idle session to be closed, closed %d", closed)
+	}
+
+	status := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"quint_status","arguments":{}}}`
+	if resp := mcpPost(t, srv.URL, "secret", idle, "application/json", status); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an expired session, got %d", resp.StatusCode)
+	}
+	s.mu.Lock()
+	_, serverSession := s.sessions[idle]
+	s.mu.Unlock()
+	if serverSession {
+		t.Error("Expected the server session to be closed with it")
+	}
+	if resp := mcpPost(t, srv.URL, "secret", busy, "application/json", status); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the busy session to survive, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTP_LimitsOpenSessions(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	transport := NewServer(tools).newHTTPTransport("secret")
+	for i := 0; i < maxHTTPSessions; i++ {
+		if _, err := transport.openSession(); err != nil {
+			t.Fatalf("openSession %d failed: %v", i, err)
+		}
+	}
+	srv := httptest.NewServer(transport.handler())
+	defer srv.Close()
+
+	resp := mcpPost(t, srv.URL, "secret", "", "application/json", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 once the session limit is reached, got %d", resp.StatusCode)
+	}
+}
@@ -94,7 +94,11 @@ func TestFullFPFWorkflowIntegration(t *testing.T) {
 		if fsm.GetPhase() != fpf.PhaseIdle {
 			t.Fatalf("Expected phase IDLE before first proposal, got %s", fsm.GetPhase())
 		}
-		path, err := tools.ProposeHypothesis(hypo1Title, hypo1Content, "global", "system", "Integration Test Rationale", "", nil, 3)
+		l0Dir := filepath.Join(tempDir, ".quint", "knowledge", "L0")
+		if err := tools.EnterPhase("quint_propose", fpf.PhaseAbduction, fpf.RoleAbductor, ev(l0Dir)); err != nil {
+			t.Fatalf("Failed to enter ABDUCTION: %v", err)
+		}
+		path, err := tools.ProposeHypothesis(hypo1Title, hypo1Content, "global", "system", "Integration Test Rationale", "", nil, 3, 0)
 		if err != nil {
 			t.Fatalf("ProposeHypothesis failed: %v", err)
 		}
@@ -317,7 +321,7 @@ func TestFullFPFWorkflowIntegration(t *testing.T) {
 			t.Fatalf("SaveState failed: %v", err)
 		}
 
-		path, err := tools.FinalizeDecision("Final Decision", finalWinnerID, nil, "Context", "Decision", drrContent, "Consequences", "Characteristics")
+		path, err := tools.FinalizeDecision("Final Decision", finalWinnerID, nil, "Context", "Decision", drrContent, "Consequences", "Characteristics", "")
 		if err != nil {
 			t.Fatalf("FinalizeDecision failed: %v", err)
 		}
@@ -0,0 +1,178 @@
+package fpf
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/m0n0x41d/quint-code/db"
+)
+
+// projection is the markdown side of the knowledge base, the surface that
+// git merges
+type projection struct {
+	holons    map[string]map[string]projectionFile // holon ID → layer → file
+	evidence  map[string]projectionFile            // evidence ID → file
+	decisions map[string][]projectionFile          // DRR holon ID → files, by name
+}
+
+// mergeConflict is a state of the projection that cannot be imported
+// without a human decision
+type mergeConflict struct {
+	kind       string
+	id         string
+	problem    string
+	resolution string
+}
+
+// readProjection scans the knowledge/*, evidence and decisions directories of
+// the current context
+func (t *Tools) readProjection() (*projection, error) {
+	p := &projection{
+		holons:    make(map[string]map[string]projectionFile),
+		evidence:  make(map[string]projectionFile),
+		decisions: make(map[string][]projectionFile),
+	}
+	dir := t.projectionDir(t.contextID())
+
+	for _, layer := range projectedLayers {
+		found, err := readProjectionDir(filepath.Join(dir, "knowledge", layer))
+		if err != nil {
+			return nil, err
+		}
+		for name, f := range found {
+			id := t.holonID(name)
+			if p.holons[id] == nil {
+				p.holons[id] = make(map[string]projectionFile)
+			}
+			p.holons[id][layer] = f
+		}
+	}
+
+	found, err := readProjectionDir(filepath.Join(dir, "evidence"))
+	if err != nil {
+		return nil, err
+	}
+	for name, f := range found {
+		p.evidence[name+".md"] = f // Evidence IDs are the file names
+	}
+
+	found, err = readProjectionDir(filepath.Join(dir, "decisions"))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range sortedKeys(found) {
+		if !strings.HasPrefix(name, "DRR-") {
+			continue
+		}
+		id := t.holonID(t.Slugify(decisionTitle(found[name])))
+		p.decisions[id] = append(p.decisions[id], found[name])
+	}
+	return p, nil
+}
+
+// decisionTitle is the title in a DRR file's heading, or its file name
+func decisionTitle(f projectionFile) string {
+	if m := decisionTitleRegex.FindStringSubmatch(f.body); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	return strings.TrimSuffix(filepath.Base(f.path), ".md")
+}
+
+// mergeConflicts finds what a git merge of .quint/ can leave behind: files
+// with conflict markers, a holon moved to different layers on each branch,
+// decisions whose titles share a slug, and IDs claimed by both a hypothesis
+// and a decision
+func (t *Tools) mergeConflicts(p *projection, dbHolons, dbDecisions map[string]db.Holon) []mergeConflict {
+	var conflicts []mergeConflict
+
+	for _, id := range sortedKeys(p.holons) {
+		layers := p.holons[id]
+		for _, layer := range sortedKeys(layers) {
+			if f := layers[layer]; f.conflicted {
+				conflicts = append(conflicts, markerConflict("holon", id, t.relPath(f.path)))
+			}
+		}
+		if len(layers) > 1 {
+			paths := make([]string, 0, len(layers))
+			for _, layer := range sortedKeys(layers) {
+				paths = append(paths, t.relPath(layers[layer].path))
+			}
+			resolution := "keep the file in the layer the hypothesis belongs to and git rm the others"
+			if holon, ok := dbHolons[id]; ok {
+				resolution += fmt.Sprintf(" (the DB has it in %s)", holon.Layer)
+			}
+			conflicts = append(conflicts, mergeConflict{
+				kind:       "holon",
+				id:         id,
+				problem:    fmt.Sprintf("moved to different layers on each branch: %s", strings.Join(paths, ", ")),
+				resolution: resolution,
+			})
+		}
+		if holon, ok := dbDecisions[id]; ok {
+			conflicts = append(conflicts, idConflict(id, "hypothesis in markdown", fmt.Sprintf("decision %q in the DB", holon.Title)))
+		}
+	}
+
+	for _, id := range sortedKeys(p.evidence) {
+		if f := p.evidence[id]; f.conflicted {
+			conflicts = append(conflicts, markerConflict("evidence", id, t.relPath(f.path)))
+		}
+	}
+
+	for _, id := range sortedKeys(p.decisions) {
+		files := p.decisions[id]
+		for _, f := range files {
+			if f.conflicted {
+				conflicts = append(conflicts, markerConflict("decision", id, t.relPath(f.path)))
+			}
+		}
+		if len(files) > 1 {
+			paths := make([]string, len(files))
+			for i, f := range files {
+				paths[i] = t.relPath(f.path)
+			}
+			conflicts = append(conflicts, mergeConflict{
+				kind:       "decision",
+				id:         id,
+				problem:    fmt.Sprintf("titles of %s share the slug %q", strings.Join(paths, ", "), id),
+				resolution: "retitle one decision so its slug is unique, or git rm the duplicate",
+			})
+		}
+		if _, ok := p.holons[id]; ok {
+			conflicts = append(conflicts, idConflict(id, "hypothesis", "decision"))
+		} else if holon, ok := dbHolons[id]; ok {
+			conflicts = append(conflicts, idConflict(id, "decision in markdown", fmt.Sprintf("hypothesis %q in the DB", holon.Title)))
+		}
+	}
+
+	sort.SliceStable(conflicts, func(i, j int) bool { return conflicts[i].id < conflicts[j].id })
+	return conflicts
+}
+
+func markerConflict(kind, id, path string) mergeConflict {
+	return mergeConflict{
+		kind:       kind,
+		id:         id,
+		problem:    fmt.Sprintf("unresolved git conflict markers in %s", path),
+		resolution: "resolve the conflict in the file and commit it",
+	}
+}
+
+func idConflict(id, one, other string) mergeConflict {
+	return mergeConflict{
+		kind:       "id",
+		id:         id,
+		problem:    fmt.Sprintf("claimed by a %s and a %s", one, other),
+		resolution: "retitle one of them so the slugs differ",
+	}
+}
+
+// relPath shows a projection path relative to the project root
+func (t *Tools) relPath(path string) string {
+	if rel, err := filepath.Rel(t.RootDir, path); err == nil {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,102 @@
+package fpf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeConflicts_BlockImport(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+	quintDir := filepath.Join(tempDir, ".quint")
+
+	for _, title := range []string{"Shared Cache", "Use Cache"} {
+		if _, err := tools.ProposeHypothesis(title, "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+			t.Fatalf("ProposeHypothesis failed: %v", err)
+		}
+	}
+	if _, err := tools.ManageEvidence(PhaseDeduction, "add", "shared-cache", "note", "Observed", "pass", "L0", "", ""); err != nil {
+		t.Fatalf("ManageEvidence failed: %v", err)
+	}
+
+	// Both branches moved shared-cache out of L0, to different layers
+	l0 := filepath.Join(quintDir, "knowledge", "L0", "shared-cache.md")
+	data, _ := os.ReadFile(l0)
+	for _, layer := range []string{"L1", "invalid"} {
+		if err := os.WriteFile(filepath.Join(quintDir, "knowledge", layer, "shared-cache.md"), data, 0644); err != nil {
+			t.Fatalf("failed to write moved file: %v", err)
+		}
+	}
+	if err := os.Remove(l0); err != nil {
+		t.Fatalf("failed to remove L0 file: %v", err)
+	}
+
+	// Both branches edited the same evidence
+	evidence, _ := filepath.Glob(filepath.Join(quintDir, "evidence", "*.md"))
+	conflicted := "<<<<<<< HEAD\nours\n=======\ntheirs\n>>>>>>> feature\n"
+	if err := os.WriteFile(evidence[0], []byte(conflicted), 0644); err != nil {
+		t.Fatalf("failed to write conflicted evidence: %v", err)
+	}
+
+	// Both branches decided under the same title, and it slugs to a hypothesis ID
+	decisions := filepath.Join(quintDir, "decisions")
+	for _, name := range []string{"DRR-2025-01-01-use-cache.md", "DRR-2025-01-02-use-cache.md"} {
+		if err := WriteWithHash(filepath.Join(decisions, name), Frontmatter{"type": "DRR"}, "\n# Use Cache\n\nBody of "+name); err != nil {
+			t.Fatalf("failed to write DRR: %v", err)
+		}
+	}
+
+	report, err := tools.Reconcile(ReconcileFromMarkdown, true)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	for _, want := range []string{
+		"### Conflicts (4)",
+		"holon `shared-cache`: moved to different layers on each branch: .quint/knowledge/L1/shared-cache.md, .quint/knowledge/invalid/shared-cache.md → keep the file in the layer the hypothesis belongs to and git rm the others (the DB has it in L0)",
+		"unresolved git conflict markers in .quint/evidence/",
+		"decision `use-cache`: titles of .quint/decisions/DRR-2025-01-01-use-cache.md, .quint/decisions/DRR-2025-01-02-use-cache.md share the slug",
+		"id `use-cache`: claimed by a hypothesis and a decision",
+		"cannot be rebuilt from the markdown until these are resolved",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, report)
+		}
+	}
+
+	if _, err := tools.Reconcile(ReconcileFromMarkdown, false); err == nil || !strings.Contains(err.Error(), "4 merge conflicts") {
+		t.Fatalf("Expected the import to be blocked, got %v", err)
+	}
+	if _, err := tools.DB.GetHolon(ctx, "use-cache"); err != nil {
+		t.Fatalf("GetHolon failed: %v", err)
+	}
+	holon, _ := tools.DB.GetHolon(ctx, "shared-cache")
+	if holon.Layer != "L0" {
+		t.Errorf("Blocked import must not touch the DB, shared-cache is in %s", holon.Layer)
+	}
+
+	// Resolve: keep L1, fix the evidence, drop one DRR and retitle the other
+	if err := os.Remove(filepath.Join(quintDir, "knowledge", "invalid", "shared-cache.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteWithHash(evidence[0], Frontmatter{"type": "note", "target": "shared-cache", "verdict": "pass", "assurance_level": "L0"}, "\nMerged"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(decisions, "DRR-2025-01-02-use-cache.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteWithHash(filepath.Join(decisions, "DRR-2025-01-01-use-cache.md"), Frontmatter{"type": "DRR", "winner_id": "use-cache"}, "\n# Adopt Cache\n\nBody"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tools.Reconcile(ReconcileFromMarkdown, false); err != nil {
+		t.Fatalf("Expected the import to succeed once resolved, got %v", err)
+	}
+	holon, _ = tools.DB.GetHolon(ctx, "shared-cache")
+	if holon.Layer != "L1" {
+		t.Errorf("Expected shared-cache imported in L1, got %s", holon.Layer)
+	}
+	if _, err := tools.DB.GetHolon(ctx, "adopt-cache"); err != nil {
+		t.Errorf("Expected the retitled decision to be imported: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package fpf
+
+import (
+	"os"
+	"time"
+)
+
+// MCP change notifications
+const (
+	NotifyResourceUpdated     = "notifications/resources/updated"
+	NotifyResourceListChanged = "notifications/resources/list_changed"
+	NotifyToolListChanged     = "notifications/tools/list_changed"
+)
+
+// MCP request lifecycle notifications
+const (
+	NotifyProgress  = "notifications/progress"
+	NotifyCancelled = "notifications/cancelled"
+)
+
+// Notifier receives change notifications. A resources/updated notification
+// carries the URI of the changed resource in params.
+type Notifier func(method string, params map[string]interface{})
+
+// notice is a change notification waiting for its unit of work to commit
+type notice struct {
+	method string
+	uri    string
+}
+
+// SetNotifier sends later change notifications to n. A nil Notifier drops
+// them.
+func (t *Tools) SetNotifier(n Notifier) {
+	t.notify = n
+}
+
+// resourceUpdated reports that the resource at uri changed
+func (t *Tools) resourceUpdated(uri string) {
+	for _, n := range updatedNotices(uri) {
+		t.emit(n)
+	}
+}
+
+// updatedNotices announce a change of the resource at uri. A holon's change
+// also changes its audit tree.
+func updatedNotices(uri string) []notice {
+	notices := []notice{{method: NotifyResourceUpdated, uri: uri}}
+	if kind, id, err := ParseResourceURI(uri); err == nil && kind == ResourceHolon {
+		notices = append(notices, notice{method: NotifyResourceUpdated, uri: ResourceURI(ResourceAuditTree, id)})
+	}
+	return notices
+}
+
+// resourceListChanged reports that resources were added or removed
+func (t *Tools) resourceListChanged() {
+	t.emit(notice{method: NotifyResourceListChanged})
+}
+
+// toolListChanged reports that the tools an agent can use next changed, as
+// they do with the phase
+func (t *Tools) toolListChanged() {
+	t.emit(notice{method: NotifyToolListChanged})
+}
+
+// emit sends n, or holds it until the unit of work in progress commits so a
+// rolled-back change is never announced
+func (t *Tools) emit(n notice) {
+	if t.uow != nil {
+		t.uow.notices = append(t.uow.notices, n)
+		return
+	}
+	t.send([]notice{n})
+}
+
+func (t *Tools) send(notices []notice) {
+	if t.notify == nil {
+		return
+	}
+	seen := make(map[notice]bool, len(notices))
+	for _, n := range notices {
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		var params map[string]interface{}
+		if n.uri != "" {
+			params = map[string]interface{}{"uri": n.uri}
+		}
+		t.notify(n.method, params)
+	}
+}
+
+// changeNotices are the notifications the staged file changes of uow will
+// cause once committed: the resources they project are updated, and the
+// resource list changes when a file is created or removed
+func (t *Tools) changeNotices(uow *unitOfWork) []notice {
+	var notices []notice
+	for _, c := range uow.changes {
+		for _, path := range []string{c.from, c.path} {
+			if uri := t.resourceURIForPath(path); uri != "" {
+				notices = append(notices, updatedNotices(uri)...)
+			}
+		}
+		if c.path == "" {
+			notices = append(notices, notice{method: NotifyResourceListChanged})
+		} else if _, err := os.Stat(c.path); os.IsNotExist(err) && c.from == "" {
+			notices = append(notices, notice{method: NotifyResourceListChanged})
+		}
+	}
+	return notices
+}
+
+// resourceURIForPath is the URI of the resource a projection file holds, or
+// "" for files that are not resources
+func (t *Tools) resourceURIForPath(path string) string {
+	switch {
+	case path == "":
+		return ""
+	case path == t.contextFile():
+		return ResourceURI(ResourceContext, "")
+	}
+	if id := extractHolonIDFromPath(path); id != "" {
+		return ResourceURI(ResourceHolon, id)
+	}
+	if id := extractEvidenceIDFromPath(path); id != "" {
+		return ResourceURI(ResourceEvidence, id)
+	}
+	if id := extractDecisionIDFromPath(path); id != "" {
+		return ResourceURI(ResourceDecision, id)
+	}
+	return ""
+}
+
+// NotifyExpiringWaivers reports the evidence of every waiver lapsing within
+// the given time, and the holon it supports, as updated. Waivers already in
+// notified are skipped; the ones reported are added to it.
+func (t *Tools) NotifyExpiringWaivers(within time.Duration, notified map[string]bool) error {
+	if t.DB == nil {
+		return nil
+	}
+	ctx := t.callContext()
+	waivers, err := t.DB.GetAllActiveWaivers(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(within)
+	for _, w := range waivers {
+		if notified[w.ID] || w.WaivedUntil.After(deadline) {
+			continue
+		}
+		notified[w.ID] = true
+		t.resourceUpdated(ResourceURI(ResourceEvidence, w.EvidenceID))
+		if e, err := t.DB.GetEvidenceByID(ctx, w.EvidenceID); err == nil {
+			t.resourceUpdated(ResourceURI(ResourceHolon, e.HolonID))
+		}
+	}
+	return nil
+}
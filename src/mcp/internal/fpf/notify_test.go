@@ -0,0 +1,152 @@
+package fpf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordNotices collects the notifications tools sends, as "method uri"
+func recordNotices(tools *Tools) *[]string {
+	var got []string
+	tools.SetNotifier(func(method string, params map[string]interface{}) {
+		uri, _ := params["uri"].(string)
+		got = append(got, strings.TrimSpace(method+" "+uri))
+	})
+	return &got
+}
+
+func expectNotices(t *testing.T, got *[]string, want ...string) {
+	t.Helper()
+	if strings.Join(*got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected notifications:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(*got, "\n"))
+	}
+	*got = nil
+}
+
+func TestNotify_HolonLifecycle(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	got := recordNotices(tools)
+
+	if _, err := tools.ProposeHypothesis("Cache Layer", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	expectNotices(t, got,
+		NotifyResourceUpdated+" quint://holon/cache-layer",
+		NotifyResourceUpdated+" quint://audit-tree/cache-layer",
+		NotifyResourceListChanged,
+	)
+
+	if err := tools.EnterPhase("quint_propose", PhaseAbduction, RoleAbductor, tools.phaseAnchor(PhaseAbduction, "")); err != nil {
+		t.Fatalf("EnterPhase failed: %v", err)
+	}
+	expectNotices(t, got, NotifyToolListChanged)
+
+	if _, err := tools.VerifyHypothesis("cache-layer", `{"check":"ok"}`, "PASS", -1); err != nil {
+		t.Fatalf("VerifyHypothesis failed: %v", err)
+	}
+	var evidenceURI string
+	for _, n := range *got {
+		if strings.Contains(n, "quint://evidence/") {
+			evidenceURI = strings.TrimPrefix(n, NotifyResourceUpdated+" ")
+		}
+	}
+	if evidenceURI == "" {
+		t.Fatalf("Expected the verification evidence to be announced, got %v", *got)
+	}
+	expectNotices(t, got,
+		NotifyResourceUpdated+" quint://holon/cache-layer",
+		NotifyResourceUpdated+" quint://audit-tree/cache-layer",
+		NotifyResourceUpdated+" "+evidenceURI,
+		NotifyResourceListChanged,
+	)
+}
+
+func TestNotify_RolledBackWorkIsSilent(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+	got := recordNotices(tools)
+
+	err := tools.atomically(func(uow *unitOfWork) error {
+		if err := uow.WriteWithHash(filepath.Join(tempDir, ".quint", "knowledge", "L0", "ghost.md"), Frontmatter{"kind": "system"}, "Ghost"); err != nil {
+			return err
+		}
+		tools.resourceUpdated(ResourceURI(ResourceHolon, "ghost"))
+		return errors.New("abort")
+	})
+	if err == nil {
+		t.Fatal("Expected the unit of work to fail")
+	}
+	expectNotices(t, got)
+}
+
+func TestNotify_TamperingAndContext(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+	if _, err := tools.ProposeHypothesis("Cache Layer", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	got := recordNotices(tools)
+
+	path := filepath.Join(tempDir, ".quint", "knowledge", "L0", "cache-layer.md")
+	data, _ := os.ReadFile(path)
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), "Content", "Edited", 1)), 0644); err != nil {
+		t.Fatalf("Failed to tamper: %v", err)
+	}
+	if _, event, err := tools.ReadWithValidation(path); err != nil || event == nil {
+		t.Fatalf("Expected tampering to be detected (err %v)", err)
+	}
+	expectNotices(t, got,
+		NotifyResourceUpdated+" quint://holon/cache-layer",
+		NotifyResourceUpdated+" quint://audit-tree/cache-layer",
+	)
+
+	if _, err := tools.RecordContext("Cache: A fast store.", "1. Reads are idempotent."); err != nil {
+		t.Fatalf("RecordContext failed: %v", err)
+	}
+	if _, err := tools.RecordContext("Cache: A faster store.", "1. Reads are idempotent."); err != nil {
+		t.Fatalf("RecordContext failed: %v", err)
+	}
+	expectNotices(t, got,
+		NotifyResourceListChanged,
+		NotifyResourceUpdated+" quint://context",
+		NotifyResourceUpdated+" quint://context",
+	)
+}
+
+func TestNotifyExpiringWaivers(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	if _, err := tools.ProposeHypothesis("Cache Layer", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	if _, err := tools.ManageEvidence(PhaseDeduction, "add", "cache-layer", "internal", "Load test passed", "pass", "L1", "bench", "2020-01-01"); err != nil {
+		t.Fatalf("ManageEvidence failed: %v", err)
+	}
+	evidence, err := tools.DB.ListAllEvidence(t.Context())
+	if err != nil || len(evidence) != 1 {
+		t.Fatalf("Expected one evidence record, got %d (%v)", len(evidence), err)
+	}
+	id := evidence[0].ID
+	if _, err := tools.createWaiver(id, time.Now().Add(12*time.Hour).Format(time.RFC3339), "Re-run pending"); err != nil {
+		t.Fatalf("createWaiver failed: %v", err)
+	}
+	got := recordNotices(tools)
+
+	notified := make(map[string]bool)
+	if err := tools.NotifyExpiringWaivers(time.Hour, notified); err != nil {
+		t.Fatalf("NotifyExpiringWaivers failed: %v", err)
+	}
+	expectNotices(t, got)
+
+	for i := 0; i < 2; i++ {
+		if err := tools.NotifyExpiringWaivers(24*time.Hour, notified); err != nil {
+			t.Fatalf("NotifyExpiringWaivers failed: %v", err)
+		}
+	}
+	expectNotices(t, got,
+		NotifyResourceUpdated+" quint://evidence/"+id,
+		NotifyResourceUpdated+" quint://holon/cache-layer",
+		NotifyResourceUpdated+" quint://audit-tree/cache-layer",
+	)
+}
@@ -1,10 +1,8 @@
 package fpf
 
 import (
-	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 )
 
 type PreconditionError struct {
@@ -29,8 +27,8 @@ func (t *Tools) CheckPreconditions(toolName string, args map[string]string) erro
 		return t.checkAuditPreconditions(args)
 	case "quint_decide":
 		return t.checkDecidePreconditions(args)
-	case "quint_calculate_r":
-		return t.checkCalculateRPreconditions(args)
+	case "quint_calculate_r", "quint_explain_r":
+		return t.checkCalculateRPreconditions(toolName, args)
 	case "quint_audit_tree":
 		return t.checkAuditTreePreconditions(args)
 	default:
@@ -73,7 +71,7 @@ func (t *Tools) checkVerifyPreconditions(args map[string]string) error {
 		}
 	}
 
-	l0Path := filepath.Join(t.GetFPFDir(), "knowledge", "L0", hypoID+".md")
+	l0Path := t.holonPath("L0", hypoID)
 	if _, err := os.Stat(l0Path); os.IsNotExist(err) {
 		return &PreconditionError{
 			Tool:       "quint_verify",
@@ -104,7 +102,7 @@ func (t *Tools) checkTestPreconditions(args map[string]string) error {
 		}
 	}
 
-	l0Path := filepath.Join(t.GetFPFDir(), "knowledge", "L0", hypoID+".md")
+	l0Path := t.holonPath("L0", hypoID)
 	if _, err := os.Stat(l0Path); err == nil {
 		return &PreconditionError{
 			Tool:       "quint_test",
@@ -113,8 +111,8 @@ func (t *Tools) checkTestPreconditions(args map[string]string) error {
 		}
 	}
 
-	l1Path := filepath.Join(t.GetFPFDir(), "knowledge", "L1", hypoID+".md")
-	l2Path := filepath.Join(t.GetFPFDir(), "knowledge", "L2", hypoID+".md")
+	l1Path := t.holonPath("L1", hypoID)
+	l2Path := t.holonPath("L2", hypoID)
 	l1Exists := false
 	l2Exists := false
 
@@ -127,7 +125,7 @@ func (t *Tools) checkTestPreconditions(args map[string]string) error {
 
 	if !l1Exists && !l2Exists {
 		if t.DB != nil {
-			ctx := context.Background()
+			ctx := t.callContext()
 			holon, err := t.DB.GetHolon(ctx, hypoID)
 			if err != nil || (holon.Layer != "L1" && holon.Layer != "L2") {
 				return &PreconditionError{
@@ -168,7 +166,7 @@ func (t *Tools) checkAuditPreconditions(args map[string]string) error {
 	}
 
 	if t.DB != nil {
-		ctx := context.Background()
+		ctx := t.callContext()
 		holon, err := t.DB.GetHolon(ctx, hypoID)
 		if err != nil {
 			return &PreconditionError{
@@ -208,8 +206,8 @@ func (t *Tools) checkDecidePreconditions(args map[string]string) error {
 	}
 
 	if t.DB != nil {
-		ctx := context.Background()
-		counts, _ := t.DB.CountHolonsByLayer(ctx, "default")
+		ctx := t.callContext()
+		counts, _ := t.DB.CountHolonsByLayer(ctx, t.contextID())
 
 		l2Count := int64(0)
 		for _, c := range counts {
@@ -231,10 +229,10 @@ func (t *Tools) checkDecidePreconditions(args map[string]string) error {
 	return nil
 }
 
-func (t *Tools) checkCalculateRPreconditions(args map[string]string) error {
+func (t *Tools) checkCalculateRPreconditions(tool string, args map[string]string) error {
 	if t.DB == nil {
 		return &PreconditionError{
-			Tool:       "quint_calculate_r",
+			Tool:       tool,
 			Condition:  "database not initialized",
 			Suggestion: "Run /q0-init to initialize the project first",
 		}
@@ -243,17 +241,17 @@ func (t *Tools) checkCalculateRPreconditions(args map[string]string) error {
 	holonID := args["holon_id"]
 	if holonID == "" {
 		return &PreconditionError{
-			Tool:       "quint_calculate_r",
+			Tool:       tool,
 			Condition:  "holon_id is required",
 			Suggestion: "Specify which holon to calculate R for",
 		}
 	}
 
-	ctx := context.Background()
+	ctx := t.callContext()
 	_, err := t.DB.GetHolon(ctx, holonID)
 	if err != nil {
 		return &PreconditionError{
-			Tool:       "quint_calculate_r",
+			Tool:       tool,
 			Condition:  fmt.Sprintf("holon '%s' not found", holonID),
 			Suggestion: "Ensure the holon exists in the database",
 		}
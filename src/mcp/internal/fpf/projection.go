@@ -1,15 +1,15 @@
 package fpf
 
 import (
-	"context"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
-
-	"github.com/m0n0x41d/quint-code/db"
 )
 
 type TamperingEvent struct {
@@ -19,50 +19,54 @@ type TamperingEvent struct {
 	Regenerated  bool
 }
 
+// ComputeContentHash hashes a body alone, as content_hash did before it
+// covered the frontmatter. Files sealed that way validate once and are then
+// re-sealed with ComputeProjectionHash.
 func ComputeContentHash(body string) string {
 	hash := sha256.Sum256([]byte(body))
 	return hex.EncodeToString(hash[:16])
 }
 
-func parseFrontmatter(content string) (frontmatter string, body string, ok bool) {
-	if !strings.HasPrefix(content, "---\n") {
-		return "", content, false
-	}
-
-	endIdx := strings.Index(content[4:], "\n---\n")
-	if endIdx == -1 {
-		return "", content, false
-	}
-
-	frontmatter = content[4 : 4+endIdx]
-	body = content[4+endIdx+5:]
-	return frontmatter, body, true
+// ComputeProjectionHash hashes the canonical frontmatter together with the
+// body, so editing a field such as verdict or valid_until is detected.
+// Formatting, key order and comments do not change the hash.
+func ComputeProjectionHash(fields Frontmatter, body string) string {
+	hash := sha256.Sum256([]byte(EncodeFrontmatter(withoutHash(fields)) + "---\n" + body))
+	return hex.EncodeToString(hash[:16])
 }
 
-func extractHashFromFrontmatter(frontmatter string) string {
-	re := regexp.MustCompile(`(?m)^content_hash:\s*([a-f0-9]+)\s*$`)
-	matches := re.FindStringSubmatch(frontmatter)
-	if len(matches) >= 2 {
-		return matches[1]
+func withoutHash(fields Frontmatter) Frontmatter {
+	rest := make(Frontmatter, len(fields))
+	for k, v := range fields {
+		if k != "content_hash" {
+			rest[k] = v
+		}
 	}
-	return ""
+	return rest
 }
 
-func WriteWithHash(path string, frontmatterFields map[string]string, body string) error {
-	hash := ComputeContentHash(body)
+func WriteWithHash(path string, fields Frontmatter, body string) error {
+	return os.WriteFile(path, renderWithHash(fields, body), 0644)
+}
 
+// renderWithHash renders a projection file: the frontmatter fields in sorted
+// order and their content_hash, followed by the body. The same holon always
+// renders to the same bytes and merges cleanly.
+func renderWithHash(fields Frontmatter, body string) []byte {
 	var fm strings.Builder
 	fm.WriteString("---\n")
-	for k, v := range frontmatterFields {
-		fm.WriteString(fmt.Sprintf("%s: %s\n", k, v))
-	}
-	fm.WriteString(fmt.Sprintf("content_hash: %s\n", hash))
+	fm.WriteString(EncodeFrontmatter(withoutHash(fields)))
+	fm.WriteString(EncodeFrontmatter(Frontmatter{"content_hash": ComputeProjectionHash(fields, body)}))
 	fm.WriteString("---\n")
 
-	content := fm.String() + body
-	return os.WriteFile(path, []byte(content), 0644)
+	return []byte(fm.String() + body)
 }
 
+// ValidateFile checks a projection file against its content_hash. A file
+// sealed with the body-only hash of ComputeContentHash is not tampered, but
+// its expectedHash differs from actualHash; a file without a content_hash has
+// an empty expectedHash. Neither vouches for its frontmatter, so
+// ReadWithValidation checks both against the DB.
 func ValidateFile(path string) (content string, tampered bool, expectedHash string, actualHash string, err error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -70,18 +74,23 @@ func ValidateFile(path string) (content string, tampered bool, expectedHash stri
 	}
 
 	content = string(data)
-	frontmatter, body, hasFM := parseFrontmatter(content)
+	header, body, hasFM := splitFrontmatter(content)
 	if !hasFM {
 		return content, false, "", "", nil
 	}
 
-	expectedHash = extractHashFromFrontmatter(frontmatter)
+	fields, err := DecodeFrontmatter(header)
+	if err != nil {
+		// Frontmatter that no longer parses was edited after it was sealed
+		return content, strings.Contains(header, "content_hash"), "", "", nil
+	}
+	expectedHash = fields.String("content_hash")
+	actualHash = ComputeProjectionHash(fields, body)
 	if expectedHash == "" {
-		return content, false, "", "", nil
+		return content, false, "", actualHash, nil
 	}
 
-	actualHash = ComputeContentHash(body)
-	if expectedHash != actualHash {
+	if expectedHash != actualHash && expectedHash != ComputeContentHash(body) {
 		return content, true, expectedHash, actualHash, nil
 	}
 
@@ -94,6 +103,12 @@ func (t *Tools) ReadWithValidation(path string) (string, *TamperingEvent, error)
 		return "", nil, err
 	}
 
+	reason := "Content hash mismatch detected"
+	if !tampered && (expectedHash == "" || expectedHash != actualHash) {
+		if tampered, reason, err = t.checkUnsealed(path, content, expectedHash); err != nil {
+			return "", nil, err
+		}
+	}
 	if !tampered {
 		return content, nil, nil
 	}
@@ -108,7 +123,7 @@ func (t *Tools) ReadWithValidation(path string) (string, *TamperingEvent, error)
 	t.AuditLog("projection_validate", "tampering_detected", "system", path, "ALERT", map[string]string{
 		"expected_hash": expectedHash,
 		"actual_hash":   actualHash,
-	}, "Content hash mismatch detected")
+	}, reason)
 
 	if t.DB != nil {
 		regenerated, regErr := t.regenerateFromDB(path)
@@ -118,66 +133,171 @@ func (t *Tools) ReadWithValidation(path string) (string, *TamperingEvent, error)
 			event.Regenerated = true
 			t.AuditLog("projection_validate", "file_regenerated", "system", path, "SUCCESS", nil, "File regenerated from database")
 			newContent, _, _, _, _ := ValidateFile(path)
-			return newContent, event, nil
+			return newContent, event, nil // Regenerating announced the change
 		}
 	}
 
+	if uri := t.resourceURIForPath(path); uri != "" {
+		t.resourceUpdated(uri)
+	}
 	return content, event, nil
 }
 
-func (t *Tools) regenerateFromDB(path string) (bool, error) {
+// checkUnsealed decides whether a file whose content_hash does not cover its
+// frontmatter was tampered with. A file without a content_hash is tampered
+// when the DB has its row. A file with the legacy body-only hash is tampered
+// when its frontmatter differs from what its row projects; otherwise it is
+// re-sealed so later edits are caught by the hash. Files without a row are
+// left as they are.
+func (t *Tools) checkUnsealed(path, content, expectedHash string) (bool, string, error) {
 	if t.DB == nil {
-		return false, fmt.Errorf("DB not initialized")
+		return false, "", nil
 	}
-
-	holonID := extractHolonIDFromPath(path)
-	if holonID == "" {
-		return false, nil
+	projected, _, ok, err := t.projectionFromDB(path)
+	if err != nil || !ok {
+		return false, "", err
+	}
+	if expectedHash == "" {
+		return true, "Projection file has no content hash", nil
 	}
 
-	ctx := context.Background()
-	holon, err := t.DB.GetHolon(ctx, holonID)
+	header, body, _ := splitFrontmatter(content)
+	fields, err := DecodeFrontmatter(header)
 	if err != nil {
-		return false, err
+		return true, "Projection file frontmatter does not parse", nil
+	}
+	if EncodeFrontmatter(withoutHash(fields)) != EncodeFrontmatter(projected) {
+		return true, "Frontmatter under a body-only content hash differs from the database", nil
 	}
 
-	layer := extractLayerFromPath(path)
-	if layer == "" || layer != holon.Layer {
-		return false, nil
+	if err := t.atomically(func(uow *unitOfWork) error {
+		return uow.WriteWithHash(path, fields, body)
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to re-seal %s: %v\n", path, err)
+		return false, "", nil
 	}
+	t.AuditLog("projection_validate", "file_resealed", "system", path, "SUCCESS", map[string]string{
+		"legacy_hash": expectedHash,
+		"new_hash":    ComputeProjectionHash(fields, body),
+	}, "Body-only content hash replaced")
+	return false, "", nil
+}
 
-	body := fmt.Sprintf("\n# Hypothesis: %s\n\n%s", holon.Title, holon.Content)
+// ValidateProjection checks every holon, evidence and DRR file of the current
+// context against its content_hash. Tampered files are regenerated from the
+// DB where it has them.
+func (t *Tools) ValidateProjection() ([]TamperingEvent, error) {
+	root := t.projectionDir(t.contextID())
+	dirs := []string{filepath.Join(root, "evidence"), filepath.Join(root, "decisions")}
+	for _, layer := range projectedLayers {
+		dirs = append(dirs, filepath.Join(root, "knowledge", layer))
+	}
 
-	fields := map[string]string{
-		"scope": holon.Scope.String,
-		"kind":  holon.Kind.String,
+	var events []TamperingEvent
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return events, err
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() || !strings.HasSuffix(name, ".md") || strings.HasPrefix(name, ".") {
+				continue
+			}
+			_, event, err := t.ReadWithValidation(filepath.Join(dir, name))
+			if err != nil {
+				return events, err
+			}
+			if event != nil {
+				events = append(events, *event)
+			}
+		}
 	}
+	return events, nil
+}
 
-	if err := WriteWithHash(path, fields, body); err != nil {
+// regenerateFromDB rewrites a holon, evidence or DRR file from the row it
+// projects. Files whose row is missing, or a holon file outside the holon's
+// layer, are left alone.
+func (t *Tools) regenerateFromDB(path string) (bool, error) {
+	_, write, ok, err := t.projectionFromDB(path)
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := t.atomically(write); err != nil {
 		return false, err
 	}
-
 	return true, nil
 }
 
+// projectionFromDB finds the row a holon, evidence or DRR file projects and
+// returns the frontmatter it renders to and the write that regenerates the
+// file. ok is false when the row is missing, or for a holon file outside
+// the holon's layer.
+func (t *Tools) projectionFromDB(path string) (fields Frontmatter, write func(uow *unitOfWork) error, ok bool, err error) {
+	if t.DB == nil {
+		return nil, nil, false, fmt.Errorf("DB not initialized")
+	}
+	ctx := t.callContext()
+
+	if holonID := extractHolonIDFromPath(path); holonID != "" {
+		holon, err := t.DB.GetHolon(ctx, holonID)
+		if err != nil || extractLayerFromPath(path) != holon.Layer {
+			return nil, nil, false, ignoreNoRows(err)
+		}
+		if fields, err = t.holonFields(holon); err != nil {
+			return nil, nil, false, err
+		}
+		return fields, t.writeHolonFiles(holon, nil), true, nil
+	}
+	if evidenceID := extractEvidenceIDFromPath(path); evidenceID != "" {
+		e, err := t.DB.GetEvidenceByID(ctx, evidenceID)
+		if err != nil {
+			return nil, nil, false, ignoreNoRows(err)
+		}
+		return evidenceFields(e), t.writeEvidenceFile(e), true, nil
+	}
+	if decisionID := extractDecisionIDFromPath(path); decisionID != "" {
+		holon, err := t.DB.GetHolon(ctx, decisionID)
+		if err != nil || holon.Type != "DRR" {
+			return nil, nil, false, ignoreNoRows(err)
+		}
+		return decisionFields(holon), t.writeDecisionFile(holon, path), true, nil
+	}
+	return nil, nil, false, nil
+}
+
+// ignoreNoRows drops the error of a lookup that found nothing
+func ignoreNoRows(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+// extractHolonIDFromPath is the ID of the holon a knowledge file holds,
+// namespaced to the context whose projection the file is in
 func extractHolonIDFromPath(path string) string {
 	re := regexp.MustCompile(`/knowledge/L[012]/([^/]+)\.md$`)
 	matches := re.FindStringSubmatch(path)
 	if len(matches) >= 2 {
-		return matches[1]
+		return qualifyID(pathContext(path), matches[1])
 	}
 
 	re = regexp.MustCompile(`/knowledge/invalid/([^/]+)\.md$`)
 	matches = re.FindStringSubmatch(path)
 	if len(matches) >= 2 {
-		return matches[1]
+		return qualifyID(pathContext(path), matches[1])
 	}
 
 	return ""
 }
 
-func extractLayerFromPath(path string) string {
-	re := regexp.MustCompile(`/knowledge/(L[012]|invalid)/`)
+func extractEvidenceIDFromPath(path string) string {
+	re := regexp.MustCompile(`/evidence/([^/]+\.md)$`)
 	matches := re.FindStringSubmatch(path)
 	if len(matches) >= 2 {
 		return matches[1]
@@ -185,25 +305,21 @@ func extractLayerFromPath(path string) string {
 	return ""
 }
 
-func RegenerateHolonFile(store *db.Store, holonID, fpfDir string) error {
-	if store == nil {
-		return fmt.Errorf("DB not initialized")
-	}
-
-	ctx := context.Background()
-	holon, err := store.GetHolon(ctx, holonID)
-	if err != nil {
-		return fmt.Errorf("holon not found: %w", err)
+// extractDecisionIDFromPath is the DRR holon ID in DRR-<date>-<slug>.md
+func extractDecisionIDFromPath(path string) string {
+	re := regexp.MustCompile(`/decisions/DRR-\d{4}-\d{2}-\d{2}-([^/]+)\.md$`)
+	matches := re.FindStringSubmatch(path)
+	if len(matches) >= 2 {
+		return qualifyID(pathContext(path), matches[1])
 	}
+	return ""
+}
 
-	path := fmt.Sprintf("%s/knowledge/%s/%s.md", fpfDir, holon.Layer, holonID)
-
-	body := fmt.Sprintf("\n# Hypothesis: %s\n\n%s", holon.Title, holon.Content)
-
-	fields := map[string]string{
-		"scope": holon.Scope.String,
-		"kind":  holon.Kind.String,
+func extractLayerFromPath(path string) string {
+	re := regexp.MustCompile(`/knowledge/(L[012]|invalid)/`)
+	matches := re.FindStringSubmatch(path)
+	if len(matches) >= 2 {
+		return matches[1]
 	}
-
-	return WriteWithHash(path, fields, body)
+	return ""
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/m0n0x41d/quint-code/db"
@@ -36,7 +37,7 @@ func TestWriteWithHash(t *testing.T) {
 	tempDir := t.TempDir()
 	path := filepath.Join(tempDir, "test.md")
 
-	fields := map[string]string{
+	fields := Frontmatter{
 		"scope": "global",
 		"kind":  "system",
 	}
@@ -67,11 +68,34 @@ func TestWriteWithHash(t *testing.T) {
 	}
 }
 
+func TestWriteWithHash_SortedFields(t *testing.T) {
+	tempDir := t.TempDir()
+	fields := Frontmatter{"verdict": "pass", "type": "test", "id": "e1", "carrier_ref": "ci", "date": dateField("2025-01-01")}
+
+	first := filepath.Join(tempDir, "first.md")
+	second := filepath.Join(tempDir, "second.md")
+	for _, path := range []string{first, second} {
+		if err := WriteWithHash(path, fields, "\nbody"); err != nil {
+			t.Fatalf("WriteWithHash failed: %v", err)
+		}
+	}
+
+	a, _ := os.ReadFile(first)
+	b, _ := os.ReadFile(second)
+	if string(a) != string(b) {
+		t.Errorf("Expected identical output, got:\n%s\n---\n%s", a, b)
+	}
+	want := "---\ncarrier_ref: ci\ndate: 2025-01-01\nid: e1\ntype: test\nverdict: pass\ncontent_hash: "
+	if !strings.HasPrefix(string(a), want) {
+		t.Errorf("Expected sorted frontmatter, got:\n%s", a)
+	}
+}
+
 func TestValidateFile_Valid(t *testing.T) {
 	tempDir := t.TempDir()
 	path := filepath.Join(tempDir, "valid.md")
 
-	fields := map[string]string{"scope": "test"}
+	fields := Frontmatter{"scope": "test"}
 	body := "\n# Valid Content\n\nThis is valid."
 
 	if err := WriteWithHash(path, fields, body); err != nil {
@@ -94,7 +118,7 @@ func TestValidateFile_Tampered(t *testing.T) {
 	tempDir := t.TempDir()
 	path := filepath.Join(tempDir, "tampered.md")
 
-	fields := map[string]string{"scope": "test"}
+	fields := Frontmatter{"scope": "test"}
 	body := "\n# Original Content"
 
 	if err := WriteWithHash(path, fields, body); err != nil {
@@ -178,7 +202,7 @@ func TestReadWithValidation_Tampered(t *testing.T) {
 
 	path := filepath.Join(l0Dir, "test-hypo.md")
 	body := "\n# Hypothesis: Test\n\nOriginal content"
-	fields := map[string]string{"scope": "test", "kind": "system"}
+	fields := Frontmatter{"scope": "test", "kind": "system"}
 	if err := WriteWithHash(path, fields, body); err != nil {
 		t.Fatalf("WriteWithHash failed: %v", err)
 	}
@@ -266,3 +290,230 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestValidateFile_FrontmatterTampered(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "evidence.md")
+	fields := Frontmatter{"verdict": "fail", "valid_until": dateField("2025-01-01")}
+	if err := WriteWithHash(path, fields, "\nBenchmark regressed"); err != nil {
+		t.Fatalf("WriteWithHash failed: %v", err)
+	}
+	original, _ := os.ReadFile(path)
+
+	edits := map[string]string{
+		"verdict":     strings.Replace(string(original), "verdict: fail", "verdict: pass", 1),
+		"valid_until": strings.Replace(string(original), "valid_until: 2025-01-01", "valid_until: 2099-01-01", 1),
+		"added field": strings.Replace(string(original), "verdict:", "waived: yes\nverdict:", 1),
+		"broken yaml": strings.Replace(string(original), "verdict: fail", "verdict: \"fail", 1),
+		"comment":     strings.Replace(string(original), "---\n", "---\n# reviewed\n", 1),
+	}
+	for name, edited := range edits {
+		if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if _, tampered, _, _, err := ValidateFile(path); err != nil || !tampered {
+			t.Errorf("%s: expected tampering to be detected (err %v)", name, err)
+		}
+	}
+
+	reordered := strings.Replace(string(original), "valid_until: 2025-01-01\nverdict: fail\n", "verdict: \"fail\"\nvalid_until: 2025-01-01\n", 1)
+	if reordered == string(original) {
+		t.Fatalf("Unexpected file layout:\n%s", original)
+	}
+	if err := os.WriteFile(path, []byte(reordered), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, tampered, _, _, err := ValidateFile(path); err != nil || tampered {
+		t.Errorf("Expected key order and quoting not to count as tampering (err %v)", err)
+	}
+}
+
+func TestValidateFile_BodyOnlyHash(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "legacy.md")
+	body := "\n# Hypothesis: Legacy\n\nWritten before the hash covered frontmatter"
+	content := "---\nkind: system\nscope: global\ncontent_hash: " + ComputeContentHash(body) + "\n---\n" + body
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if _, tampered, _, _, err := ValidateFile(path); err != nil || tampered {
+		t.Errorf("Expected a body-only hash to still validate (err %v)", err)
+	}
+}
+
+func TestValidateProjection_RegeneratesEvidenceAndDecisions(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+
+	if _, err := tools.ProposeHypothesis("Cache Layer", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	if _, err := tools.ManageEvidence(PhaseDeduction, "add", "cache-layer", "internal", "Load test failed", "fail", "L1", "bench", "2030-01-01"); err != nil {
+		t.Fatalf("ManageEvidence failed: %v", err)
+	}
+	drrPath, err := tools.FinalizeDecision("Adopt Cache", "cache-layer", nil, "Context", "Decision", "Rationale", "Consequences", "", "")
+	if err != nil {
+		t.Fatalf("FinalizeDecision failed: %v", err)
+	}
+	evidence, _ := filepath.Glob(filepath.Join(tempDir, ".quint", "evidence", "*.md"))
+	if len(evidence) != 1 {
+		t.Fatalf("Expected one evidence file, got %v", evidence)
+	}
+
+	tamper := func(path, old, replacement string) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if !strings.Contains(string(content), old) {
+			t.Fatalf("Expected %s to contain %q:\n%s", path, old, content)
+		}
+		if err := os.WriteFile(path, []byte(strings.Replace(string(content), old, replacement, 1)), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	tamper(evidence[0], "verdict: fail", "verdict: pass")
+	tamper(drrPath, "winner_id: cache-layer", "winner_id: something-else")
+
+	events, err := tools.ValidateProjection()
+	if err != nil {
+		t.Fatalf("ValidateProjection failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 tampering events, got %+v", events)
+	}
+	for _, e := range events {
+		if !e.Regenerated {
+			t.Errorf("Expected %s to be regenerated from the DB", e.FilePath)
+		}
+		if _, tampered, _, _, err := ValidateFile(e.FilePath); err != nil || tampered {
+			t.Errorf("Expected regenerated %s to validate (err %v)", e.FilePath, err)
+		}
+	}
+
+	f, err := readProjectionFile(evidence[0])
+	if err != nil {
+		t.Fatalf("readProjectionFile failed: %v", err)
+	}
+	if got := f.fields.String("verdict"); got != "fail" {
+		t.Errorf("Expected the evidence verdict restored to fail, got %q", got)
+	}
+	d, err := readProjectionFile(drrPath)
+	if err != nil {
+		t.Fatalf("readProjectionFile failed: %v", err)
+	}
+	if got := d.fields.String("winner_id"); got != "cache-layer" {
+		t.Errorf("Expected the DRR winner restored to cache-layer, got %q", got)
+	}
+
+	report, err := tools.Actualize()
+	if err != nil {
+		t.Fatalf("Actualize failed: %v", err)
+	}
+	if strings.Contains(report, "INTEGRITY") {
+		t.Errorf("Expected no integrity findings after regeneration, got:\n%s", report)
+	}
+}
+
+func TestValidateProjection_ResealsBodyOnlyHash(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+	if _, err := tools.ProposeHypothesis("Cache Layer", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	path := filepath.Join(tempDir, ".quint", "knowledge", "L0", "cache-layer.md")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	header, body, _ := splitFrontmatter(string(data))
+	fields, err := DecodeFrontmatter(header)
+	if err != nil {
+		t.Fatalf("DecodeFrontmatter failed: %v", err)
+	}
+	legacy := strings.Replace(string(data), fields.String("content_hash"), ComputeContentHash(body), 1)
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if events, err := tools.ValidateProjection(); err != nil || len(events) != 0 {
+		t.Fatalf("Expected the legacy hash to validate, got %v (err %v)", events, err)
+	}
+	if _, _, expected, actual, _ := ValidateFile(path); expected != actual || expected != ComputeProjectionHash(fields, body) {
+		t.Errorf("Expected the file to be re-sealed, content_hash %s", expected)
+	}
+
+	resealed, _ := os.ReadFile(path)
+	edited := strings.Replace(string(resealed), "kind: system", "kind: episteme", 1)
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, tampered, _, _, _ := ValidateFile(path); !tampered {
+		t.Error("Expected a frontmatter edit after re-sealing to be detected")
+	}
+}
+
+func TestValidateProjection_UnsealedFrontmatterMustMatchDB(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+	if _, err := tools.ProposeHypothesis("Cache Layer", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	if _, err := tools.ManageEvidence(PhaseDeduction, "add", "cache-layer", "internal", "Load test failed", "fail", "L1", "bench", "2030-01-01"); err != nil {
+		t.Fatalf("ManageEvidence failed: %v", err)
+	}
+	evidence, _ := filepath.Glob(filepath.Join(tempDir, ".quint", "evidence", "*.md"))
+	if len(evidence) != 1 {
+		t.Fatalf("Expected one evidence file, got %v", evidence)
+	}
+	holon := filepath.Join(tempDir, ".quint", "knowledge", "invalid", "cache-layer.md") // The failed test refuted it
+
+	// unseal rewrites a file with its frontmatter edited and its hash
+	// replaced, or dropped when hash is empty
+	unseal := func(path, old, replacement string, hash func(body string) string) {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read file: %v", err)
+		}
+		header, body, _ := splitFrontmatter(string(data))
+		fields, err := DecodeFrontmatter(header)
+		if err != nil {
+			t.Fatalf("DecodeFrontmatter failed: %v", err)
+		}
+		sealed := "content_hash: " + fields.String("content_hash") + "\n"
+		edited := strings.Replace(string(data), sealed, "", 1)
+		if hash != nil {
+			edited = strings.Replace(string(data), sealed, "content_hash: "+hash(body)+"\n", 1)
+		}
+		if edited == string(data) || !strings.Contains(edited, old) {
+			t.Fatalf("Unexpected layout of %s:\n%s", path, data)
+		}
+		if err := os.WriteFile(path, []byte(strings.Replace(edited, old, replacement, 1)), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	unseal(evidence[0], "verdict: fail", "verdict: pass", ComputeContentHash)
+	unseal(holon, "kind: system", "kind: episteme", nil)
+
+	events, err := tools.ValidateProjection()
+	if err != nil {
+		t.Fatalf("ValidateProjection failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected both forged files to be reported, got %+v", events)
+	}
+	for _, e := range events {
+		if !e.Regenerated {
+			t.Errorf("Expected %s to be regenerated from the DB", e.FilePath)
+		}
+	}
+	for path, want := range map[string]string{evidence[0]: "verdict: fail", holon: "kind: system"} {
+		data, _ := os.ReadFile(path)
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %s to be restored, got:\n%s", path, data)
+		}
+		if _, tampered, expected, actual, _ := ValidateFile(path); tampered || expected != actual {
+			t.Errorf("Expected %s to be sealed with the full hash", path)
+		}
+	}
+}
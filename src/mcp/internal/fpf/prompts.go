@@ -0,0 +1,140 @@
+package fpf
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// PromptArgument is an argument a prompt accepts
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// Prompt is a slash command served over MCP prompts/list and prompts/get
+type Prompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+	body        string
+}
+
+// LoadPrompts parses every markdown command at the root of fsys, sorted by
+// name. The prompt name is the file name without .md.
+func LoadPrompts(fsys fs.FS) ([]Prompt, error) {
+	names, err := fs.Glob(fsys, "*.md")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	prompts := make([]Prompt, 0, len(names))
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		p, err := ParsePrompt(strings.TrimSuffix(path.Base(name), ".md"), string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		prompts = append(prompts, p)
+	}
+	return prompts, nil
+}
+
+// ParsePrompt reads a command file. Its frontmatter may declare arguments
+// as a list of "name: description" entries; a name ending in "?" is
+// optional. Without a description the first heading is used.
+func ParsePrompt(name, content string) (Prompt, error) {
+	p := Prompt{Name: name, body: content}
+
+	header, body, ok := splitFrontmatter(content)
+	if ok {
+		fields, err := DecodeFrontmatter(header)
+		if err != nil {
+			return Prompt{}, err
+		}
+		p.body = strings.TrimLeft(body, "\r\n")
+		p.Description = fields.String("description")
+
+		seen := make(map[string]bool)
+		for _, entry := range fields.List("arguments") {
+			argName, description, _ := strings.Cut(entry, ":")
+			argName = strings.TrimSpace(argName)
+			arg := PromptArgument{
+				Name:        strings.TrimSuffix(argName, "?"),
+				Description: strings.TrimSpace(description),
+				Required:    !strings.HasSuffix(argName, "?"),
+			}
+			if arg.Name == "" || seen[arg.Name] {
+				return Prompt{}, fmt.Errorf("invalid or duplicate argument %q", entry)
+			}
+			seen[arg.Name] = true
+			p.Arguments = append(p.Arguments, arg)
+		}
+	}
+
+	if p.Description == "" {
+		p.Description = firstHeading(p.body)
+	}
+	return p, nil
+}
+
+// Render fills in the prompt with args. $ARGUMENTS is replaced by the given
+// values in declared order and $1..$n by the value of the n-th declared
+// argument; a prompt without placeholders gets the values appended in an
+// Arguments section.
+func (p Prompt) Render(args map[string]string) (string, error) {
+	declared := make(map[string]bool, len(p.Arguments))
+	positional := make([]string, len(p.Arguments))
+	var values []string
+	var section strings.Builder
+	for i, arg := range p.Arguments {
+		declared[arg.Name] = true
+		value := strings.TrimSpace(args[arg.Name])
+		if value == "" {
+			if arg.Required {
+				return "", fmt.Errorf("prompt %s requires argument %q", p.Name, arg.Name)
+			}
+			continue
+		}
+		positional[i] = value
+		values = append(values, value)
+		section.WriteString(fmt.Sprintf("- **%s**: %s\n", arg.Name, value))
+	}
+	for name := range args {
+		if !declared[name] {
+			return "", fmt.Errorf("prompt %s has no argument %q", p.Name, name)
+		}
+	}
+
+	if !strings.Contains(p.body, "$ARGUMENTS") && !strings.Contains(p.body, "$1") {
+		if section.Len() == 0 {
+			return p.body, nil
+		}
+		return strings.TrimRight(p.body, "\n") + "\n\n## Arguments\n\n" + section.String(), nil
+	}
+
+	text := strings.ReplaceAll(p.body, "$ARGUMENTS", strings.Join(values, " "))
+	for i := len(positional); i >= 1; i-- { // $10 before $1
+		text = strings.ReplaceAll(text, fmt.Sprintf("$%d", i), positional[i-1])
+	}
+	return text, nil
+}
+
+// firstHeading is the text of the first markdown heading in content
+func firstHeading(content string) string {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "#") {
+			return strings.TrimSpace(strings.TrimLeft(line, "# "))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+package fpf
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParsePrompt(t *testing.T) {
+	p, err := ParsePrompt("q2-verify", "---\n"+
+		"description: \"Verify Logic (Deduction)\"\n"+
+		"required_tools:\n"+
+		"  - quint_verify\n"+
+		"arguments:\n"+
+		"  - \"hypothesis_id: The hypothesis to verify\"\n"+
+		"  - \"note?: Anything the Deductor should know\"\n"+
+		"---\n\n# Phase 2\n\nVerify it.\n")
+	if err != nil {
+		t.Fatalf("ParsePrompt failed: %v", err)
+	}
+	if p.Description != "Verify Logic (Deduction)" {
+		t.Errorf("Expected the frontmatter description, got %q", p.Description)
+	}
+	want := []PromptArgument{
+		{Name: "hypothesis_id", Description: "The hypothesis to verify", Required: true},
+		{Name: "note", Description: "Anything the Deductor should know"},
+	}
+	if !reflect.DeepEqual(p.Arguments, want) {
+		t.Errorf("Expected arguments %+v, got %+v", want, p.Arguments)
+	}
+
+	p, err = ParsePrompt("q-decay", "# q-decay: Evidence Freshness\n\nBody\n")
+	if err != nil {
+		t.Fatalf("ParsePrompt failed: %v", err)
+	}
+	if p.Description != "q-decay: Evidence Freshness" || len(p.Arguments) != 0 {
+		t.Errorf("Expected the first heading as description and no arguments, got %+v", p)
+	}
+
+	for _, src := range []string{
+		"---\narguments:\n  - \"a: one\"\n  - \"a?: again\"\n---\nBody",
+		"---\narguments:\n  - \"?: nameless\"\n---\nBody",
+		"---\ndescription: \"unterminated\n---\nBody",
+	} {
+		if _, err := ParsePrompt("bad", src); err == nil {
+			t.Errorf("Expected an error for %q", src)
+		}
+	}
+}
+
+func TestPrompt_Render(t *testing.T) {
+	p := Prompt{
+		Name: "q1-add",
+		Arguments: []PromptArgument{
+			{Name: "idea", Required: true},
+			{Name: "scope"},
+		},
+		body: "# Add\n\nFormalize the idea.\n",
+	}
+
+	text, err := p.Render(map[string]string{"idea": "Use Redis"})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if want := "# Add\n\nFormalize the idea.\n\n## Arguments\n\n- **idea**: Use Redis\n"; text != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, text)
+	}
+	if _, err := p.Render(nil); err == nil {
+		t.Error("Expected a missing required argument to be rejected")
+	}
+	if _, err := p.Render(map[string]string{"idea": "x", "other": "y"}); err == nil {
+		t.Error("Expected an undeclared argument to be rejected")
+	}
+
+	p.body = "Idea: $1; scope: $2; all: $ARGUMENTS"
+	text, err = p.Render(map[string]string{"scope": "billing", "idea": "Use Redis"})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if want := "Idea: Use Redis; scope: billing; all: Use Redis billing"; text != want {
+		t.Errorf("Expected %q, got %q", want, text)
+	}
+}
+
+func TestLoadPrompts(t *testing.T) {
+	fsys := fstest.MapFS{
+		"q1-add.md":    {Data: []byte("---\ndescription: \"Add\"\narguments:\n  - \"idea: The idea\"\n---\nBody\n")},
+		"q0-init.md":   {Data: []byte("---\ndescription: \"Init\"\n---\nBody\n")},
+		"README.txt":   {Data: []byte("not a command")},
+		"nested/x.md":  {Data: []byte("# Nested")},
+		"q-status.md":  {Data: []byte("# Status\n")},
+		"q2-verify.md": {Data: []byte("---\ndescription: \"Verify\"\n---\n")},
+	}
+	prompts, err := LoadPrompts(fsys)
+	if err != nil {
+		t.Fatalf("LoadPrompts failed: %v", err)
+	}
+	var names []string
+	for _, p := range prompts {
+		names = append(names, p.Name)
+	}
+	if got := strings.Join(names, ","); got != "q-status,q0-init,q1-add,q2-verify" {
+		t.Errorf("Expected the root commands sorted by name, got %s", got)
+	}
+}
@@ -0,0 +1,686 @@
+package fpf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/m0n0x41d/quint-code/assurance"
+	"github.com/m0n0x41d/quint-code/db"
+)
+
+// Reconcile sources
+const (
+	ReconcileFromMarkdown = "markdown" // Rebuild the DB from the .quint markdown
+	ReconcileFromDB       = "db"       // Rebuild the .quint markdown from the DB
+)
+
+// projectedLayers are the knowledge layers with a markdown directory
+var projectedLayers = []string{"L0", "L1", "L2", "invalid"}
+
+var (
+	hypothesisTitleRegex = regexp.MustCompile(`(?m)^# Hypothesis: (.+)$`)
+	decisionTitleRegex   = regexp.MustCompile(`(?m)^# (.+)$`)
+	conflictMarkerRegex  = regexp.MustCompile(`(?m)^(<{7}|>{7})( |$)`)
+)
+
+// projectionFile is a parsed markdown file of the projection
+type projectionFile struct {
+	path       string
+	fields     Frontmatter
+	body       string
+	tampered   bool
+	conflicted bool // Contains git conflict markers
+}
+
+// reconcileDiff is one difference between the markdown and the DB, with the
+// repair for each source. A nil repair leaves the difference in place.
+type reconcileDiff struct {
+	kind       string // holon, evidence or decision
+	id         string
+	change     string
+	toDB       func(uow *unitOfWork) error
+	dbAction   string
+	toMarkdown func(uow *unitOfWork) error
+	mdAction   string
+}
+
+// Reconcile diffs the knowledge, evidence and decisions of the current
+// context against its holons and their evidence. With from set, it rebuilds the DB
+// from the markdown or the markdown from the DB in one unit of work; dryRun
+// (or an empty from) only reports what differs and what would be done.
+func (t *Tools) Reconcile(from string, dryRun bool) (string, error) {
+	defer t.RecordWork("Reconcile", time.Now())
+	if t.DB == nil {
+		return "", fmt.Errorf("DB not initialized")
+	}
+	switch from {
+	case "", ReconcileFromMarkdown, ReconcileFromDB:
+	default:
+		return "", fmt.Errorf("unknown reconcile source %q (expected %s or %s)", from, ReconcileFromMarkdown, ReconcileFromDB)
+	}
+	if from == "" {
+		dryRun = true
+	}
+
+	ctx := t.callContext()
+	p, err := t.readProjection()
+	if err != nil {
+		return "", err
+	}
+	dbHolons, dbDecisions, err := t.loadHolons(ctx)
+	if err != nil {
+		return "", err
+	}
+	diffs, err := t.reconcileDiffs(ctx, p, dbHolons, dbDecisions)
+	if err != nil {
+		return "", err
+	}
+	conflicts := t.mergeConflicts(p, dbHolons, dbDecisions)
+
+	var sb strings.Builder
+	switch {
+	case from == "":
+		sb.WriteString("## Reconcile: differences\n\n")
+	case dryRun:
+		sb.WriteString(fmt.Sprintf("## Reconcile from %s (dry run)\n\n", from))
+	default:
+		sb.WriteString(fmt.Sprintf("## Reconcile from %s\n\n", from))
+	}
+	if len(conflicts) > 0 {
+		sb.WriteString(formatConflicts(conflicts))
+		if from != ReconcileFromDB {
+			sb.WriteString("The DB cannot be rebuilt from the markdown until these are resolved.\n\n")
+		}
+		if from == ReconcileFromMarkdown && !dryRun {
+			input := map[string]string{"from": from, "dry_run": "false"}
+			t.AuditLog("quint_reconcile", "reconcile", t.actor(), "", "BLOCKED", input, fmt.Sprintf("%d merge conflicts", len(conflicts)))
+			return "", fmt.Errorf("%d merge conflicts block rebuilding the DB from the markdown:\n%s", len(conflicts), formatConflicts(conflicts))
+		}
+	}
+	if len(diffs) == 0 {
+		sb.WriteString("Markdown and DB are in sync.\n")
+		return sb.String(), nil
+	}
+
+	applied, skipped := 0, 0
+	for _, d := range diffs {
+		repair, action := d.toDB, d.dbAction
+		if from == ReconcileFromDB {
+			repair, action = d.toMarkdown, d.mdAction
+		}
+		switch {
+		case from == "":
+			sb.WriteString(fmt.Sprintf("- %s `%s`: %s\n", d.kind, d.id, d.change))
+			continue
+		case repair == nil:
+			skipped++
+			sb.WriteString(fmt.Sprintf("- %s `%s`: %s → skipped: %s\n", d.kind, d.id, d.change, action))
+			continue
+		}
+		applied++
+		sb.WriteString(fmt.Sprintf("- %s `%s`: %s → %s\n", d.kind, d.id, d.change, action))
+	}
+
+	if from == "" {
+		sb.WriteString(fmt.Sprintf("\n%d differences. Rebuild with from=%s or from=%s.\n", len(diffs), ReconcileFromMarkdown, ReconcileFromDB))
+		return sb.String(), nil
+	}
+
+	summary := fmt.Sprintf("%d differences: %d to repair, %d skipped", len(diffs), applied, skipped)
+	input := map[string]string{"from": from, "dry_run": fmt.Sprintf("%t", dryRun)}
+	if dryRun {
+		sb.WriteString(fmt.Sprintf("\n%s. Nothing was changed.\n", summary))
+		return sb.String(), nil
+	}
+
+	err = t.atomically(func(uow *unitOfWork) error {
+		for _, d := range diffs {
+			repair := d.toDB
+			if from == ReconcileFromDB {
+				repair = d.toMarkdown
+			}
+			if repair == nil {
+				continue
+			}
+			if err := repair(uow); err != nil {
+				return fmt.Errorf("failed to reconcile %s %s: %w", d.kind, d.id, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.AuditLog("quint_reconcile", "reconcile", t.actor(), "", "ERROR", input, err.Error())
+		return "", err
+	}
+
+	t.AuditLog("quint_reconcile", "reconcile", t.actor(), "", "SUCCESS", input, summary)
+	sb.WriteString(fmt.Sprintf("\n%s. Applied.\n", summary))
+	return sb.String(), nil
+}
+
+// reconcileDiffs collects every difference, ordered by kind and ID
+func (t *Tools) reconcileDiffs(ctx context.Context, p *projection, dbHolons, dbDecisions map[string]db.Holon) ([]reconcileDiff, error) {
+	evidenceDiffs, err := t.diffEvidence(ctx, p.evidence)
+	if err != nil {
+		return nil, err
+	}
+	diffs := append(t.diffHolons(p.holons, dbHolons), evidenceDiffs...)
+	return append(diffs, t.diffDecisions(p.decisions, dbDecisions)...), nil
+}
+
+// loadHolons splits the DB holons of the current context into projected
+// hypotheses and DRRs
+func (t *Tools) loadHolons(ctx context.Context) (map[string]db.Holon, map[string]db.Holon, error) {
+	holons, err := t.DB.ListAllHolons(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	dbHolons := make(map[string]db.Holon)
+	dbDecisions := make(map[string]db.Holon)
+	for _, h := range holons {
+		switch {
+		case h.ContextID != t.contextID():
+		case h.Layer == "DRR":
+			dbDecisions[h.ID] = h
+		case isProjectedLayer(h.Layer):
+			dbHolons[h.ID] = h
+		}
+	}
+	return dbHolons, dbDecisions, nil
+}
+
+// formatConflicts lists merge conflicts with how to resolve each
+func formatConflicts(conflicts []mergeConflict) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("### Conflicts (%d)\n", len(conflicts)))
+	for _, c := range conflicts {
+		sb.WriteString(fmt.Sprintf("- %s `%s`: %s → %s\n", c.kind, c.id, c.problem, c.resolution))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func isProjectedLayer(layer string) bool {
+	for _, l := range projectedLayers {
+		if l == layer {
+			return true
+		}
+	}
+	return false
+}
+
+// diffHolons compares the knowledge layers with the hypothesis holons
+func (t *Tools) diffHolons(files map[string]map[string]projectionFile, dbHolons map[string]db.Holon) []reconcileDiff {
+	var diffs []reconcileDiff
+	for _, id := range unionIDs(files, dbHolons) {
+		holon, inDB := dbHolons[id]
+		layers := files[id]
+		d := reconcileDiff{kind: "holon", id: id}
+
+		switch {
+		case len(layers) > 1:
+			names := sortedKeys(layers)
+			d.change = fmt.Sprintf("markdown in %s", strings.Join(names, " and "))
+			d.dbAction = "resolve the duplicate files by hand"
+			if inDB {
+				d.mdAction = fmt.Sprintf("keep %s from DB", holon.Layer)
+				d.toMarkdown = t.writeHolonFiles(holon, layers)
+			} else {
+				d.mdAction = "not in DB, resolve by hand"
+			}
+
+		case !inDB:
+			layer, f := onlyEntry(layers)
+			d.change = fmt.Sprintf("markdown only (%s)", layer)
+			d.dbAction, d.toDB = "create in DB", t.createHolonFromFile(id, layer, f)
+			d.mdAction, d.toMarkdown = "remove file", removeFile(f.path)
+
+		case len(layers) == 0:
+			d.change = fmt.Sprintf("DB only (%s)", holon.Layer)
+			d.dbAction = "kept: holons are not deleted from the DB"
+			d.mdAction, d.toMarkdown = "write file", t.writeHolonFiles(holon, layers)
+
+		default:
+			layer, f := onlyEntry(layers)
+			var changes []string
+			if layer != holon.Layer {
+				changes = append(changes, fmt.Sprintf("layer %s in markdown, %s in DB", layer, holon.Layer))
+			}
+			fileTitle := fileHolonTitle(f, id)
+			if fileTitle != holon.Title || f.body != holon.Content || f.fields.String("kind") != holon.Kind.String || f.fields.String("scope") != holon.Scope.String {
+				changes = append(changes, "content differs")
+			}
+			if f.tampered {
+				changes = append(changes, "content_hash mismatch")
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			d.change = strings.Join(changes, "; ")
+			d.dbAction, d.toDB = "update DB", t.updateHolonFromFile(holon, layer, f)
+			d.mdAction, d.toMarkdown = "rewrite file", t.writeHolonFiles(holon, layers)
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs
+}
+
+// fileHolonTitle is the hypothesis title in a knowledge file's heading
+func fileHolonTitle(f projectionFile, id string) string {
+	if m := hypothesisTitleRegex.FindStringSubmatch(f.body); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	return id
+}
+
+func (t *Tools) createHolonFromFile(id, layer string, f projectionFile) func(uow *unitOfWork) error {
+	return func(uow *unitOfWork) error {
+		ctx := t.callContext()
+		scope := f.fields.String("scope")
+		if err := t.DB.CreateHolon(ctx, id, "hypothesis", f.fields.String("kind"), layer, fileHolonTitle(f, id), f.body, t.contextID(), scope, ""); err != nil {
+			return err
+		}
+		if f.tampered {
+			if err := uow.WriteWithHash(f.path, f.fields, f.body); err != nil {
+				return err
+			}
+		}
+		return t.storeScopeSlice(ctx, id, scope)
+	}
+}
+
+func (t *Tools) updateHolonFromFile(holon db.Holon, layer string, f projectionFile) func(uow *unitOfWork) error {
+	return func(uow *unitOfWork) error {
+		ctx := t.callContext()
+		scope := f.fields.String("scope")
+		if err := t.DB.UpdateHolonContent(ctx, holon.ID, fileHolonTitle(f, holon.ID), f.body, f.fields.String("kind"), scope); err != nil {
+			return err
+		}
+		if layer != holon.Layer {
+			if err := t.DB.UpdateHolonLayer(ctx, holon.ID, layer); err != nil {
+				return err
+			}
+		}
+		if f.tampered {
+			if err := uow.WriteWithHash(f.path, f.fields, f.body); err != nil {
+				return err
+			}
+		}
+		return t.storeScopeSlice(ctx, holon.ID, scope)
+	}
+}
+
+func (t *Tools) storeScopeSlice(ctx context.Context, id, scope string) error {
+	parsed, err := assurance.ParseScope(scope)
+	if err != nil {
+		return nil // Free-text scopes have no slice
+	}
+	return t.DB.UpdateHolonScopeSlice(ctx, id, parsed.Encode())
+}
+
+// writeHolonFiles projects a holon to its layer and removes its files in
+// any other layer
+func (t *Tools) writeHolonFiles(holon db.Holon, existing map[string]projectionFile) func(uow *unitOfWork) error {
+	return func(uow *unitOfWork) error {
+		for layer, f := range existing {
+			if layer != holon.Layer {
+				if err := uow.Remove(f.path); err != nil {
+					return err
+				}
+			}
+		}
+		path := t.holonPath(holon.Layer, holon.ID)
+		fields, err := t.holonFields(holon)
+		if err != nil {
+			return err
+		}
+		return uow.WriteWithHash(path, fields, holon.Content)
+	}
+}
+
+// holonFields is the frontmatter of a holon's knowledge file
+func (t *Tools) holonFields(holon db.Holon) (Frontmatter, error) {
+	fields := Frontmatter{
+		"scope": holon.Scope.String,
+		"kind":  holon.Kind.String,
+	}
+	deps, err := t.holonDependsOn(t.callContext(), holon.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(deps) > 0 {
+		fields["depends_on"] = deps
+	}
+	return fields, nil
+}
+
+// diffEvidence compares the evidence directory with the evidence on the
+// current context's holons
+func (t *Tools) diffEvidence(ctx context.Context, byID map[string]projectionFile) ([]reconcileDiff, error) {
+	rows, err := t.DB.ListAllEvidence(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dbEvidence := make(map[string]db.Evidence, len(rows))
+	for _, e := range rows {
+		if contextID, _ := splitHolonID(e.HolonID); contextID == t.contextID() {
+			dbEvidence[e.ID] = e
+		}
+	}
+
+	var diffs []reconcileDiff
+	for _, id := range unionIDs(byID, dbEvidence) {
+		f, inMarkdown := byID[id]
+		e, inDB := dbEvidence[id]
+		d := reconcileDiff{kind: "evidence", id: id}
+
+		switch {
+		case !inDB:
+			d.change = fmt.Sprintf("markdown only (target %s)", f.fields.String("target"))
+			d.dbAction, d.toDB = "create in DB", t.createEvidenceFromFile(id, f)
+			d.mdAction, d.toMarkdown = "remove file", removeFile(f.path)
+
+		case !inMarkdown:
+			d.change = fmt.Sprintf("DB only (target %s)", e.HolonID)
+			d.dbAction = "kept: evidence is not deleted from the DB"
+			d.mdAction, d.toMarkdown = "write file", t.writeEvidenceFile(e)
+
+		default:
+			var changes []string
+			if evidenceDiffers(f, e) {
+				changes = append(changes, "content differs")
+			}
+			if f.tampered {
+				changes = append(changes, "content_hash mismatch")
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			d.change = strings.Join(changes, "; ")
+			d.dbAction, d.toDB = "update DB", t.updateEvidenceFromFile(id, f)
+			d.mdAction, d.toMarkdown = "rewrite file", t.writeEvidenceFile(e)
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+func evidenceContent(f projectionFile) string {
+	return strings.TrimPrefix(f.body, "\n")
+}
+
+func evidenceDiffers(f projectionFile, e db.Evidence) bool {
+	validUntil := ""
+	if e.ValidUntil.Valid {
+		validUntil = e.ValidUntil.Time.Format("2006-01-02")
+	}
+	fileValidUntil := f.fields.String("valid_until")
+	if date, ok := f.fields.Date("valid_until"); ok {
+		fileValidUntil = date.Format("2006-01-02")
+	}
+	return f.fields.String("target") != e.HolonID ||
+		f.fields.String("type") != e.Type ||
+		f.fields.String("verdict") != e.Verdict ||
+		f.fields.String("assurance_level") != e.AssuranceLevel.String ||
+		f.fields.String("carrier_ref") != e.CarrierRef.String ||
+		fileValidUntil != validUntil ||
+		evidenceContent(f) != e.Content
+}
+
+func (t *Tools) createEvidenceFromFile(id string, f projectionFile) func(uow *unitOfWork) error {
+	return func(uow *unitOfWork) error {
+		ctx := t.callContext()
+		target := f.fields.String("target")
+		if err := t.DB.AddEvidence(ctx, id, target, f.fields.String("type"), evidenceContent(f), f.fields.String("verdict"),
+			f.fields.String("assurance_level"), f.fields.String("carrier_ref"), f.fields.String("valid_until")); err != nil {
+			return err
+		}
+		if f.tampered {
+			if err := uow.WriteWithHash(f.path, f.fields, f.body); err != nil {
+				return err
+			}
+		}
+		return t.DB.Link(ctx, id, target, "verifiedBy")
+	}
+}
+
+func (t *Tools) updateEvidenceFromFile(id string, f projectionFile) func(uow *unitOfWork) error {
+	return func(uow *unitOfWork) error {
+		if err := t.DB.UpdateEvidence(t.callContext(), id, f.fields.String("target"), f.fields.String("type"), evidenceContent(f),
+			f.fields.String("verdict"), f.fields.String("assurance_level"), f.fields.String("carrier_ref"), f.fields.String("valid_until")); err != nil {
+			return err
+		}
+		if f.tampered {
+			return uow.WriteWithHash(f.path, f.fields, f.body)
+		}
+		return nil
+	}
+}
+
+func (t *Tools) writeEvidenceFile(e db.Evidence) func(uow *unitOfWork) error {
+	return func(uow *unitOfWork) error {
+		return uow.WriteWithHash(t.evidencePath(e.HolonID, e.ID), evidenceFields(e), "\n"+e.Content)
+	}
+}
+
+// evidenceFields is the frontmatter of an evidence file
+func evidenceFields(e db.Evidence) Frontmatter {
+	date := ""
+	if e.CreatedAt.Valid {
+		date = e.CreatedAt.Time.Format("2006-01-02")
+	}
+	validUntil := ""
+	if e.ValidUntil.Valid {
+		validUntil = e.ValidUntil.Time.Format("2006-01-02")
+	}
+	return Frontmatter{
+		"id":              e.ID,
+		"type":            e.Type,
+		"target":          e.HolonID,
+		"verdict":         e.Verdict,
+		"assurance_level": e.AssuranceLevel.String,
+		"carrier_ref":     e.CarrierRef.String,
+		"valid_until":     dateField(validUntil),
+		"date":            dateField(date),
+	}
+}
+
+// diffDecisions compares .quint/decisions with the DRR holons. Of several
+// files claiming one DRR, only the first is compared; the rest are conflicts.
+func (t *Tools) diffDecisions(decisions map[string][]projectionFile, dbDecisions map[string]db.Holon) []reconcileDiff {
+	files := make(map[string]projectionFile, len(decisions))
+	titles := make(map[string]string, len(decisions))
+	for id, fs := range decisions {
+		files[id] = fs[0]
+		titles[id] = decisionTitle(fs[0])
+	}
+
+	var diffs []reconcileDiff
+	for _, id := range unionIDs(files, dbDecisions) {
+		f, inMarkdown := files[id]
+		holon, inDB := dbDecisions[id]
+		d := reconcileDiff{kind: "decision", id: id}
+
+		switch {
+		case !inDB:
+			d.change = "markdown only"
+			d.dbAction, d.toDB = "create in DB", t.createDecisionFromFile(id, titles[id], f)
+			d.mdAction, d.toMarkdown = "remove file", removeFile(f.path)
+
+		case !inMarkdown:
+			d.change = "DB only"
+			d.dbAction = "kept: decisions are not deleted from the DB"
+			d.mdAction, d.toMarkdown = "write file", t.writeDecisionFile(holon, "")
+
+		default:
+			var changes []string
+			if titles[id] != holon.Title || f.body != holon.Content || f.fields.String("winner_id") != holon.ParentID.String {
+				changes = append(changes, "content differs")
+			}
+			if f.tampered {
+				changes = append(changes, "content_hash mismatch")
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			d.change = strings.Join(changes, "; ")
+			d.dbAction, d.toDB = "update DB", t.updateDecisionFromFile(id, titles[id], f)
+			d.mdAction, d.toMarkdown = "rewrite file", t.writeDecisionFile(holon, f.path)
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs
+}
+
+func (t *Tools) createDecisionFromFile(id, title string, f projectionFile) func(uow *unitOfWork) error {
+	return func(uow *unitOfWork) error {
+		ctx := t.callContext()
+		winnerID := f.fields.String("winner_id")
+		if err := t.DB.CreateHolon(ctx, id, "DRR", "", "DRR", title, f.body, t.contextID(), "", winnerID); err != nil {
+			return err
+		}
+		if f.tampered {
+			if err := uow.WriteWithHash(f.path, f.fields, f.body); err != nil {
+				return err
+			}
+		}
+		if winnerID == "" {
+			return nil
+		}
+		if _, err := t.DB.GetHolon(ctx, winnerID); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: winner '%s' of decision %s not found, skipping selects relation\n", winnerID, id)
+			return nil
+		}
+		return t.createRelation(ctx, id, "selects", winnerID, 3)
+	}
+}
+
+func (t *Tools) updateDecisionFromFile(id, title string, f projectionFile) func(uow *unitOfWork) error {
+	return func(uow *unitOfWork) error {
+		if err := t.DB.UpdateHolonContent(t.callContext(), id, title, f.body, "", ""); err != nil {
+			return err
+		}
+		if f.tampered {
+			return uow.WriteWithHash(f.path, f.fields, f.body)
+		}
+		return nil
+	}
+}
+
+// writeDecisionFile projects a DRR holon to path, or to a new
+// DRR-<date>-<slug>.md when it has no file yet
+func (t *Tools) writeDecisionFile(holon db.Holon, path string) func(uow *unitOfWork) error {
+	return func(uow *unitOfWork) error {
+		if path == "" {
+			path = t.decisionPath(holon.ID, decisionCreated(holon))
+		}
+		return uow.WriteWithHash(path, decisionFields(holon), holon.Content)
+	}
+}
+
+// decisionFields is the frontmatter of a DRR file
+func decisionFields(holon db.Holon) Frontmatter {
+	return Frontmatter{
+		"type":      "DRR",
+		"winner_id": holon.ParentID.String,
+		"created":   decisionCreated(holon).Truncate(time.Second),
+	}
+}
+
+func decisionCreated(holon db.Holon) time.Time {
+	if holon.CreatedAt.Valid {
+		return holon.CreatedAt.Time
+	}
+	return time.Now()
+}
+
+func removeFile(path string) func(uow *unitOfWork) error {
+	return func(uow *unitOfWork) error {
+		return uow.Remove(path)
+	}
+}
+
+// readProjectionDir parses every markdown file in dir, keyed by name without
+// the .md extension. A missing directory has no files.
+func readProjectionDir(dir string) (map[string]projectionFile, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]projectionFile)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".md") || strings.HasPrefix(name, ".") {
+			continue
+		}
+		f, err := readProjectionFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		files[strings.TrimSuffix(name, ".md")] = f
+	}
+	return files, nil
+}
+
+func readProjectionFile(path string) (projectionFile, error) {
+	content, tampered, _, _, err := ValidateFile(path)
+	if err != nil {
+		return projectionFile{}, err
+	}
+	f := projectionFile{path: path, fields: make(Frontmatter), body: content, tampered: tampered}
+	f.conflicted = conflictMarkerRegex.MatchString(content)
+	header, body, ok := splitFrontmatter(content)
+	if !ok {
+		return f, nil
+	}
+	f.body = body
+	fields, err := DecodeFrontmatter(header)
+	if err != nil {
+		if f.conflicted {
+			return f, nil // Reported as a merge conflict
+		}
+		return projectionFile{}, fmt.Errorf("invalid frontmatter in %s: %w", path, err)
+	}
+	delete(fields, "content_hash")
+	f.fields = fields
+	return f, nil
+}
+
+// unionIDs returns the sorted keys of both maps
+func unionIDs[A, B any](a map[string]A, b map[string]B) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for id := range a {
+		seen[id] = true
+	}
+	for id := range b {
+		seen[id] = true
+	}
+	return sortedKeys(seen)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// onlyEntry returns the single entry of a one-element map
+func onlyEntry(m map[string]projectionFile) (string, projectionFile) {
+	for k, v := range m {
+		return k, v
+	}
+	return "", projectionFile{}
+}
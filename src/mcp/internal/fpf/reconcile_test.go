@@ -0,0 +1,145 @@
+package fpf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReconcile_InSyncAfterWorkflow(t *testing.T) {
+	tools, _, _ := setupTools(t)
+
+	if _, err := tools.ProposeHypothesis("Cache Layer", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	if _, err := tools.VerifyHypothesis("cache-layer", `{"check":"ok"}`, "PASS", -1); err != nil {
+		t.Fatalf("VerifyHypothesis failed: %v", err)
+	}
+	if _, err := tools.FinalizeDecision("Use Cache", "cache-layer", nil, "Ctx", "Dec", "Rat", "Cons", "", ""); err != nil {
+		t.Fatalf("FinalizeDecision failed: %v", err)
+	}
+
+	report, err := tools.Reconcile("", false)
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if !strings.Contains(report, "in sync") {
+		t.Errorf("Expected markdown and DB in sync, got:\n%s", report)
+	}
+	if _, err := tools.Reconcile("git", false); err == nil {
+		t.Error("Expected an unknown source to be rejected")
+	}
+}
+
+func TestReconcile_FromMarkdown(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+	ctx := context.Background()
+	knowledge := filepath.Join(tempDir, ".quint", "knowledge")
+
+	if _, err := tools.ProposeHypothesis("Moved In Merge", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+
+	// A merge brings in a new L1 hypothesis and promotes an existing one
+	body := "\n# Hypothesis: Merged Branch\n\nFrom the other branch"
+	if err := WriteWithHash(filepath.Join(knowledge, "L1", "merged-branch.md"), Frontmatter{"kind": "system", "scope": "global"}, body); err != nil {
+		t.Fatalf("failed to write merged file: %v", err)
+	}
+	if err := os.Rename(filepath.Join(knowledge, "L0", "moved-in-merge.md"), filepath.Join(knowledge, "L1", "moved-in-merge.md")); err != nil {
+		t.Fatalf("failed to move file: %v", err)
+	}
+
+	report, err := tools.Reconcile(ReconcileFromMarkdown, true)
+	if err != nil {
+		t.Fatalf("Reconcile dry run failed: %v", err)
+	}
+	for _, want := range []string{
+		"holon `merged-branch`: markdown only (L1) → create in DB",
+		"holon `moved-in-merge`: layer L1 in markdown, L0 in DB → update DB",
+		"Nothing was changed",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected dry run report to contain %q, got:\n%s", want, report)
+		}
+	}
+	if _, err := tools.DB.GetHolon(ctx, "merged-branch"); err == nil {
+		t.Fatal("Dry run must not change the DB")
+	}
+
+	if _, err := tools.Reconcile(ReconcileFromMarkdown, false); err != nil {
+		t.Fatalf("Reconcile from markdown failed: %v", err)
+	}
+	merged, err := tools.DB.GetHolon(ctx, "merged-branch")
+	if err != nil || merged.Layer != "L1" || merged.Title != "Merged Branch" || merged.Content != body {
+		t.Errorf("Expected merged-branch rebuilt from markdown, got %+v (%v)", merged, err)
+	}
+	moved, _ := tools.DB.GetHolon(ctx, "moved-in-merge")
+	if moved.Layer != "L1" {
+		t.Errorf("Expected moved-in-merge in L1, got %s", moved.Layer)
+	}
+
+	report, _ = tools.Reconcile("", false)
+	if !strings.Contains(report, "in sync") {
+		t.Errorf("Expected sync after rebuild, got:\n%s", report)
+	}
+}
+
+func TestReconcile_FromDB(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+	quintDir := filepath.Join(tempDir, ".quint")
+
+	if _, err := tools.ProposeHypothesis("Lost File", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	if _, err := tools.ManageEvidence(PhaseDeduction, "add", "lost-file", "note", "Observed", "pass", "L0", "", ""); err != nil {
+		t.Fatalf("ManageEvidence failed: %v", err)
+	}
+
+	lost := filepath.Join(quintDir, "knowledge", "L0", "lost-file.md")
+	if err := os.Remove(lost); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	stray := filepath.Join(quintDir, "knowledge", "L2", "stray.md")
+	if err := WriteWithHash(stray, Frontmatter{"kind": "system"}, "\n# Hypothesis: Stray\n"); err != nil {
+		t.Fatalf("failed to write stray file: %v", err)
+	}
+	evidence, _ := filepath.Glob(filepath.Join(quintDir, "evidence", "*.md"))
+	if len(evidence) != 1 {
+		t.Fatalf("Expected one evidence file, got %v", evidence)
+	}
+	if err := os.WriteFile(evidence[0], []byte("edited by hand"), 0644); err != nil {
+		t.Fatalf("failed to edit evidence: %v", err)
+	}
+
+	report, err := tools.Reconcile(ReconcileFromDB, false)
+	if err != nil {
+		t.Fatalf("Reconcile from DB failed: %v", err)
+	}
+	for _, want := range []string{
+		"holon `lost-file`: DB only (L0) → write file",
+		"holon `stray`: markdown only (L2) → remove file",
+		"content differs → rewrite file",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, report)
+		}
+	}
+
+	if _, err := os.Stat(lost); err != nil {
+		t.Errorf("Expected lost-file.md to be restored: %v", err)
+	}
+	if _, err := os.Stat(stray); !os.IsNotExist(err) {
+		t.Errorf("Expected stray.md to be removed, got %v", err)
+	}
+	data, _ := os.ReadFile(evidence[0])
+	if !strings.Contains(string(data), "Observed") {
+		t.Errorf("Expected evidence file rebuilt from DB, got:\n%s", data)
+	}
+
+	report, _ = tools.Reconcile("", false)
+	if !strings.Contains(report, "in sync") {
+		t.Errorf("Expected sync after rebuild, got:\n%s", report)
+	}
+}
@@ -0,0 +1,244 @@
+package fpf
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/m0n0x41d/quint-code/db"
+)
+
+// ResourceScheme prefixes the URIs of knowledge base resources
+const ResourceScheme = "quint://"
+
+// Resource kinds, the first segment of a resource URI
+const (
+	ResourceHolon     = "holon"      // quint://holon/<id>
+	ResourceEvidence  = "evidence"   // quint://evidence/<id>
+	ResourceDecision  = "decision"   // quint://decision/<id>
+	ResourceContext   = "context"    // quint://context
+	ResourceAuditTree = "audit-tree" // quint://audit-tree/<holon id>
+)
+
+// ErrResourceNotFound is returned for a URI that names nothing in the
+// knowledge base
+var ErrResourceNotFound = errors.New("resource not found")
+
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+type ResourceContents struct {
+	URI      string                 `json:"uri"`
+	MimeType string                 `json:"mimeType,omitempty"`
+	Text     string                 `json:"text"`
+	Meta     map[string]interface{} `json:"_meta,omitempty"` // Holons and DRRs carry their current R here, outside the projection
+}
+
+// ResourceURI is the stable URI of a knowledge base resource
+func ResourceURI(kind, id string) string {
+	if id == "" {
+		return ResourceScheme + kind
+	}
+	return ResourceScheme + kind + "/" + id
+}
+
+// ParseResourceURI splits a resource URI into its kind and ID
+func ParseResourceURI(uri string) (kind, id string, err error) {
+	rest, ok := strings.CutPrefix(uri, ResourceScheme)
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s is not a %s URI", ErrResourceNotFound, uri, ResourceScheme)
+	}
+	kind, id, _ = strings.Cut(rest, "/")
+	switch kind {
+	case ResourceContext:
+		if id != "" {
+			return "", "", fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+		}
+	case ResourceHolon, ResourceEvidence, ResourceDecision, ResourceAuditTree:
+		if id == "" {
+			return "", "", fmt.Errorf("%w: %s has no ID", ErrResourceNotFound, uri)
+		}
+	default:
+		return "", "", fmt.Errorf("%w: unknown resource kind %q", ErrResourceNotFound, kind)
+	}
+	return kind, id, nil
+}
+
+// ResourceTemplates describes the URI patterns ReadResource accepts
+func ResourceTemplates() []ResourceTemplate {
+	return []ResourceTemplate{
+		{URITemplate: ResourceURI(ResourceHolon, "{id}"), Name: "Holon", Description: "A hypothesis with its scope, kind and dependencies", MimeType: "text/markdown"},
+		{URITemplate: ResourceURI(ResourceEvidence, "{id}"), Name: "Evidence", Description: "An evidence record with its verdict and validity", MimeType: "text/markdown"},
+		{URITemplate: ResourceURI(ResourceDecision, "{id}"), Name: "Decision", Description: "A Design Rationale Record", MimeType: "text/markdown"},
+		{URITemplate: ResourceURI(ResourceAuditTree, "{holon_id}"), Name: "Audit tree", Description: "The assurance tree of a holon with R-scores and CL penalties", MimeType: "text/plain"},
+	}
+}
+
+// ListResources lists the bounded context and every holon, evidence record
+// and DRR of the current context. Audit trees are only reachable through their
+// template.
+func (t *Tools) ListResources() ([]Resource, error) {
+	defer t.RecordWork("ListResources", time.Now())
+	if t.DB == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+	ctx := t.callContext()
+
+	var resources []Resource
+	if _, err := os.Stat(t.contextFile()); err == nil {
+		resources = append(resources, Resource{
+			URI:         ResourceURI(ResourceContext, ""),
+			Name:        "Bounded context: " + t.contextID(),
+			Description: "Vocabulary and invariants of the active context",
+			MimeType:    "text/markdown",
+		})
+	}
+
+	holons, err := t.DB.ListAllHolons(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range holons {
+		switch {
+		case h.ContextID != t.contextID():
+		case h.Layer == "DRR":
+			resources = append(resources, Resource{
+				URI:         ResourceURI(ResourceDecision, h.ID),
+				Name:        h.Title,
+				Description: "Decision record, winner " + h.ParentID.String,
+				MimeType:    "text/markdown",
+			})
+		case isProjectedLayer(h.Layer):
+			resources = append(resources, Resource{
+				URI:         ResourceURI(ResourceHolon, h.ID),
+				Name:        h.Title,
+				Description: fmt.Sprintf("%s hypothesis (%s)", h.Layer, h.Kind.String),
+				MimeType:    "text/markdown",
+			})
+		}
+	}
+
+	evidence, err := t.DB.ListAllEvidence(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range evidence {
+		if contextID, _ := splitHolonID(e.HolonID); contextID != t.contextID() {
+			continue
+		}
+		resources = append(resources, Resource{
+			URI:         ResourceURI(ResourceEvidence, e.ID),
+			Name:        fmt.Sprintf("%s evidence for %s", e.Type, e.HolonID),
+			Description: "Verdict: " + e.Verdict,
+			MimeType:    "text/markdown",
+		})
+	}
+	return resources, nil
+}
+
+// ReadResource renders the resource at uri from the DB. Holons, evidence and
+// DRRs read exactly as their projection files would be written, frontmatter
+// included; the audit tree is the quint_audit_tree output.
+func (t *Tools) ReadResource(uri string) (ResourceContents, error) {
+	defer t.RecordWork("ReadResource", time.Now())
+	if t.DB == nil {
+		return ResourceContents{}, fmt.Errorf("DB not initialized")
+	}
+	kind, id, err := ParseResourceURI(uri)
+	if err != nil {
+		return ResourceContents{}, err
+	}
+	ctx := t.callContext()
+	contents := ResourceContents{URI: uri, MimeType: "text/markdown"}
+
+	switch kind {
+	case ResourceContext:
+		data, err := os.ReadFile(t.contextFile())
+		if os.IsNotExist(err) {
+			return ResourceContents{}, fmt.Errorf("%w: context %s has no recorded context yet, run quint_record_context", ErrResourceNotFound, t.contextID())
+		}
+		if err != nil {
+			return ResourceContents{}, err
+		}
+		contents.Text = string(data)
+
+	case ResourceHolon, ResourceDecision, ResourceAuditTree:
+		holon, err := t.GetHolon(id)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && !holonIsKind(holon, kind)) {
+			return ResourceContents{}, fmt.Errorf("%w: no %s %q", ErrResourceNotFound, kind, id)
+		}
+		if err != nil {
+			return ResourceContents{}, err
+		}
+		switch kind {
+		case ResourceHolon:
+			fields, err := t.holonFields(holon)
+			if err != nil {
+				return ResourceContents{}, err
+			}
+			contents.Text = string(renderWithHash(fields, holon.Content))
+			contents.Meta = reliabilityMeta(holon)
+		case ResourceDecision:
+			contents.Text = string(renderWithHash(decisionFields(holon), holon.Content))
+			contents.Meta = reliabilityMeta(holon)
+		case ResourceAuditTree:
+			tree, err := t.VisualizeAudit(id)
+			if err != nil {
+				return ResourceContents{}, err
+			}
+			contents.MimeType = "text/plain"
+			contents.Text = tree
+		}
+
+	case ResourceEvidence:
+		e, err := t.DB.GetEvidenceByID(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ResourceContents{}, fmt.Errorf("%w: no evidence %q", ErrResourceNotFound, id)
+		}
+		if err != nil {
+			return ResourceContents{}, err
+		}
+		contents.Text = string(renderWithHash(evidenceFields(e), "\n"+e.Content))
+	}
+	return contents, nil
+}
+
+// reliabilityMeta is the R of a holon read through Tools.GetHolon, kept out
+// of the text so it reads exactly as the projection file
+func reliabilityMeta(holon db.Holon) map[string]interface{} {
+	if !holon.CachedRScore.Valid {
+		return nil
+	}
+	meta := map[string]interface{}{"r_eff": holon.CachedRScore.Float64}
+	if holon.RComputedAt.Valid {
+		meta["r_computed_at"] = holon.RComputedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return meta
+}
+
+// holonIsKind reports whether a holon can be read as a resource of kind:
+// DRRs are decisions, projected hypotheses are holons, and both have an
+// audit tree
+func holonIsKind(h db.Holon, kind string) bool {
+	switch kind {
+	case ResourceDecision:
+		return h.Layer == "DRR"
+	case ResourceHolon:
+		return isProjectedLayer(h.Layer)
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+package fpf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResources_ReadMatchesProjection(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+
+	if _, err := tools.RecordContext("Cache: A fast store.", "1. Reads are idempotent."); err != nil {
+		t.Fatalf("RecordContext failed: %v", err)
+	}
+	if _, err := tools.ProposeHypothesis("Base Layer", "Base", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	if _, err := tools.ProposeHypothesis("Cache Layer", "Content", "global", "system", "R", "", []string{"base-layer"}, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	if _, err := tools.ManageEvidence(PhaseDeduction, "add", "cache-layer", "internal", "Load test passed", "pass", "L1", "bench", "2030-01-01"); err != nil {
+		t.Fatalf("ManageEvidence failed: %v", err)
+	}
+	drrPath, err := tools.FinalizeDecision("Adopt Cache", "cache-layer", nil, "Context", "Decision", "Rationale", "Consequences", "", "")
+	if err != nil {
+		t.Fatalf("FinalizeDecision failed: %v", err)
+	}
+	evidence, _ := filepath.Glob(filepath.Join(tempDir, ".quint", "evidence", "*.md"))
+	if len(evidence) != 1 {
+		t.Fatalf("Expected one evidence file, got %v", evidence)
+	}
+
+	resources, err := tools.ListResources()
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+	byKind := make(map[string][]string)
+	for _, r := range resources {
+		kind, id, err := ParseResourceURI(r.URI)
+		if err != nil {
+			t.Fatalf("Listed an unreadable URI %s: %v", r.URI, err)
+		}
+		byKind[kind] = append(byKind[kind], id)
+	}
+	if len(byKind[ResourceContext]) != 1 || len(byKind[ResourceHolon]) != 2 || len(byKind[ResourceEvidence]) != 1 || len(byKind[ResourceDecision]) != 1 {
+		t.Fatalf("Expected a context, 2 holons, 1 evidence and 1 decision, got %v", byKind)
+	}
+
+	files := map[string]string{
+		ResourceURI(ResourceContext, ""):                           filepath.Join(tempDir, ".quint", "context.md"),
+		ResourceURI(ResourceHolon, "base-layer"):                   filepath.Join(tempDir, ".quint", "knowledge", "L0", "base-layer.md"),
+		ResourceURI(ResourceEvidence, byKind[ResourceEvidence][0]): evidence[0],
+		ResourceURI(ResourceDecision, byKind[ResourceDecision][0]): drrPath,
+	}
+	for uri, path := range files {
+		contents, err := tools.ReadResource(uri)
+		if err != nil {
+			t.Errorf("ReadResource(%s) failed: %v", uri, err)
+			continue
+		}
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if contents.Text != string(want) {
+			t.Errorf("%s does not match %s:\n%s\nwant:\n%s", uri, path, contents.Text, want)
+		}
+	}
+
+	tree, err := tools.ReadResource(ResourceURI(ResourceAuditTree, "cache-layer"))
+	if err != nil {
+		t.Fatalf("ReadResource(audit-tree) failed: %v", err)
+	}
+	if tree.MimeType != "text/plain" || !strings.Contains(tree.Text, "cache-layer") {
+		t.Errorf("Expected the audit tree of cache-layer, got %+v", tree)
+	}
+}
+
+func TestResources_NotFound(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	if _, err := tools.ProposeHypothesis("Cache Layer", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+
+	for _, uri := range []string{
+		"file:///etc/passwd",
+		"quint://holon",
+		"quint://holon/missing",
+		"quint://decision/cache-layer",
+		"quint://evidence/missing.md",
+		"quint://context",
+		"quint://context/extra",
+		"quint://unknown/cache-layer",
+	} {
+		if _, err := tools.ReadResource(uri); !errors.Is(err, ErrResourceNotFound) {
+			t.Errorf("Expected %s to be not found, got %v", uri, err)
+		}
+	}
+}
+
+func TestResources_ReadServesCurrentR(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	ctx := context.Background()
+	if err := tools.DB.CreateHolon(ctx, "fresh-r", "hypothesis", "system", "L1", "Fresh R", "Content", "default", "global", ""); err != nil {
+		t.Fatalf("CreateHolon failed: %v", err)
+	}
+	if err := tools.DB.AddEvidence(ctx, "e-fresh", "fresh-r", "test", "Passing test", "pass", "L1", "test-runner", "2099-01-01"); err != nil {
+		t.Fatalf("AddEvidence failed: %v", err)
+	}
+
+	tools.readOnly = true // As served alongside other reads
+	contents, err := tools.ReadResource(ResourceURI(ResourceHolon, "fresh-r"))
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if r, ok := contents.Meta["r_eff"].(float64); !ok || r != 1.0 {
+		t.Errorf("Expected the invalidated R recomputed as 1.0, got %v", contents.Meta)
+	}
+	if holon, _ := tools.DB.GetHolon(ctx, "fresh-r"); holon.RComputedAt.Valid {
+		t.Error("Expected a read-only read to leave the cache alone")
+	}
+}
@@ -0,0 +1,149 @@
+package fpf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// roles lists the FPF roles a session can assume
+var roles = []Role{RoleAbductor, RoleDeductor, RoleInductor, RoleAuditor, RoleDecider}
+
+// ParseRole resolves a role name case-insensitively
+func ParseRole(name string) (Role, error) {
+	for _, role := range roles {
+		if strings.EqualFold(name, string(role)) {
+			return role, nil
+		}
+	}
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = string(role)
+	}
+	return "", fmt.Errorf("unknown role %q (expected one of %s)", name, strings.Join(names, ", "))
+}
+
+// SessionFromClientInfo derives a session ID from the MCP initialize clientInfo
+func SessionFromClientInfo(name, version string) string {
+	if name == "" {
+		return ""
+	}
+	if version == "" {
+		return name
+	}
+	return name + "/" + version
+}
+
+// sessionRole returns the role bound to the tools' session in the current
+// context, if any
+func (t *Tools) sessionRole() (Role, bool) {
+	if t.FSM == nil || t.Session == "" {
+		return "", false
+	}
+	active := t.FSM.State.ActiveRole
+	if active.Role == "" || active.SessionID != t.Session {
+		return "", false
+	}
+	return active.Role, true
+}
+
+// sessionActor identifies the current performer as Role@session, the bare
+// session, or "" outside an MCP session
+func (t *Tools) sessionActor() string {
+	if role, ok := t.sessionRole(); ok {
+		return fmt.Sprintf("%s@%s", role, t.Session)
+	}
+	return t.Session
+}
+
+// actor is the audit_log actor for actions taken by the agent
+func (t *Tools) actor() string {
+	if who := t.sessionActor(); who != "" {
+		return who
+	}
+	return "agent"
+}
+
+// actingRole resolves the role a tool acts under: the session's assumed role,
+// or the tool's own role when no role has been assumed. A session acting
+// outside its assumed role is refused.
+func (t *Tools) actingRole(tool string, target Phase, toolRole Role) (Role, error) {
+	role, ok := t.sessionRole()
+	if !ok {
+		return toolRole, nil
+	}
+	if !isValidRoleForPhase(target, role) {
+		return "", &PreconditionError{
+			Tool:       tool,
+			Condition:  fmt.Sprintf("active role %s cannot act in %s phase", role, target),
+			Suggestion: fmt.Sprintf("Call quint_assume_role with role %s (or quint_release_role) before %s", toolRole, tool),
+		}
+	}
+	return role, nil
+}
+
+// AssumeRole binds the tools' session to a role in the current context. The
+// session is the one the server derived at initialize: label is only recorded
+// with the assignment, so a client cannot pass as another performer and slip
+// past separation of duties.
+func (t *Tools) AssumeRole(roleName, label string) (string, error) {
+	defer t.RecordWork("AssumeRole", time.Now())
+
+	role, err := ParseRole(roleName)
+	if err != nil {
+		return "", err
+	}
+	if t.Session == "" {
+		return "", fmt.Errorf("no MCP session to bind: the client sent no clientInfo at initialize")
+	}
+
+	active := t.FSM.State.ActiveRole
+	if active.Role != "" && active.SessionID != t.Session {
+		return "", fmt.Errorf("role %s is held by session %q in context %s; it must call quint_release_role first",
+			active.Role, active.SessionID, t.contextID())
+	}
+
+	t.FSM.State.ActiveRole = RoleAssignment{Role: role, SessionID: t.Session, Context: t.contextID()}
+	if t.FSM.DB != nil {
+		if err := t.FSM.SaveState(t.contextID()); err != nil {
+			return "", err
+		}
+	}
+
+	details := map[string]string{"role": string(role), "previous": string(active.Role)}
+	if label != "" {
+		details["label"] = label
+	}
+	t.AuditLog("quint_assume_role", "assume_role", t.actor(), "", "SUCCESS", details, "")
+	return fmt.Sprintf("Session %s assumed role %s in context %s (phase %s)", t.Session, role, t.contextID(), t.FSM.GetPhase()), nil
+}
+
+// ReleaseRole unbinds the session's role in the current context. Like
+// AssumeRole it only acts for the tools' own session; label is recorded, so
+// a client cannot release a role another session holds.
+func (t *Tools) ReleaseRole(label string) (string, error) {
+	defer t.RecordWork("ReleaseRole", time.Now())
+
+	active := t.FSM.State.ActiveRole
+	if active.Role == "" {
+		return fmt.Sprintf("No role is held in context %s.", t.contextID()), nil
+	}
+	if t.Session == "" || active.SessionID != t.Session {
+		return "", fmt.Errorf("role %s is held by another session in context %s; only that session can release it", active.Role, t.contextID())
+	}
+
+	actor := t.actor()
+	t.FSM.State.ActiveRole = RoleAssignment{}
+	if t.FSM.DB != nil {
+		if err := t.FSM.SaveState(t.contextID()); err != nil {
+			return "", err
+		}
+	}
+
+	details := map[string]string{"role": string(active.Role)}
+	if label != "" {
+		details["label"] = label
+	}
+	t.AuditLog("quint_release_role", "release_role", actor, "", "SUCCESS", details, "")
+	return fmt.Sprintf("Session %s released role %s in context %s", t.Session, active.Role, t.contextID()), nil
+}
@@ -0,0 +1,108 @@
+package fpf
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseRole(t *testing.T) {
+	if role, err := ParseRole("auditor"); err != nil || role != RoleAuditor {
+		t.Errorf("Expected auditor to resolve to Auditor, got %q (%v)", role, err)
+	}
+	if _, err := ParseRole("Reviewer"); err == nil {
+		t.Error("Expected unknown role to be rejected")
+	}
+}
+
+func TestAssumeRole_BindsSessionAndActor(t *testing.T) {
+	tools, fsm, _ := setupTools(t)
+	ctx := context.Background()
+
+	if _, err := tools.AssumeRole("Abductor", ""); err == nil {
+		t.Error("Expected AssumeRole without any session to fail")
+	}
+
+	tools.Session = SessionFromClientInfo("claude-code", "1.0.0")
+	if _, err := tools.AssumeRole("abductor", ""); err != nil {
+		t.Fatalf("AssumeRole failed: %v", err)
+	}
+
+	reloaded, err := LoadState(DefaultContext, fsm.DB)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if reloaded.State.ActiveRole.Role != RoleAbductor || reloaded.State.ActiveRole.SessionID != "claude-code/1.0.0" {
+		t.Errorf("Expected persisted Abductor binding, got %+v", reloaded.State.ActiveRole)
+	}
+
+	if err := tools.EnterPhase("quint_propose", PhaseAbduction, RoleAbductor, tools.phaseAnchor(PhaseAbduction, "")); err != nil {
+		t.Fatalf("EnterPhase as Abductor failed: %v", err)
+	}
+	if _, err := tools.ProposeHypothesis("Role Bound", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+
+	logs, _ := tools.DB.GetAuditLogByTarget(ctx, "role-bound")
+	if len(logs) == 0 || logs[0].Actor != "Abductor@claude-code/1.0.0" {
+		t.Errorf("Expected audit actor Abductor@claude-code/1.0.0, got %+v", logs)
+	}
+
+	var performer string
+	if err := fsm.DB.QueryRow("SELECT performer_ref FROM work_records WHERE method_ref = 'ProposeHypothesis'").Scan(&performer); err != nil {
+		t.Fatalf("failed to read work record: %v", err)
+	}
+	if performer != "Abductor@claude-code/1.0.0" {
+		t.Errorf("Expected work record performer Abductor@claude-code/1.0.0, got %q", performer)
+	}
+
+	// The assumed role is enforced before a tool of another role runs
+	err = tools.EnterPhase("quint_verify", PhaseDeduction, RoleDeductor, tools.phaseAnchor(PhaseDeduction, "role-bound"))
+	if err == nil || !strings.Contains(err.Error(), "active role Abductor cannot act in DEDUCTION") {
+		t.Errorf("Expected Abductor to be refused in DEDUCTION, got %v", err)
+	}
+	if fsm.GetPhase() != PhaseAbduction {
+		t.Errorf("Refused tool must not change the phase, got %s", fsm.GetPhase())
+	}
+
+	if _, err := tools.AssumeRole("Deductor", ""); err != nil {
+		t.Fatalf("Switching own role failed: %v", err)
+	}
+	if err := tools.EnterPhase("quint_verify", PhaseDeduction, RoleDeductor, tools.phaseAnchor(PhaseDeduction, "role-bound")); err != nil {
+		t.Errorf("EnterPhase as Deductor failed: %v", err)
+	}
+}
+
+func TestAssumeRole_HeldByOtherSession(t *testing.T) {
+	tools, _, _ := setupTools(t)
+
+	tools.Session = "session-a"
+	if _, err := tools.AssumeRole("Auditor", ""); err != nil {
+		t.Fatalf("AssumeRole failed: %v", err)
+	}
+
+	tools.Session = "session-b"
+	if _, err := tools.AssumeRole("Decider", ""); err == nil || !strings.Contains(err.Error(), `held by session "session-a"`) {
+		t.Errorf("Expected role held by session-a to block session-b, got %v", err)
+	}
+	for _, label := range []string{"", "session-a"} {
+		if _, err := tools.ReleaseRole(label); err == nil || strings.Contains(err.Error(), "session-a") {
+			t.Errorf("Expected session-b to be unable to release session-a's role (label %q), got %v", label, err)
+		}
+	}
+	if tools.FSM.State.ActiveRole.Role != RoleAuditor {
+		t.Errorf("Expected session-a to keep its role, got %+v", tools.FSM.State.ActiveRole)
+	}
+	if tools.actor() != "session-b" {
+		t.Errorf("Expected session-b to act without a role, got %q", tools.actor())
+	}
+
+	tools.Session = "session-a"
+	if _, err := tools.ReleaseRole(""); err != nil {
+		t.Fatalf("ReleaseRole by the holder failed: %v", err)
+	}
+	tools.Session = "session-b"
+	if _, err := tools.AssumeRole("Decider", ""); err != nil {
+		t.Errorf("Expected role to be free after release, got %v", err)
+	}
+}
@@ -2,9 +2,15 @@ package fpf
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
 type JSONRPCRequest struct {
@@ -21,6 +27,13 @@ type JSONRPCResponse struct {
 	ID      interface{} `json:"id"`
 }
 
+// JSONRPCNotification is a message from the server that expects no response
+type JSONRPCNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
 type RPCError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
@@ -42,76 +55,460 @@ type ContentItem struct {
 	Text string `json:"text"`
 }
 
+// How often the server looks for waivers about to lapse, and how far ahead
+const (
+	waiverCheckInterval = time.Hour
+	waiverExpiryNotice  = 24 * time.Hour
+)
+
+// Protocol versions the server speaks; the last one is offered to clients
+// that ask for another
+var protocolVersions = []string{"2024-11-05", "2025-03-26"}
+
 type Server struct {
-	tools *Tools
+	tools   *Tools
+	prompts []Prompt
+
+	guard    sync.RWMutex                  // Held alone by requests that change state, shared by the rest
+	mu       sync.Mutex                    // Guards sessions and in-flight requests
+	sessions map[string]*session           // Connected clients by session ID
+	inflight map[string]context.CancelFunc // Running requests by requestKey
+	out      sync.Mutex                    // Serializes writes to stdout
+}
+
+// session is one connected client. Requests run as its FPF session, and
+// notifications reach it through deliver.
+type session struct {
+	id         string
+	client     string          // Tools.Session while its requests run
+	fsm        *FSM            // Context the client works in, until it switches
+	subscribed map[string]bool // Resource URIs the client subscribed to
+	deliver    func(msg interface{})
 }
 
 func NewServer(t *Tools) *Server {
-	return &Server{tools: t}
+	s := &Server{
+		tools:    t,
+		sessions: make(map[string]*session),
+		inflight: make(map[string]context.CancelFunc),
+	}
+	t.SetNotifier(s.notify)
+	t.states = &contextStates{fsms: make(map[string]*FSM)}
+	if t.FSM != nil {
+		t.states.fsms[t.contextID()] = t.FSM
+	}
+	return s
+}
+
+// SetPrompts serves prompts over prompts/list and prompts/get
+func (s *Server) SetPrompts(prompts []Prompt) {
+	s.prompts = prompts
 }
 
+// Start serves one client over newline-delimited JSON-RPC on stdio until
+// stdin closes. Each message is handled as soon as it arrives, so a slow
+// tool call does not hold up the ones after it.
 func (s *Server) Start() {
+	done := make(chan struct{})
+	defer close(done)
+	go s.watchWaivers(done)
+
+	sess := s.openSession("stdio", s.write)
+	sess.client = s.tools.Session
+
+	var running sync.WaitGroup
+	defer running.Wait() // Answer what is still running once stdin closes
+
 	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
 	for scanner.Scan() {
 		line := scanner.Bytes()
-		if len(line) == 0 {
+		if len(bytes.TrimSpace(line)) == 0 {
 			continue
 		}
-
-		var req JSONRPCRequest
-		if err := json.Unmarshal(line, &req); err != nil {
-			s.sendError(nil, -32700, "Parse error")
+		reqs, batch, err := parseMessage(line)
+		if err != nil {
+			s.write(rpcError(nil, -32700, "Parse error"))
 			continue
 		}
-
-		switch req.Method {
-		case "initialize":
-			s.handleInitialize(req)
-		case "tools/list":
-			s.handleToolsList(req)
-		case "tools/call":
-			s.handleToolsCall(req)
-		case "notifications/initialized":
-			// No-op
-		default:
-			if req.ID != nil {
-				s.sendError(req.ID, -32601, "Method not found")
+		handle := func() {
+			for _, resp := range s.handleBatch(context.Background(), sess, reqs, batch, s.write) {
+				s.write(resp)
 			}
 		}
+		if reqs[0].Method == "initialize" {
+			handle() // Later requests run as the session it binds
+			continue
+		}
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			handle()
+		}()
+	}
+}
+
+// maxMessageSize bounds one JSON-RPC message or batch
+const maxMessageSize = 4 << 20
+
+// openSession registers a client whose notifications go to deliver
+func (s *Server) openSession(id string, deliver func(msg interface{})) *session {
+	sess := &session{id: id, subscribed: make(map[string]bool), deliver: deliver}
+	s.mu.Lock()
+	sess.fsm = s.tools.FSM
+	s.sessions[id] = sess
+	s.mu.Unlock()
+	return sess
+}
+
+// closeSession forgets a client and cancels its running requests
+func (s *Server) closeSession(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	for key, cancel := range s.inflight {
+		if strings.HasPrefix(key, id+" ") {
+			cancel()
+		}
+	}
+}
+
+// parseMessage splits a JSON-RPC message or batch into its requests
+func parseMessage(data []byte) ([]JSONRPCRequest, bool, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var batch []JSONRPCRequest
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return nil, true, err
+		}
+		if len(batch) == 0 {
+			return nil, true, fmt.Errorf("empty batch")
+		}
+		return batch, true, nil
+	}
+	var req JSONRPCRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, false, err
+	}
+	return []JSONRPCRequest{req}, false, nil
+}
+
+// handleBatch runs requests in order as sess and returns their responses.
+// Notifications and cancelled requests get none; a batch is answered with
+// one JSON array. Progress of the requests goes to send.
+func (s *Server) handleBatch(ctx context.Context, sess *session, reqs []JSONRPCRequest, batch bool, send func(msg interface{})) []interface{} {
+	var resps []*JSONRPCResponse
+	for _, req := range reqs {
+		if resp := s.handle(ctx, sess, req, send); resp != nil {
+			resps = append(resps, resp)
+		}
+	}
+	switch {
+	case len(resps) == 0:
+		return nil
+	case batch:
+		return []interface{}{resps}
+	default:
+		return []interface{}{resps[0]}
 	}
 }
 
-func (s *Server) send(resp JSONRPCResponse) {
-	bytes, err := json.Marshal(resp)
+// handle runs one request with its own Tools, cancelled with ctx or by a
+// notifications/cancelled naming it. Requests that change state run alone;
+// the rest run alongside each other.
+func (s *Server) handle(ctx context.Context, sess *session, req JSONRPCRequest, send func(msg interface{})) *JSONRPCResponse {
+	if req.Method == NotifyCancelled {
+		s.cancelRequest(sess, req)
+		return nil
+	}
+	ctx, done := s.track(ctx, sess, req.ID)
+	defer done()
+
+	exclusive := mutates(req)
+	if exclusive {
+		s.guard.Lock()
+		defer s.guard.Unlock()
+	} else {
+		s.guard.RLock()
+		defer s.guard.RUnlock()
+	}
+	if ctx.Err() != nil {
+		return nil // Cancelled while waiting its turn
+	}
+
+	s.mu.Lock()
+	t := s.tools.WithCall(ctx, progressTo(req, send))
+	t.Session = sess.client
+	if sess.fsm != nil {
+		t.FSM = sess.fsm
+	}
+	t.readOnly = !exclusive
+	s.mu.Unlock()
+
+	resp := s.dispatch(t, sess, req)
+
+	if exclusive {
+		s.mu.Lock()
+		if req.Method == "initialize" {
+			sess.client = t.Session
+		}
+		sess.fsm = t.FSM // quint_switch_context replaces it
+		s.mu.Unlock()
+	}
+	if ctx.Err() != nil {
+		return nil // Nobody waits for the response of a cancelled request
+	}
+	return resp
+}
+
+// readOnlyTools only read state, so they run alongside each other. Their
+// calculators compute R without writing it back to the cache.
+var readOnlyTools = map[string]bool{
+	"quint_status":        true,
+	"quint_list_contexts": true,
+	"quint_audit_tree":    true,
+	"quint_calculate_r":   true,
+	"quint_explain_r":     true,
+}
+
+// mutates reports whether req can change the FSM, the database or the
+// projection. Tools not known to be read-only are assumed to.
+func mutates(req JSONRPCRequest) bool {
+	switch req.Method {
+	case "initialize":
+		return true
+	case "tools/call":
+	default:
+		return false
+	}
+
+	var params struct {
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return true
+	}
+	switch params.Name {
+	case "quint_reconcile":
+		dryRun, _ := params.Arguments["dry_run"].(bool)
+		return !dryRun
+	case "quint_check_decay":
+		deprecate, _ := params.Arguments["deprecate"].(string)
+		waiveID, _ := params.Arguments["waive_id"].(string)
+		recompute, _ := params.Arguments["recompute"].(bool)
+		return deprecate != "" || waiveID != "" || recompute
+	}
+	return !readOnlyTools[params.Name]
+}
+
+// requestKey identifies a request among those running for all sessions
+func requestKey(sessionID string, id interface{}) string {
+	data, _ := json.Marshal(id)
+	return sessionID + " " + string(data)
+}
+
+// track derives the context a request runs with and registers it for
+// cancellation. done must be called once the request finishes.
+func (s *Server) track(ctx context.Context, sess *session, id interface{}) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	if id == nil {
+		return ctx, cancel
+	}
+	key := requestKey(sess.id, id)
+	s.mu.Lock()
+	s.inflight[key] = cancel
+	s.mu.Unlock()
+	return ctx, func() {
+		s.mu.Lock()
+		delete(s.inflight, key)
+		s.mu.Unlock()
+		cancel()
+	}
+}
+
+// cancelRequest stops the request a notifications/cancelled names. Requests
+// already finished or unknown are ignored.
+func (s *Server) cancelRequest(sess *session, req JSONRPCRequest) {
+	var params struct {
+		RequestID interface{} `json:"requestId"`
+		Reason    string      `json:"reason"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.RequestID == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel := s.inflight[requestKey(sess.id, params.RequestID)]; cancel != nil {
+		cancel()
+	}
+}
+
+// progressTo sends the progress of req to send as notifications/progress,
+// if the client asked for it with a progress token
+func progressTo(req JSONRPCRequest, send func(msg interface{})) Progress {
+	var params struct {
+		Meta struct {
+			ProgressToken interface{} `json:"progressToken"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.Meta.ProgressToken == nil || send == nil {
+		return nil
+	}
+	token := params.Meta.ProgressToken
+	return func(done, total int, message string) {
+		send(JSONRPCNotification{
+			JSONRPC: "2.0",
+			Method:  NotifyProgress,
+			Params: map[string]interface{}{
+				"progressToken": token,
+				"progress":      done,
+				"total":         total,
+				"message":       message,
+			},
+		})
+	}
+}
+
+func (s *Server) dispatch(t *Tools, sess *session, req JSONRPCRequest) *JSONRPCResponse {
+	var resp *JSONRPCResponse
+	switch req.Method {
+	case "initialize":
+		resp = s.handleInitialize(t, sess, req)
+	case "ping":
+		resp = result(req.ID, map[string]interface{}{})
+	case "tools/list":
+		resp = s.handleToolsList(req)
+	case "tools/call":
+		resp = s.handleToolsCall(t, req)
+	case "resources/list":
+		resp = s.handleResourcesList(t, req)
+	case "resources/templates/list":
+		resp = s.handleResourceTemplatesList(req)
+	case "resources/read":
+		resp = s.handleResourcesRead(t, req)
+	case "resources/subscribe":
+		resp = s.handleResourcesSubscribe(sess, req, true)
+	case "resources/unsubscribe":
+		resp = s.handleResourcesSubscribe(sess, req, false)
+	case "prompts/list":
+		resp = s.handlePromptsList(req)
+	case "prompts/get":
+		resp = s.handlePromptsGet(req)
+	case "notifications/initialized":
+		// No-op
+	default:
+		resp = rpcError(req.ID, -32601, "Method not found")
+	}
+	if req.ID == nil {
+		return nil // Notifications are never answered
+	}
+	return resp
+}
+
+func (s *Server) write(msg interface{}) {
+	data, err := json.Marshal(msg)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to marshal JSON-RPC response: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: failed to marshal JSON-RPC message: %v\n", err)
 		return
 	}
-	fmt.Printf("%s\n", string(bytes))
+	s.out.Lock()
+	defer s.out.Unlock()
+	fmt.Printf("%s\n", string(data))
+}
+
+// notify sends a change notification to every session. Updates only go to
+// sessions subscribed to the resource.
+func (s *Server) notify(method string, params map[string]interface{}) {
+	msg := JSONRPCNotification{JSONRPC: "2.0", Method: method}
+	if params != nil {
+		msg.Params = params
+	}
+	uri, _ := params["uri"].(string)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sess := range s.sessions {
+		if method == NotifyResourceUpdated && !sess.subscribed[uri] {
+			continue
+		}
+		if sess.deliver != nil {
+			sess.deliver(msg)
+		}
+	}
 }
 
-func (s *Server) sendResult(id interface{}, result interface{}) {
-	s.send(JSONRPCResponse{
+// watchWaivers announces waivers about to lapse until done is closed
+func (s *Server) watchWaivers(done <-chan struct{}) {
+	ticker := time.NewTicker(waiverCheckInterval)
+	defer ticker.Stop()
+
+	notified := make(map[string]bool)
+	for {
+		s.guard.RLock()
+		if err := s.tools.NotifyExpiringWaivers(waiverExpiryNotice, notified); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to check waivers: %v\n", err)
+		}
+		s.guard.RUnlock()
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func result(id interface{}, result interface{}) *JSONRPCResponse {
+	return &JSONRPCResponse{
 		JSONRPC: "2.0",
 		ID:      id,
 		Result:  result,
-	})
+	}
 }
 
-func (s *Server) sendError(id interface{}, code int, message string) {
-	s.send(JSONRPCResponse{
+func rpcError(id interface{}, code int, message string) *JSONRPCResponse {
+	return &JSONRPCResponse{
 		JSONRPC: "2.0",
 		ID:      id,
 		Error:   &RPCError{Code: code, Message: message},
-	})
+	}
 }
 
-func (s *Server) handleInitialize(req JSONRPCRequest) {
-	s.sendResult(req.ID, map[string]interface{}{
-		"protocolVersion": "2024-11-05",
-		"capabilities": map[string]interface{}{
-			"tools": map[string]interface{}{},
-		},
+func (s *Server) handleInitialize(t *Tools, sess *session, req JSONRPCRequest) *JSONRPCResponse {
+	var params struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		ClientInfo      struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"clientInfo"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err == nil && t.Session == "" {
+		t.Session = SessionFromClientInfo(params.ClientInfo.Name, params.ClientInfo.Version)
+		if t.Session != "" && sess.id != "stdio" {
+			// Several clients of one kind can share an HTTP server; each is
+			// its own performer
+			t.Session += "#" + sess.id[:8]
+		}
+	}
+	version := protocolVersions[len(protocolVersions)-1]
+	for _, v := range protocolVersions {
+		if v == params.ProtocolVersion {
+			version = v
+		}
+	}
+
+	capabilities := map[string]interface{}{
+		"tools":     map[string]interface{}{"listChanged": true},
+		"resources": map[string]interface{}{"subscribe": true, "listChanged": true},
+	}
+	if len(s.prompts) > 0 {
+		capabilities["prompts"] = map[string]interface{}{}
+	}
+
+	return result(req.ID, map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities":    capabilities,
 		"serverInfo": map[string]string{
 			"name":    "quint-code",
 			"version": "4.0.0",
@@ -120,11 +517,12 @@ func (s *Server) handleInitialize(req JSONRPCRequest) {
 			"Workflow: quint_init > quint_record_context > quint_propose (hypothesize) > " +
 			"quint_verify (logical checks) > quint_test (empirical validation) > " +
 			"quint_audit (bias/trust) > quint_decide (finalize DRR). " +
-			"Use quint_status to check phase. State lives in .quint/ per project.",
+			"Use quint_status to check phase. State lives in .quint/ per project; " +
+			"holons, evidence, DRRs and the bounded context are also readable as quint:// resources.",
 	})
 }
 
-func (s *Server) handleToolsList(req JSONRPCRequest) {
+func (s *Server) handleToolsList(req JSONRPCRequest) *JSONRPCResponse {
 	tools := []Tool{
 		{
 			Name:        "quint_status",
@@ -134,6 +532,63 @@ func (s *Server) handleToolsList(req JSONRPCRequest) {
 				"properties": map[string]interface{}{},
 			},
 		},
+		{
+			Name:        "quint_create_context",
+			Description: "Create a bounded context with its own phase, holons and audit trail.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"context_id":  map[string]string{"type": "string", "description": "Context ID: lowercase letters, digits, '-' or '_'"},
+					"description": map[string]string{"type": "string", "description": "What the context covers"},
+				},
+				"required": []string{"context_id"},
+			},
+		},
+		{
+			Name:        "quint_list_contexts",
+			Description: "List bounded contexts with their phase, holon counts per layer and cross-context relations.",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "quint_switch_context",
+			Description: "Switch the bounded context of this session. Other tools operate in it unless they are given context_id.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"context_id": map[string]string{"type": "string", "description": "Context to switch to"},
+				},
+				"required": []string{"context_id"},
+			},
+		},
+		{
+			Name:        "quint_assume_role",
+			Description: "Bind this MCP session to an FPF role in the current context. Phase-changing tools then act as that role and are refused outside it.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"role": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"Abductor", "Deductor", "Inductor", "Auditor", "Decider"},
+						"description": "Role to assume",
+					},
+					"session_id": map[string]string{"type": "string", "description": "Label recorded with the assignment; the role is always bound to this MCP session"},
+				},
+				"required": []string{"role"},
+			},
+		},
+		{
+			Name:        "quint_release_role",
+			Description: "Release the role held by this session in the current context.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"session_id": map[string]string{"type": "string", "description": "Label recorded with the release; only the role of this MCP session is released"},
+				},
+			},
+		},
 		{
 			Name:        "quint_init",
 			Description: "Initialize FPF project structure.",
@@ -162,7 +617,7 @@ func (s *Server) handleToolsList(req JSONRPCRequest) {
 				"properties": map[string]interface{}{
 					"title":     map[string]string{"type": "string", "description": "Title"},
 					"content":   map[string]string{"type": "string", "description": "Description"},
-					"scope":     map[string]string{"type": "string", "description": "Scope (G) - where this hypothesis applies"},
+					"scope":     map[string]string{"type": "string", "description": "Scope (G) - where this hypothesis applies. Structured form 'services=payments,billing; env=prod' (or JSON object) is propagated by the calculator; 'global' means unbounded."},
 					"kind":      map[string]interface{}{"type": "string", "enum": []interface{}{"system", "episteme"}, "description": "system=code/architecture, episteme=process/methodology"},
 					"rationale": map[string]string{"type": "string", "description": "JSON: {anomaly, approach, alternatives_rejected}"},
 					"decision_context": map[string]string{
@@ -179,7 +634,14 @@ func (s *Server) handleToolsList(req JSONRPCRequest) {
 						"minimum":     1,
 						"maximum":     3,
 						"default":     3,
-						"description": "Congruence level for dependencies. CL3=same context (no penalty), CL2=similar (10% penalty), CL1=different (30% penalty).",
+						"description": "Congruence level for dependencies. CL3=same context (no penalty), CL2=similar, CL1=different. Penalty Φ(CL) comes from the project's cl_penalty_profile (default: CL2=0.1, CL1=0.4).",
+					},
+					"formality": map[string]interface{}{
+						"type":        "integer",
+						"minimum":     0,
+						"maximum":     9,
+						"default":     0,
+						"description": "Formality (F) of the claim. F0=informal sketch, F3=structured prose, F5=semi-formal spec/typed model, F7=formal spec, F9=machine-checked proof. Propagates via min(F) over dependencies.",
 					},
 				},
 				"required": []string{"title", "content", "scope", "kind", "rationale"},
@@ -194,6 +656,12 @@ func (s *Server) handleToolsList(req JSONRPCRequest) {
 					"hypothesis_id": map[string]string{"type": "string"},
 					"checks_json":   map[string]string{"type": "string", "description": "JSON of checks"},
 					"verdict":       map[string]interface{}{"type": "string", "enum": []interface{}{"PASS", "FAIL", "REFINE"}},
+					"formality": map[string]interface{}{
+						"type":        "integer",
+						"minimum":     0,
+						"maximum":     9,
+						"description": "Optional: updated Formality (F) if verification formalized the claim. Omit to keep the proposed level.",
+					},
 				},
 				"required": []string{"hypothesis_id", "checks_json", "verdict"},
 			},
@@ -220,6 +688,10 @@ func (s *Server) handleToolsList(req JSONRPCRequest) {
 				"properties": map[string]interface{}{
 					"hypothesis_id": map[string]string{"type": "string"},
 					"risks":         map[string]string{"type": "string", "description": "Risk analysis"},
+					"override_rationale": map[string]string{
+						"type":        "string",
+						"description": "Why this session may audit a hypothesis it proposed, verified or tested (honoured when the separation-of-duties mode is override)",
+					},
 				},
 				"required": []string{"hypothesis_id", "risks"},
 			},
@@ -242,6 +714,14 @@ func (s *Server) handleToolsList(req JSONRPCRequest) {
 					"rationale":       map[string]string{"type": "string"},
 					"consequences":    map[string]string{"type": "string"},
 					"characteristics": map[string]string{"type": "string"},
+					"scope": map[string]string{
+						"type":        "string",
+						"description": "Scope (G) the decision must hold for, e.g. 'services=payments,billing; env=prod'. Defaults to the scope of the winner's decision_context. A warning is returned if the winner's effective scope is narrower.",
+					},
+					"override_rationale": map[string]string{
+						"type":        "string",
+						"description": "Why this session may decide on a winner it proposed or audited (honoured when the separation-of-duties mode is override)",
+					},
 				},
 				"required": []string{"title", "winner_id", "context", "decision", "rationale", "consequences"},
 			},
@@ -254,9 +734,27 @@ func (s *Server) handleToolsList(req JSONRPCRequest) {
 				"properties": map[string]interface{}{},
 			},
 		},
+		{
+			Name:        "quint_reconcile",
+			Description: "Diff the .quint markdown (knowledge, evidence, decisions) against the database. from=markdown rebuilds the database from the files (e.g. after a git merge), from=db rebuilds the files from the database. Without from, or with dry_run, only reports the differences.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"from": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{ReconcileFromMarkdown, ReconcileFromDB},
+						"description": "Source of truth to rebuild the other side from",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Report what would change without applying it",
+					},
+				},
+			},
+		},
 		{
 			Name:        "quint_audit_tree",
-			Description: "Visualize the assurance tree for a holon, showing R scores, dependencies, and CL penalties.",
+			Description: "Visualize the assurance tree for a holon, showing R and F scores, dependencies, and CL penalties.",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -267,21 +765,38 @@ func (s *Server) handleToolsList(req JSONRPCRequest) {
 		},
 		{
 			Name:        "quint_calculate_r",
-			Description: "Calculate the effective reliability (R_eff) for a holon with detailed breakdown.",
+			Description: "Calculate the effective reliability (R_eff) and formality (F_eff) for a holon with detailed breakdown. Reports when evidence decay is projected to take R_eff below the assurance threshold.",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
 					"holon_id": map[string]string{"type": "string", "description": "ID of the holon"},
+					"as_of":    map[string]string{"type": "string", "description": "Evaluate evidence decay at this date instead of now: YYYY-MM-DD, RFC3339, or an offset like +90d. Projections are not cached."},
+				},
+				"required": []string{"holon_id"},
+			},
+		},
+		{
+			Name:        "quint_explain_r",
+			Description: "Explain R_eff for a holon as structured JSON: each evidence item with raw score, decay weight and waiver, each dependency edge with CL, penalty and the child's R_eff, and the weakest link path.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"holon_id": map[string]string{"type": "string", "description": "ID of the holon"},
+					"as_of":    map[string]string{"type": "string", "description": "Evaluate evidence decay at this date instead of now: YYYY-MM-DD, RFC3339, or an offset like +90d"},
 				},
 				"required": []string{"holon_id"},
 			},
 		},
 		{
 			Name:        "quint_check_decay",
-			Description: "Check evidence freshness and manage stale decisions. Without parameters: shows freshness report. With deprecate: downgrades hypothesis. With waive: records temporary risk acceptance.",
+			Description: "Check evidence freshness and manage stale decisions. Without parameters: shows freshness report. With recompute: recomputes every cached R at today's date first, reporting progress. With deprecate: downgrades hypothesis. With waive: records temporary risk acceptance.",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
+					"recompute": map[string]string{
+						"type":        "boolean",
+						"description": "Recompute and cache R for every holon before the freshness report",
+					},
 					"deprecate": map[string]string{
 						"type":        "string",
 						"description": "Hypothesis ID to deprecate (L2→L1 or L1→L0)",
@@ -303,19 +818,78 @@ func (s *Server) handleToolsList(req JSONRPCRequest) {
 		},
 	}
 
-	s.sendResult(req.ID, map[string]interface{}{
+	for _, tool := range tools {
+		if !managesContexts(tool.Name) {
+			addContextArg(tool.InputSchema)
+		}
+	}
+
+	return result(req.ID, map[string]interface{}{
 		"tools": tools,
 	})
 }
 
-func (s *Server) handleToolsCall(req JSONRPCRequest) {
+// managesContexts reports whether a tool selects contexts itself and so
+// takes no per-call context_id override
+func managesContexts(name string) bool {
+	switch name {
+	case "quint_create_context", "quint_list_contexts", "quint_switch_context":
+		return true
+	}
+	return false
+}
+
+// addContextArg adds the optional context_id property to a tool schema
+func addContextArg(schema interface{}) {
+	m, ok := schema.(map[string]interface{})
+	if !ok {
+		return
+	}
+	props, ok := m["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	props["context_id"] = map[string]string{
+		"type":        "string",
+		"description": "Run in this bounded context instead of the active one",
+	}
+}
+
+// holonArgs are the tool arguments that name holons, and holonListArgs the
+// ones that list them
+var (
+	holonArgs     = []string{"hypothesis_id", "winner_id", "holon_id", "decision_context", "deprecate"}
+	holonListArgs = []string{"depends_on", "rejected_ids"}
+)
+
+// resolveHolonArgs replaces the holon references among args with the IDs
+// they name in the context the call runs in
+func resolveHolonArgs(t *Tools, args map[string]interface{}) {
+	for _, k := range holonArgs {
+		if ref, ok := args[k].(string); ok {
+			args[k] = t.resolveID(ref)
+		}
+	}
+	for _, k := range holonListArgs {
+		refs, ok := args[k].([]interface{})
+		if !ok {
+			continue
+		}
+		for i, r := range refs {
+			if ref, ok := r.(string); ok {
+				refs[i] = t.resolveID(ref)
+			}
+		}
+	}
+}
+
+func (s *Server) handleToolsCall(t *Tools, req JSONRPCRequest) *JSONRPCResponse {
 	var params struct {
 		Name      string                 `json:"name"`
 		Arguments map[string]interface{} `json:"arguments"`
 	}
 	if err := json.Unmarshal(req.Params, &params); err != nil {
-		s.sendError(req.ID, -32700, "Invalid params")
-		return
+		return rpcError(req.ID, -32700, "Invalid params")
 	}
 
 	arg := func(k string) string {
@@ -325,6 +899,18 @@ func (s *Server) handleToolsCall(req JSONRPCRequest) {
 		return ""
 	}
 
+	if contextID := arg("context_id"); contextID != "" && !managesContexts(params.Name) {
+		restore, ctxErr := t.UseContext(contextID)
+		if ctxErr != nil {
+			return result(req.ID, CallToolResult{
+				Content: []ContentItem{{Type: "text", Text: ctxErr.Error()}},
+				IsError: true,
+			})
+		}
+		defer restore()
+	}
+	resolveHolonArgs(t, params.Arguments)
+
 	args := make(map[string]string)
 	for k, v := range params.Arguments {
 		if s, ok := v.(string); ok {
@@ -332,13 +918,20 @@ func (s *Server) handleToolsCall(req JSONRPCRequest) {
 		}
 	}
 
-	if precondErr := s.tools.CheckPreconditions(params.Name, args); precondErr != nil {
-		s.tools.AuditLog(params.Name, "precondition_failed", "agent", "", "BLOCKED", args, precondErr.Error())
-		s.sendResult(req.ID, CallToolResult{
+	if precondErr := t.CheckPreconditions(params.Name, args); precondErr != nil {
+		t.AuditLog(params.Name, "precondition_failed", t.actor(), "", "BLOCKED", args, precondErr.Error())
+		return result(req.ID, CallToolResult{
 			Content: []ContentItem{{Type: "text", Text: precondErr.Error()}},
 			IsError: true,
 		})
-		return
+	}
+
+	notice, dutyErr := t.CheckDuties(params.Name, args)
+	if dutyErr != nil {
+		return result(req.ID, CallToolResult{
+			Content: []ContentItem{{Type: "text", Text: dutyErr.Error()}},
+			IsError: true,
+		})
 	}
 
 	var output string
@@ -346,32 +939,46 @@ func (s *Server) handleToolsCall(req JSONRPCRequest) {
 
 	switch params.Name {
 	case "quint_status":
-		st := s.tools.FSM.State.Phase
-		output = string(st)
+		st := t.FSM.GetPhase()
+		output = fmt.Sprintf("%s (context: %s)", st, t.contextID())
+		if active := t.FSM.State.ActiveRole; active.Role != "" {
+			output += fmt.Sprintf("\nActive role: %s (session %s)", active.Role, active.SessionID)
+		}
+
+	case "quint_assume_role":
+		output, err = t.AssumeRole(arg("role"), arg("session_id"))
+
+	case "quint_release_role":
+		output, err = t.ReleaseRole(arg("session_id"))
+
+	case "quint_create_context":
+		output, err = t.CreateContext(arg("context_id"), arg("description"))
+
+	case "quint_list_contexts":
+		output, err = t.ListContexts()
+
+	case "quint_switch_context":
+		output, err = t.SwitchContext(arg("context_id"))
 
 	case "quint_init":
-		res := s.tools.InitProject()
+		res := t.InitProject()
 		if res != nil {
 			err = res
 		} else {
-			s.tools.FSM.State.Phase = PhaseAbduction
-			if saveErr := s.tools.FSM.SaveState("default"); saveErr != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to save state: %v\n", saveErr)
-			}
-			output = "Initialized. Phase: ABDUCTION"
+			output = fmt.Sprintf("Initialized. Phase: %s", t.FSM.GetPhase())
 		}
 
 	case "quint_actualize":
-		output, err = s.tools.Actualize()
+		output, err = t.Actualize()
+
+	case "quint_reconcile":
+		dryRun, _ := params.Arguments["dry_run"].(bool)
+		output, err = t.Reconcile(arg("from"), dryRun)
 
 	case "quint_record_context":
-		output, err = s.tools.RecordContext(arg("vocabulary"), arg("invariants"))
+		output, err = t.RecordContext(arg("vocabulary"), arg("invariants"))
 
 	case "quint_propose":
-		s.tools.FSM.State.Phase = PhaseAbduction
-		if saveErr := s.tools.FSM.SaveState("default"); saveErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to save state: %v\n", saveErr)
-		}
 		decisionContext := arg("decision_context")
 		var dependsOn []string
 		if deps, ok := params.Arguments["depends_on"].([]interface{}); ok {
@@ -385,33 +992,43 @@ func (s *Server) handleToolsCall(req JSONRPCRequest) {
 		if cl, ok := params.Arguments["dependency_cl"].(float64); ok {
 			dependencyCL = int(cl)
 		}
-		output, err = s.tools.ProposeHypothesis(arg("title"), arg("content"), arg("scope"), arg("kind"), arg("rationale"), decisionContext, dependsOn, dependencyCL)
+		formality := 0
+		if f, ok := params.Arguments["formality"].(float64); ok {
+			formality = int(f)
+		}
+		err = t.inPhase(params.Name, PhaseAbduction, RoleAbductor, t.phaseAnchor(PhaseAbduction, ""), func() (err error) {
+			output, err = t.ProposeHypothesis(arg("title"), arg("content"), arg("scope"), arg("kind"), arg("rationale"), decisionContext, dependsOn, dependencyCL, formality)
+			return err
+		})
 
 	case "quint_verify":
-		s.tools.FSM.State.Phase = PhaseDeduction
-		if saveErr := s.tools.FSM.SaveState("default"); saveErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to save state: %v\n", saveErr)
+		formality := -1
+		if f, ok := params.Arguments["formality"].(float64); ok {
+			formality = int(f)
 		}
-		output, err = s.tools.VerifyHypothesis(arg("hypothesis_id"), arg("checks_json"), arg("verdict"))
+		err = t.inPhase(params.Name, PhaseDeduction, RoleDeductor, t.phaseAnchor(PhaseDeduction, arg("hypothesis_id")), func() (err error) {
+			output, err = t.VerifyHypothesis(arg("hypothesis_id"), arg("checks_json"), arg("verdict"), formality)
+			return err
+		})
 
 	case "quint_test":
-		s.tools.FSM.State.Phase = PhaseInduction
-		if saveErr := s.tools.FSM.SaveState("default"); saveErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to save state: %v\n", saveErr)
-		}
-
 		assLevel := "L2"
 		if arg("verdict") != "PASS" {
 			assLevel = "L1"
 		}
 
-		output, err = s.tools.ManageEvidence(PhaseInduction, "add", arg("hypothesis_id"), arg("test_type"), arg("result"), arg("verdict"), assLevel, "test-runner", "")
+		err = t.inPhase(params.Name, PhaseInduction, RoleInductor, t.phaseAnchor(PhaseInduction, arg("hypothesis_id")), func() (err error) {
+			output, err = t.ManageEvidence(PhaseInduction, "add", arg("hypothesis_id"), arg("test_type"), arg("result"), arg("verdict"), assLevel, "test-runner", "")
+			return err
+		})
 
 	case "quint_audit":
-		output, err = s.tools.AuditEvidence(arg("hypothesis_id"), arg("risks"))
+		err = t.inPhase(params.Name, PhaseAudit, RoleAuditor, t.phaseAnchor(PhaseAudit, arg("hypothesis_id")), func() (err error) {
+			output, err = t.AuditEvidence(arg("hypothesis_id"), arg("risks"))
+			return err
+		})
 
 	case "quint_decide":
-		s.tools.FSM.State.Phase = PhaseDecision
 		var rejectedIDs []string
 		if rids, ok := params.Arguments["rejected_ids"].([]interface{}); ok {
 			for _, r := range rids {
@@ -420,35 +1037,159 @@ func (s *Server) handleToolsCall(req JSONRPCRequest) {
 				}
 			}
 		}
-		output, err = s.tools.FinalizeDecision(arg("title"), arg("winner_id"), rejectedIDs, arg("context"), arg("decision"), arg("rationale"), arg("consequences"), arg("characteristics"))
+		err = t.inPhase(params.Name, PhaseDecision, RoleDecider, t.phaseAnchor(PhaseDecision, arg("winner_id")), func() (err error) {
+			output, err = t.FinalizeDecision(arg("title"), arg("winner_id"), rejectedIDs, arg("context"), arg("decision"), arg("rationale"), arg("consequences"), arg("characteristics"), arg("scope"))
+			if err != nil {
+				return err
+			}
+			if err := t.EnterPhase(params.Name, PhaseIdle, RoleDecider, t.phaseAnchor(PhaseIdle, arg("winner_id"))); err != nil {
+				return fmt.Errorf("failed to close decision phase: %w", err)
+			}
+			return nil
+		})
 		if err == nil {
-			s.tools.FSM.State.Phase = PhaseIdle
-			if saveErr := s.tools.FSM.SaveState("default"); saveErr != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to save state: %v\n", saveErr)
+			if _, warning := t.CheckDecisionScope(arg("winner_id"), arg("scope")); warning != "" {
+				output += "\n\n" + warning
 			}
 		}
 
 	case "quint_audit_tree":
-		output, err = s.tools.VisualizeAudit(arg("holon_id"))
+		output, err = t.VisualizeAudit(arg("holon_id"))
 
 	case "quint_calculate_r":
-		output, err = s.tools.CalculateR(arg("holon_id"))
+		output, err = t.CalculateR(arg("holon_id"), arg("as_of"))
+
+	case "quint_explain_r":
+		output, err = t.ExplainR(arg("holon_id"), arg("as_of"))
 
 	case "quint_check_decay":
-		output, err = s.tools.CheckDecay(arg("deprecate"), arg("waive_id"), arg("waive_until"), arg("waive_rationale"))
+		recomputed := -1
+		if recompute, _ := params.Arguments["recompute"].(bool); recompute {
+			if recomputed, err = t.RunDecay(); err != nil {
+				break
+			}
+		}
+		output, err = t.CheckDecay(arg("deprecate"), arg("waive_id"), arg("waive_until"), arg("waive_rationale"))
+		if recomputed >= 0 {
+			output = fmt.Sprintf("CACHE: Recomputed R for %d holons.\n\n%s", recomputed, output)
+		}
 
 	default:
 		err = fmt.Errorf("unknown tool: %s", params.Name)
 	}
 
+	if err == nil && notice != "" {
+		output += "\n\n" + notice
+	}
+
 	if err != nil {
-		s.sendResult(req.ID, CallToolResult{
+		return result(req.ID, CallToolResult{
 			Content: []ContentItem{{Type: "text", Text: err.Error()}},
 			IsError: true,
 		})
 	} else {
-		s.sendResult(req.ID, CallToolResult{
+		return result(req.ID, CallToolResult{
 			Content: []ContentItem{{Type: "text", Text: output}},
 		})
 	}
 }
+
+func (s *Server) handleResourcesList(t *Tools, req JSONRPCRequest) *JSONRPCResponse {
+	resources, err := t.ListResources()
+	if err != nil {
+		return rpcError(req.ID, -32603, err.Error())
+	}
+	if resources == nil {
+		resources = []Resource{}
+	}
+	return result(req.ID, map[string]interface{}{
+		"resources": resources,
+	})
+}
+
+func (s *Server) handleResourceTemplatesList(req JSONRPCRequest) *JSONRPCResponse {
+	return result(req.ID, map[string]interface{}{
+		"resourceTemplates": ResourceTemplates(),
+	})
+}
+
+func (s *Server) handleResourcesRead(t *Tools, req JSONRPCRequest) *JSONRPCResponse {
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+		return rpcError(req.ID, -32602, "Invalid params: uri is required")
+	}
+
+	contents, err := t.ReadResource(params.URI)
+	if errors.Is(err, ErrResourceNotFound) {
+		return rpcError(req.ID, -32002, err.Error())
+	}
+	if err != nil {
+		return rpcError(req.ID, -32603, err.Error())
+	}
+	return result(req.ID, map[string]interface{}{
+		"contents": []ResourceContents{contents},
+	})
+}
+
+// handleResourcesSubscribe starts or stops resources/updated notifications
+// for a URI. Any URI ReadResource accepts can be subscribed to, including
+// ones that do not exist yet.
+func (s *Server) handleResourcesSubscribe(sess *session, req JSONRPCRequest, subscribe bool) *JSONRPCResponse {
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+		return rpcError(req.ID, -32602, "Invalid params: uri is required")
+	}
+	if _, _, err := ParseResourceURI(params.URI); err != nil {
+		return rpcError(req.ID, -32602, err.Error())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if subscribe {
+		sess.subscribed[params.URI] = true
+	} else {
+		delete(sess.subscribed, params.URI)
+	}
+	return result(req.ID, map[string]interface{}{})
+}
+
+func (s *Server) handlePromptsList(req JSONRPCRequest) *JSONRPCResponse {
+	prompts := s.prompts
+	if prompts == nil {
+		prompts = []Prompt{}
+	}
+	return result(req.ID, map[string]interface{}{
+		"prompts": prompts,
+	})
+}
+
+func (s *Server) handlePromptsGet(req JSONRPCRequest) *JSONRPCResponse {
+	var params struct {
+		Name      string            `json:"name"`
+		Arguments map[string]string `json:"arguments"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.Name == "" {
+		return rpcError(req.ID, -32602, "Invalid params: name is required")
+	}
+
+	for _, p := range s.prompts {
+		if p.Name != params.Name {
+			continue
+		}
+		text, err := p.Render(params.Arguments)
+		if err != nil {
+			return rpcError(req.ID, -32602, err.Error())
+		}
+		return result(req.ID, map[string]interface{}{
+			"description": p.Description,
+			"messages": []map[string]interface{}{
+				{"role": "user", "content": ContentItem{Type: "text", Text: text}},
+			},
+		})
+	}
+	return rpcError(req.ID, -32602, fmt.Sprintf("Unknown prompt: %s", params.Name))
+}
@@ -0,0 +1,227 @@
+package fpf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func toolCall(id interface{}, name string, args string) JSONRPCRequest {
+	return JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      id,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"` + name + `","arguments":` + args + `}`),
+	}
+}
+
+func TestMutates(t *testing.T) {
+	cases := []struct {
+		req  JSONRPCRequest
+		want bool
+	}{
+		{JSONRPCRequest{Method: "initialize"}, true},
+		{JSONRPCRequest{Method: "resources/read"}, false},
+		{toolCall(1, "quint_status", `{}`), false},
+		{toolCall(1, "quint_calculate_r", `{"holon_id":"a"}`), false},
+		{toolCall(1, "quint_propose", `{"title":"a"}`), true},
+		{toolCall(1, "quint_reconcile", `{"dry_run":true}`), false},
+		{toolCall(1, "quint_reconcile", `{}`), true},
+		{toolCall(1, "quint_check_decay", `{}`), false},
+		{toolCall(1, "quint_check_decay", `{"waive_id":"e1"}`), true},
+		{toolCall(1, "quint_check_decay", `{"recompute":true}`), true},
+		{toolCall(1, "quint_new_tool", `{}`), true},
+	}
+	for _, c := range cases {
+		if got := mutates(c.req); got != c.want {
+			t.Errorf("mutates(%s %s) = %v, want %v", c.req.Method, c.req.Params, got, c.want)
+		}
+	}
+}
+
+func TestServer_ReadsRunAlongsideEachOther(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	s := NewServer(tools)
+	sess := s.openSession("test", nil)
+
+	s.guard.RLock() // A read in progress
+	defer s.guard.RUnlock()
+
+	answered := make(chan *JSONRPCResponse, 1)
+	go func() {
+		answered <- s.handle(context.Background(), sess, toolCall(1, "quint_status", `{}`), nil)
+	}()
+	select {
+	case resp := <-answered:
+		if resp == nil || resp.Error != nil {
+			t.Errorf("Expected quint_status to succeed, got %+v", resp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("quint_status waited for another read to finish")
+	}
+}
+
+func TestServer_ReadsLeaveTheCacheAlone(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	s := NewServer(tools)
+	sess := s.openSession("test", nil)
+	ctx := context.Background()
+	if err := tools.DB.CreateHolon(ctx, "uncached", "hypothesis", "system", "L1", "Uncached", "Content", "default", "global", ""); err != nil {
+		t.Fatalf("CreateHolon failed: %v", err)
+	}
+
+	for _, name := range []string{"quint_calculate_r", "quint_explain_r", "quint_audit_tree"} {
+		callTool(t, s, sess, name, `{"holon_id":"uncached"}`)
+	}
+	holon, err := tools.DB.GetHolon(ctx, "uncached")
+	if err != nil {
+		t.Fatalf("GetHolon failed: %v", err)
+	}
+	if holon.RComputedAt.Valid {
+		t.Error("Expected read-only tools not to write the cached R")
+	}
+}
+
+func TestServer_CancelledRequestIsNotAnswered(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	s := NewServer(tools)
+	sess := s.openSession("test", nil)
+
+	s.guard.Lock() // A mutation in progress
+	answered := make(chan *JSONRPCResponse, 1)
+	go func() {
+		answered <- s.handle(context.Background(), sess, toolCall(7, "quint_status", `{}`), nil)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s.mu.Lock()
+		running := len(s.inflight)
+		s.mu.Unlock()
+		if running == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("The request was never registered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel := JSONRPCRequest{JSONRPC: "2.0", Method: NotifyCancelled, Params: json.RawMessage(`{"requestId":7,"reason":"user gave up"}`)}
+	if resp := s.handle(context.Background(), sess, cancel, nil); resp != nil {
+		t.Errorf("Expected no response to a notification, got %+v", resp)
+	}
+	s.guard.Unlock()
+
+	if resp := <-answered; resp != nil {
+		t.Errorf("Expected the cancelled request to go unanswered, got %+v", resp)
+	}
+	if len(s.inflight) != 0 {
+		t.Errorf("Expected the finished request to be forgotten, %d still tracked", len(s.inflight))
+	}
+}
+
+func TestServer_ReportsProgress(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	s := NewServer(tools)
+	sess := s.openSession("test", nil)
+
+	var progress []string
+	send := func(msg interface{}) {
+		n, ok := msg.(JSONRPCNotification)
+		if !ok || n.Method != NotifyProgress {
+			return
+		}
+		params := n.Params.(map[string]interface{})
+		if params["progressToken"] != "tok" {
+			t.Errorf("Expected the client's progress token, got %v", params["progressToken"])
+		}
+		progress = append(progress, params["message"].(string))
+	}
+
+	req := toolCall(1, "quint_actualize", `{}`)
+	req.Params = json.RawMessage(`{"name":"quint_actualize","arguments":{},"_meta":{"progressToken":"tok"}}`)
+	resp := s.handle(context.Background(), sess, req, send)
+	if resp == nil || resp.Error != nil {
+		t.Fatalf("Expected quint_actualize to succeed, got %+v", resp)
+	}
+	if got := strings.Join(progress, ", "); got != "Reconciling with git, Refreshing stale R scores, Validating projection files, Actualized" {
+		t.Errorf("Unexpected progress: %s", got)
+	}
+
+	progress = nil
+	if resp := s.handle(context.Background(), sess, toolCall(2, "quint_actualize", `{}`), send); resp == nil {
+		t.Fatal("Expected quint_actualize to be answered")
+	}
+	if len(progress) != 0 {
+		t.Errorf("Expected no progress without a progress token, got %v", progress)
+	}
+}
+
+func TestServer_RecomputeReportsProgress(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	s := NewServer(tools)
+	sess := s.openSession("test", nil)
+	for _, id := range []string{"first", "second"} {
+		if err := tools.DB.CreateHolon(context.Background(), id, "hypothesis", "system", "L1", id, "Content", "default", "global", ""); err != nil {
+			t.Fatalf("CreateHolon failed: %v", err)
+		}
+	}
+
+	var progress []string
+	send := func(msg interface{}) {
+		if n, ok := msg.(JSONRPCNotification); ok && n.Method == NotifyProgress {
+			params := n.Params.(map[string]interface{})
+			progress = append(progress, fmt.Sprintf("%v/%v %v", params["progress"], params["total"], params["message"]))
+		}
+	}
+	req := toolCall(1, "quint_check_decay", `{}`)
+	req.Params = json.RawMessage(`{"name":"quint_check_decay","arguments":{"recompute":true},"_meta":{"progressToken":"tok"}}`)
+	resp := s.handle(context.Background(), sess, req, send)
+	if resp == nil || resp.Error != nil {
+		t.Fatalf("Expected quint_check_decay to succeed, got %+v", resp)
+	}
+	if text := resp.Result.(CallToolResult).Content[0].Text; !strings.HasPrefix(text, "CACHE: Recomputed R for 2 holons.") {
+		t.Errorf("Expected the recompute in the output, got %s", text)
+	}
+	if got := strings.Join(progress, ", "); got != "1/2 Recomputing R scores, 2/2 Recomputing R scores" {
+		t.Errorf("Unexpected progress: %s", got)
+	}
+}
+
+// callTool runs a tools/call through the server and returns its text,
+// failing the test when the call fails
+func callTool(t *testing.T, s *Server, sess *session, name, args string) string {
+	t.Helper()
+	resp := s.handle(context.Background(), sess, toolCall(1, name, args), nil)
+	if resp == nil || resp.Error != nil {
+		t.Fatalf("%s: unexpected response %+v", name, resp)
+	}
+	res := resp.Result.(CallToolResult)
+	if res.IsError {
+		t.Fatalf("%s failed: %s", name, res.Content[0].Text)
+	}
+	return res.Content[0].Text
+}
+
+func TestServer_DecideClosesThePhase(t *testing.T) {
+	tools, fsm, _ := setupTools(t)
+	s := NewServer(tools)
+	sess := s.openSession("test", nil)
+
+	callTool(t, s, sess, "quint_propose", `{"title":"Cache Layer","content":"Content","scope":"global","kind":"system","rationale":"R"}`)
+	callTool(t, s, sess, "quint_verify", `{"hypothesis_id":"cache-layer","checks_json":"{}","verdict":"PASS"}`)
+	callTool(t, s, sess, "quint_test", `{"hypothesis_id":"cache-layer","test_type":"internal","result":"Load test passed","verdict":"PASS"}`)
+	callTool(t, s, sess, "quint_decide", `{"title":"Use a cache","winner_id":"cache-layer","context":"C","decision":"D","rationale":"R","consequences":"Q"}`)
+
+	reloaded, err := LoadState(DefaultContext, fsm.DB)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if s.tools.FSM.GetPhase() != PhaseIdle || reloaded.GetPhase() != PhaseIdle {
+		t.Errorf("Expected the decision to close the cycle, got %s in memory and %s saved", s.tools.FSM.GetPhase(), reloaded.GetPhase())
+	}
+}
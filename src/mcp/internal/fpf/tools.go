@@ -3,6 +3,7 @@ package fpf
 import (
 	"context"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -25,8 +27,20 @@ type Tools struct {
 	FSM     *FSM
 	RootDir string
 	DB      *db.Store
+	Session string // MCP session the server bound at initialize; empty outside a session
+
+	uow      *unitOfWork     // unit of work of the tool call in progress, if any
+	notify   Notifier        // receives change notifications, if set
+	ctx      context.Context // cancels the tool call in progress, if set
+	progress Progress        // receives progress of the tool call in progress, if set
+	readOnly bool            // the call runs alongside other reads and must not write the R cache
+	states   *contextStates  // FSMs shared by the sessions of a server; nil loads contexts afresh
 }
 
+// Progress receives how far a long operation got: done of total steps, with
+// a short message
+type Progress func(done, total int, message string)
+
 func NewTools(fsm *FSM, rootDir string, database *db.Store) *Tools {
 	if database == nil {
 		dbPath := filepath.Join(rootDir, ".quint", "quint.db")
@@ -37,6 +51,14 @@ func NewTools(fsm *FSM, rootDir string, database *db.Store) *Tools {
 		}
 	}
 
+	if database != nil {
+		key, err := LoadAuditSigningKey(filepath.Join(rootDir, ".quint"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: audit entries will not be signed: %v\n", err)
+		}
+		database.SetAuditKey(key)
+	}
+
 	return &Tools{
 		FSM:     fsm,
 		RootDir: rootDir,
@@ -44,10 +66,186 @@ func NewTools(fsm *FSM, rootDir string, database *db.Store) *Tools {
 	}
 }
 
+// WithCall returns a copy of t for a single tool call that is cancelled with
+// ctx and reports progress to progress. The copy shares the FSM, database and
+// notifier, so calls that change them must not run concurrently.
+func (t *Tools) WithCall(ctx context.Context, progress Progress) *Tools {
+	call := *t
+	call.ctx = ctx
+	call.progress = progress
+	return &call
+}
+
+// callContext is the context of the tool call in progress
+func (t *Tools) callContext() context.Context {
+	if t.ctx == nil {
+		return context.Background()
+	}
+	return t.ctx
+}
+
+// reportProgress tells the caller how far the tool call got, if it asked
+func (t *Tools) reportProgress(done, total int, message string) {
+	if t.progress != nil {
+		t.progress(done, total, message)
+	}
+}
+
 func (t *Tools) GetFPFDir() string {
 	return filepath.Join(t.RootDir, ".quint")
 }
 
+// newCalculator returns a calculator using the context's configured Φ(CL),
+// evidence decay and evidence aggregation. A read-only call leaves the
+// cache alone, and so does a unit of work: the calculator reads the
+// connection, which the transaction locks against writes.
+func (t *Tools) newCalculator() *assurance.Calculator {
+	calc := assurance.New(t.DB.GetRawDB())
+	calc.NoCache = t.readOnly || t.uow != nil
+	if t.FSM != nil {
+		calc.Penalty = t.FSM.PenaltyProfile()
+		calc.Decay = t.FSM.DecayPolicy()
+		calc.Aggregation = t.FSM.AggregationPolicy()
+	}
+	return calc
+}
+
+// phaseTools names the tool that moves the FSM into each phase
+var phaseTools = map[Phase]string{
+	PhaseAbduction: "quint_propose",
+	PhaseDeduction: "quint_verify",
+	PhaseInduction: "quint_test",
+	PhaseAudit:     "quint_audit",
+	PhaseDecision:  "quint_decide",
+	PhaseIdle:      "quint_decide",
+}
+
+// EnterPhase moves the context's FSM to target on behalf of tool. The move
+// must match a transition rule for the acting role (the session's assumed
+// role, else role); staying in the current phase only requires the role to
+// be active in it. The new phase is persisted.
+func (t *Tools) EnterPhase(tool string, target Phase, role Role, evidence *EvidenceStub) error {
+	transition, err := t.checkPhase(tool, target, role, evidence)
+	if err != nil || transition == nil {
+		return err
+	}
+	return t.savePhase(tool, target, transition)
+}
+
+// inPhase runs op in the target phase. The move is checked first, then saved
+// in the same unit of work as op, so a failing op leaves the phase unchanged.
+func (t *Tools) inPhase(tool string, target Phase, role Role, evidence *EvidenceStub, op func() error) error {
+	transition, err := t.checkPhase(tool, target, role, evidence)
+	if err != nil {
+		return err
+	}
+	return t.atomically(func(uow *unitOfWork) error {
+		if transition != nil {
+			if err := t.savePhase(tool, target, transition); err != nil {
+				return err
+			}
+		}
+		return op()
+	})
+}
+
+// checkPhase verifies that the acting role may move the FSM to target and
+// returns the transition to record, or nil when the FSM is already there
+func (t *Tools) checkPhase(tool string, target Phase, role Role, evidence *EvidenceStub) (map[string]string, error) {
+	role, err := t.actingRole(tool, target, role)
+	if err != nil {
+		t.AuditLog(tool, "phase_transition", t.actor(), "", "BLOCKED", map[string]string{"to": string(target)}, err.Error())
+		return nil, err
+	}
+
+	from := t.FSM.GetPhase()
+	transition := map[string]string{"from": string(from), "to": string(target), "role": string(role)}
+	assignment := RoleAssignment{Role: role, SessionID: t.FSM.State.ActiveRole.SessionID, Context: t.contextID()}
+
+	if ok, reason := t.FSM.CanTransition(target, assignment, evidence); !ok {
+		var targetID string
+		if evidence != nil {
+			targetID = evidence.HolonID
+		}
+		t.AuditLog(tool, "phase_transition", t.actor(), targetID, "BLOCKED", transition, reason)
+		return nil, &PreconditionError{Tool: tool, Condition: reason, Suggestion: nextSteps(from)}
+	}
+	if from == target {
+		return nil, nil
+	}
+	return transition, nil
+}
+
+// savePhase moves the FSM to target and persists it, inside the unit of work
+// in progress if there is one
+func (t *Tools) savePhase(tool string, target Phase, transition map[string]string) error {
+	fsm, previous := t.FSM, t.FSM.State.Phase
+	fsm.State.Phase = target
+	if t.FSM.DB != nil {
+		if err := t.saveState(); err != nil {
+			fsm.State.Phase = previous
+			return err
+		}
+	}
+	if t.uow != nil {
+		t.uow.onRollback(func() { fsm.State.Phase = previous })
+	}
+	t.AuditLog(tool, "phase_transition", t.actor(), "", "SUCCESS", transition, "")
+	t.toolListChanged()
+	return nil
+}
+
+// saveState persists the FSM state through the transaction of the unit of
+// work in progress, or directly outside one
+func (t *Tools) saveState() error {
+	if t.uow != nil && t.DB != nil {
+		return t.FSM.SaveStateWith(t.callContext(), t.DB.DBTX(), t.contextID())
+	}
+	return t.FSM.SaveState(t.contextID())
+}
+
+// nextSteps tells the agent which tools are legal from a phase
+func nextSteps(from Phase) string {
+	var steps []string
+	if tool, ok := phaseTools[from]; ok && from != PhaseIdle {
+		steps = append(steps, fmt.Sprintf("call %s to continue in %s", tool, from))
+	}
+	for _, rule := range AllowedTransitions(from) {
+		// quint_decide only closes the cycle from DECISION
+		if tool, ok := phaseTools[rule.To]; ok && (rule.To != PhaseIdle || from == PhaseDecision) {
+			steps = append(steps, fmt.Sprintf("call %s to enter %s as %s", tool, rule.To, rule.Role))
+		} else {
+			steps = append(steps, fmt.Sprintf("enter %s as %s", rule.To, rule.Role))
+		}
+	}
+	if len(steps) == 0 {
+		return fmt.Sprintf("%s is terminal; no further transitions are allowed", from)
+	}
+	return fmt.Sprintf("From %s: %s", from, strings.Join(steps, "; "))
+}
+
+// phaseAnchor is the evidence anchor (A.10) a tool presents when entering
+// target: the knowledge the phase builds on
+func (t *Tools) phaseAnchor(target Phase, holonID string) *EvidenceStub {
+	dir := t.projectionDir(t.contextID())
+	stub := &EvidenceStub{Type: "artifact", HolonID: holonID}
+	switch target {
+	case PhaseAbduction, PhaseDeduction:
+		stub.URI = filepath.Join(dir, "knowledge", "L0")
+		stub.Description = "L0 hypotheses"
+	case PhaseInduction:
+		stub.URI = t.holonPath("L1", holonID)
+		stub.Description = "L1 hypothesis under test"
+	case PhaseAudit, PhaseDecision:
+		stub.URI = t.holonPath("L2", holonID)
+		stub.Description = "L2 hypothesis"
+	default:
+		stub.URI = filepath.Join(dir, "decisions")
+		stub.Description = "Decision records"
+	}
+	return stub
+}
+
 func (t *Tools) AuditLog(toolName, operation, actor, targetID, result string, input interface{}, details string) {
 	if t.DB == nil {
 		return
@@ -58,13 +256,13 @@ func (t *Tools) AuditLog(toolName, operation, actor, targetID, result string, in
 		data, err := json.Marshal(input)
 		if err == nil {
 			hash := sha256.Sum256(data)
-			inputHash = hex.EncodeToString(hash[:8])
+			inputHash = hex.EncodeToString(hash[:])
 		}
 	}
 
 	id := uuid.New().String()
-	ctx := context.Background()
-	if err := t.DB.InsertAuditLog(ctx, id, toolName, operation, actor, targetID, inputHash, result, details, "default"); err != nil {
+	ctx := context.Background() // Recorded even when the call was cancelled
+	if err := t.DB.InsertAuditLog(ctx, id, toolName, operation, actor, targetID, inputHash, result, details, t.contextID()); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to insert audit log: %v\n", err)
 	}
 }
@@ -75,26 +273,30 @@ func (t *Tools) Slugify(title string) string {
 }
 
 func (t *Tools) MoveHypothesis(hypothesisID, sourceLevel, destLevel string) (string, error) {
-	srcPath := filepath.Join(t.GetFPFDir(), "knowledge", sourceLevel, hypothesisID+".md")
-	destPath := filepath.Join(t.GetFPFDir(), "knowledge", destLevel, hypothesisID+".md")
-
-	if _, err := os.Stat(srcPath); os.IsNotExist(err) {
-		t.AuditLog("quint_move", "move_hypothesis", "agent", hypothesisID, "ERROR", map[string]string{"from": sourceLevel, "to": destLevel}, "not found")
-		return "", fmt.Errorf("hypothesis %s not found in %s", hypothesisID, sourceLevel)
-	}
-
-	if err := os.Rename(srcPath, destPath); err != nil {
-		t.AuditLog("quint_move", "move_hypothesis", "agent", hypothesisID, "ERROR", map[string]string{"from": sourceLevel, "to": destLevel}, err.Error())
-		return "", fmt.Errorf("failed to move hypothesis from %s to %s: %v", sourceLevel, destLevel, err)
-	}
+	srcPath := t.holonPath(sourceLevel, hypothesisID)
+	destPath := t.holonPath(destLevel, hypothesisID)
+	input := map[string]string{"from": sourceLevel, "to": destLevel}
 
-	if t.DB != nil {
-		if err := t.DB.UpdateHolonLayer(context.Background(), hypothesisID, destLevel); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to update holon layer in DB: %v\n", err)
+	err := t.atomically(func(uow *unitOfWork) error {
+		if !uow.Exists(srcPath) {
+			return fmt.Errorf("hypothesis %s not found in %s", hypothesisID, sourceLevel)
+		}
+		if err := uow.Move(srcPath, destPath); err != nil {
+			return fmt.Errorf("failed to move hypothesis from %s to %s: %v", sourceLevel, destLevel, err)
 		}
+		if t.DB != nil {
+			if err := t.DB.UpdateHolonLayer(t.callContext(), hypothesisID, destLevel); err != nil {
+				return fmt.Errorf("failed to update holon layer in DB: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.AuditLog("quint_move", "move_hypothesis", t.actor(), hypothesisID, "ERROR", input, err.Error())
+		return "", err
 	}
 
-	t.AuditLog("quint_move", "move_hypothesis", "agent", hypothesisID, "SUCCESS", map[string]string{"from": sourceLevel, "to": destLevel}, "")
+	t.AuditLog("quint_move", "move_hypothesis", t.actor(), hypothesisID, "SUCCESS", input, "")
 	return destPath, nil
 }
 
@@ -141,11 +343,19 @@ func (t *Tools) RecordContext(vocabulary, invariants string) (string, error) {
 	invFormatted := formatInvariants(invariants)
 
 	content := fmt.Sprintf("# Bounded Context\n\n## Vocabulary\n\n%s\n\n## Invariants\n\n%s\n", vocabFormatted, invFormatted)
-	path := filepath.Join(t.GetFPFDir(), "context.md")
+	path := t.contextFile()
 
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	_, statErr := os.Stat(path)
 	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
 		return "", err
 	}
+	if os.IsNotExist(statErr) {
+		t.resourceListChanged()
+	}
+	t.resourceUpdated(ResourceURI(ResourceContext, ""))
 	return path, nil
 }
 
@@ -233,9 +443,9 @@ func (t *Tools) RecordWork(methodName string, start time.Time) {
 		return
 	}
 	end := time.Now()
-	id := fmt.Sprintf("work-%d", start.UnixNano())
+	id := fmt.Sprintf("work-%d-%s", start.UnixNano(), uuid.New().String()[:8]) // Calls can start in the same instant
 
-	performer := string(t.FSM.State.ActiveRole.Role)
+	performer := t.sessionActor()
 	if performer == "" {
 		performer = "System"
 	}
@@ -246,73 +456,114 @@ func (t *Tools) RecordWork(methodName string, start time.Time) {
 	}
 }
 
-func (t *Tools) ProposeHypothesis(title, content, scope, kind, rationale string, decisionContext string, dependsOn []string, dependencyCL int) (string, error) {
+func (t *Tools) ProposeHypothesis(title, content, scope, kind, rationale string, decisionContext string, dependsOn []string, dependencyCL int, formality int) (string, error) {
 	defer t.RecordWork("ProposeHypothesis", time.Now())
 
-	slug := t.Slugify(title)
-	filename := fmt.Sprintf("%s.md", slug)
-	path := filepath.Join(t.GetFPFDir(), "knowledge", "L0", filename)
+	id := t.holonID(t.Slugify(title))
+	path := t.holonPath("L0", id)
 
 	body := fmt.Sprintf("\n# Hypothesis: %s\n\n%s\n\n## Rationale\n%s", title, content, rationale)
-	fields := map[string]string{
+	formality = assurance.ClampFormality(formality)
+	fields := Frontmatter{
 		"scope": scope,
 		"kind":  kind,
 	}
 
-	if err := WriteWithHash(path, fields, body); err != nil {
-		t.AuditLog("quint_propose", "create_hypothesis", "agent", slug, "ERROR", map[string]string{"title": title, "kind": kind}, err.Error())
+	err := t.atomically(func(uow *unitOfWork) error {
+		recorded := dependsOn
+		if t.DB != nil {
+			if err := t.recordHypothesis(id, title, body, scope, kind, decisionContext, dependsOn, dependencyCL, formality); err != nil {
+				return err
+			}
+			var err error
+			if recorded, err = t.holonDependsOn(t.callContext(), id); err != nil {
+				return err
+			}
+		}
+		if len(recorded) > 0 {
+			fields["depends_on"] = recorded
+		}
+		return uow.WriteWithHash(path, fields, body)
+	})
+	if err != nil {
+		t.AuditLog("quint_propose", "create_hypothesis", t.actor(), id, "ERROR", map[string]string{"title": title, "kind": kind}, err.Error())
 		return "", err
 	}
 
-	if t.DB != nil {
-		if err := t.DB.CreateHolon(context.Background(), slug, "hypothesis", kind, "L0", title, body, "default", scope, ""); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to create holon in DB: %v\n", err)
-		}
+	t.AuditLog("quint_propose", "create_hypothesis", t.actor(), id, "SUCCESS", map[string]string{"title": title, "kind": kind, "scope": scope, "formality": fmt.Sprintf("F%d", formality)}, "")
+
+	return path, nil
+}
+
+// holonDependsOn lists the componentOf/constituentOf sources of id, sorted.
+// memberOf alternatives do not propagate R, so they are not dependencies.
+func (t *Tools) holonDependsOn(ctx context.Context, id string) ([]string, error) {
+	sources, err := t.DB.GetDependents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var deps []string
+	for _, s := range sources {
+		deps = append(deps, s.SourceID)
 	}
+	sort.Strings(deps)
+	return deps, nil
+}
+
+// recordHypothesis writes a proposed hypothesis and its relations to the DB.
+// Missing or cyclic dependencies are skipped with a warning; a failed write
+// is an error.
+func (t *Tools) recordHypothesis(id, title, body, scope, kind, decisionContext string, dependsOn []string, dependencyCL, formality int) error {
+	ctx := t.callContext()
 
-	ctx := context.Background()
+	if err := t.DB.CreateHolon(ctx, id, "hypothesis", kind, "L0", title, body, t.contextID(), scope, ""); err != nil {
+		return fmt.Errorf("failed to create holon in DB: %w", err)
+	}
+	if err := t.DB.UpdateHolonFormality(ctx, id, formality); err != nil {
+		return fmt.Errorf("failed to set formality in DB: %w", err)
+	}
+	if parsed, err := assurance.ParseScope(scope); err == nil {
+		if err := t.DB.UpdateHolonScopeSlice(ctx, id, parsed.Encode()); err != nil {
+			return fmt.Errorf("failed to store structured scope in DB: %w", err)
+		}
+	}
 
-	if decisionContext != "" && t.DB != nil {
+	if decisionContext != "" {
 		if _, err := t.DB.GetHolon(ctx, decisionContext); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: decision_context '%s' not found, skipping MemberOf\n", decisionContext)
-		} else {
-			if err := t.createRelation(ctx, slug, "memberOf", decisionContext, 3); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to create MemberOf relation: %v\n", err)
-			}
+		} else if err := t.createRelation(ctx, id, "memberOf", decisionContext, 3); err != nil {
+			return fmt.Errorf("failed to create MemberOf relation: %w", err)
 		}
 	}
 
-	if len(dependsOn) > 0 && t.DB != nil {
-		if dependencyCL < 1 || dependencyCL > 3 {
-			dependencyCL = 3
-		}
+	if len(dependsOn) == 0 {
+		return nil
+	}
+	if dependencyCL < 1 || dependencyCL > 3 {
+		dependencyCL = 3
+	}
 
-		relationType := "componentOf"
-		if kind == "episteme" {
-			relationType = "constituentOf"
-		}
+	relationType := "componentOf"
+	if kind == "episteme" {
+		relationType = "constituentOf"
+	}
 
-		for _, depID := range dependsOn {
-			if _, err := t.DB.GetHolon(ctx, depID); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: dependency '%s' not found, skipping\n", depID)
-				continue
-			}
+	for _, depID := range dependsOn {
+		if _, err := t.DB.GetHolon(ctx, depID); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: dependency '%s' not found, skipping\n", depID)
+			continue
+		}
 
-			if cyclic, _ := t.wouldCreateCycle(ctx, depID, slug); cyclic {
-				fmt.Fprintf(os.Stderr, "Warning: dependency on '%s' would create cycle, skipping\n", depID)
-				continue
-			}
+		if cyclic, _ := t.wouldCreateCycle(ctx, depID, id); cyclic {
+			fmt.Fprintf(os.Stderr, "Warning: dependency on '%s' would create cycle, skipping\n", depID)
+			continue
+		}
 
-			if err := t.createRelation(ctx, depID, relationType, slug, dependencyCL); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to create %s relation to %s: %v\n",
-					relationType, depID, err)
-			}
+		if err := t.createRelation(ctx, depID, relationType, id, dependencyCL); err != nil {
+			return fmt.Errorf("failed to create %s relation to %s: %w", relationType, depID, err)
 		}
 	}
-
-	t.AuditLog("quint_propose", "create_hypothesis", "agent", slug, "SUCCESS", map[string]string{"title": title, "kind": kind, "scope": scope}, "")
-
-	return path, nil
+	return nil
 }
 
 func (t *Tools) createRelation(ctx context.Context, sourceID, relationType, targetID string, cl int) error {
@@ -324,8 +575,13 @@ func (t *Tools) createRelation(ctx context.Context, sourceID, relationType, targ
 		return err
 	}
 
-	t.AuditLog("quint_propose", "create_relation", "agent", sourceID, "SUCCESS",
-		map[string]string{"relation": relationType, "target": targetID, "cl": fmt.Sprintf("%d", cl)}, "")
+	var details string
+	if sourceContext, targetContext, cross := t.crossContext(ctx, sourceID, targetID); cross {
+		details = warnCrossContext(sourceID, sourceContext, relationType, targetID, targetContext)
+	}
+
+	t.AuditLog("quint_propose", "create_relation", t.actor(), sourceID, "SUCCESS",
+		map[string]string{"relation": relationType, "target": targetID, "cl": fmt.Sprintf("%d", cl)}, details)
 
 	return nil
 }
@@ -359,12 +615,15 @@ func (t *Tools) isReachable(ctx context.Context, from, to string, visited map[st
 	return false, nil
 }
 
-func (t *Tools) VerifyHypothesis(hypothesisID, checksJSON, verdict string) (string, error) {
+// VerifyHypothesis records deduction results. A non-negative formality
+// replaces the holon's F (e.g. after the claim was formalized during checks);
+// pass -1 to keep the level set at proposal time.
+func (t *Tools) VerifyHypothesis(hypothesisID, checksJSON, verdict string, formality int) (string, error) {
 	defer t.RecordWork("VerifyHypothesis", time.Now())
 
 	carrierRef := "internal-logic"
 	if t.DB != nil {
-		holon, err := t.DB.GetHolon(context.Background(), hypothesisID)
+		holon, err := t.DB.GetHolon(t.callContext(), hypothesisID)
 		if err == nil && holon.Kind.Valid {
 			switch holon.Kind.String {
 			case "system":
@@ -375,34 +634,46 @@ func (t *Tools) VerifyHypothesis(hypothesisID, checksJSON, verdict string) (stri
 		}
 	}
 
-	switch strings.ToLower(verdict) {
-	case "pass":
-		_, err := t.MoveHypothesis(hypothesisID, "L0", "L1")
-		if err != nil {
-			t.AuditLog("quint_verify", "verify_hypothesis", "agent", hypothesisID, "ERROR", map[string]string{"verdict": verdict}, err.Error())
-			return "", err
+	normalizedVerdict := strings.ToLower(verdict)
+	err := t.atomically(func(uow *unitOfWork) error {
+		if formality >= 0 && t.DB != nil {
+			formality = assurance.ClampFormality(formality)
+			if err := t.DB.UpdateHolonFormality(t.callContext(), hypothesisID, formality); err != nil {
+				return fmt.Errorf("failed to set formality in DB: %w", err)
+			}
+			t.AuditLog("quint_verify", "set_formality", t.actor(), hypothesisID, "SUCCESS", map[string]string{"formality": fmt.Sprintf("F%d", formality)}, "")
 		}
 
-		evidenceContent := fmt.Sprintf("Verification Checks:\n%s", checksJSON)
-		if _, err := t.ManageEvidence(PhaseDeduction, "add", hypothesisID, "verification", evidenceContent, "pass", "L1", carrierRef, ""); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to record verification evidence for %s: %v\n", hypothesisID, err)
+		switch normalizedVerdict {
+		case "pass":
+			// Passing L1 evidence in Deduction promotes the hypothesis to L1
+			evidenceContent := fmt.Sprintf("Verification Checks:\n%s", checksJSON)
+			_, err := t.ManageEvidence(PhaseDeduction, "add", hypothesisID, "verification", evidenceContent, "pass", "L1", carrierRef, "")
+			return err
+		case "fail":
+			_, err := t.MoveHypothesis(hypothesisID, "L0", "invalid")
+			return err
+		case "refine":
+			return nil
+		default:
+			return fmt.Errorf("unknown verdict: %s", verdict)
 		}
+	})
+	if err != nil {
+		t.AuditLog("quint_verify", "verify_hypothesis", t.actor(), hypothesisID, "ERROR", map[string]string{"verdict": verdict}, err.Error())
+		return "", err
+	}
 
-		t.AuditLog("quint_verify", "verify_hypothesis", "agent", hypothesisID, "SUCCESS", map[string]string{"verdict": "PASS", "result": "L1"}, "")
+	switch normalizedVerdict {
+	case "pass":
+		t.AuditLog("quint_verify", "verify_hypothesis", t.actor(), hypothesisID, "SUCCESS", map[string]string{"verdict": "PASS", "result": "L1"}, "")
 		return fmt.Sprintf("Hypothesis %s (kind: %s) promoted to L1", hypothesisID, carrierRef), nil
 	case "fail":
-		_, err := t.MoveHypothesis(hypothesisID, "L0", "invalid")
-		if err != nil {
-			t.AuditLog("quint_verify", "verify_hypothesis", "agent", hypothesisID, "ERROR", map[string]string{"verdict": verdict}, err.Error())
-			return "", err
-		}
-		t.AuditLog("quint_verify", "verify_hypothesis", "agent", hypothesisID, "SUCCESS", map[string]string{"verdict": "FAIL", "result": "invalid"}, "")
+		t.AuditLog("quint_verify", "verify_hypothesis", t.actor(), hypothesisID, "SUCCESS", map[string]string{"verdict": "FAIL", "result": "invalid"}, "")
 		return fmt.Sprintf("Hypothesis %s moved to invalid", hypothesisID), nil
-	case "refine":
-		t.AuditLog("quint_verify", "verify_hypothesis", "agent", hypothesisID, "SUCCESS", map[string]string{"verdict": "REFINE", "result": "L0"}, "")
-		return fmt.Sprintf("Hypothesis %s requires refinement (staying in L0)", hypothesisID), nil
 	default:
-		return "", fmt.Errorf("unknown verdict: %s", verdict)
+		t.AuditLog("quint_verify", "verify_hypothesis", t.actor(), hypothesisID, "SUCCESS", map[string]string{"verdict": "REFINE", "result": "L0"}, "")
+		return fmt.Sprintf("Hypothesis %s requires refinement (staying in L0)", hypothesisID), nil
 	}
 }
 
@@ -418,7 +689,7 @@ func (t *Tools) ManageEvidence(currentPhase Phase, action, targetID, evidenceTyp
 	if validUntil == "" && action != "check" {
 		validUntil = time.Now().AddDate(0, 0, 90).Format("2006-01-02")
 	}
-	ctx := context.Background()
+	ctx := t.callContext()
 
 	if action == "check" {
 		if t.DB == nil {
@@ -459,58 +730,65 @@ func (t *Tools) ManageEvidence(currentPhase Phase, action, targetID, evidenceTyp
 		}
 	}
 
-	var moveErr error
-	if (normalizedVerdict == "pass") && shouldPromote {
-		switch currentPhase {
-		case PhaseDeduction:
-			_, moveErr = t.MoveHypothesis(targetID, "L0", "L1")
-		case PhaseInduction:
-			if _, err := os.Stat(filepath.Join(t.GetFPFDir(), "knowledge", "L0", targetID+".md")); err == nil {
-				return "", fmt.Errorf("hypothesis %s is still in L0: run /q2-verify to promote it to L1 before testing", targetID)
+	date := time.Now().Format("2006-01-02")
+	var path string
+	err := t.atomically(func(uow *unitOfWork) error {
+		var moveErr error
+		if (normalizedVerdict == "pass") && shouldPromote {
+			switch currentPhase {
+			case PhaseDeduction:
+				_, moveErr = t.MoveHypothesis(targetID, "L0", "L1")
+			case PhaseInduction:
+				if uow.Exists(t.holonPath("L0", targetID)) {
+					return fmt.Errorf("hypothesis %s is still in L0: run /q2-verify to promote it to L1 before testing", targetID)
+				}
+				_, moveErr = t.MoveHypothesis(targetID, "L1", "L2")
+			}
+		} else if normalizedVerdict == "fail" || normalizedVerdict == "refine" {
+			switch currentPhase {
+			case PhaseDeduction:
+				_, moveErr = t.MoveHypothesis(targetID, "L0", "invalid")
+			case PhaseInduction:
+				_, moveErr = t.MoveHypothesis(targetID, "L1", "invalid")
 			}
-			_, moveErr = t.MoveHypothesis(targetID, "L1", "L2")
 		}
-	} else if normalizedVerdict == "fail" || normalizedVerdict == "refine" {
-		switch currentPhase {
-		case PhaseDeduction:
-			_, moveErr = t.MoveHypothesis(targetID, "L0", "invalid")
-		case PhaseInduction:
-			_, moveErr = t.MoveHypothesis(targetID, "L1", "invalid")
+		if moveErr != nil {
+			return fmt.Errorf("failed to move hypothesis: %v", moveErr)
 		}
-	}
 
-	if moveErr != nil {
-		return "", fmt.Errorf("failed to move hypothesis: %v", moveErr)
-	}
+		filename := t.evidenceFilename(uow, date, evidenceType, targetID)
+		path = t.evidencePath(targetID, filename)
+		fields := Frontmatter{
+			"id":              filename,
+			"type":            evidenceType,
+			"target":          targetID,
+			"verdict":         normalizedVerdict,
+			"assurance_level": assuranceLevel,
+			"carrier_ref":     carrierRef,
+			"valid_until":     dateField(validUntil),
+			"date":            dateField(date),
+		}
+		if err := uow.WriteWithHash(path, fields, "\n"+content); err != nil {
+			return err
+		}
 
-	date := time.Now().Format("2006-01-02")
-	filename := fmt.Sprintf("%s-%s-%s.md", date, evidenceType, targetID)
-	path := filepath.Join(t.GetFPFDir(), "evidence", filename)
-
-	body := fmt.Sprintf("\n%s", content)
-	fields := map[string]string{
-		"id":              filename,
-		"type":            evidenceType,
-		"target":          targetID,
-		"verdict":         normalizedVerdict,
-		"assurance_level": assuranceLevel,
-		"carrier_ref":     carrierRef,
-		"valid_until":     validUntil,
-		"date":            date,
-	}
-
-	if err := WriteWithHash(path, fields, body); err != nil {
+		if t.DB != nil {
+			if err := t.DB.AddEvidence(ctx, filename, targetID, evidenceType, content, normalizedVerdict, assuranceLevel, carrierRef, validUntil); err != nil {
+				return fmt.Errorf("failed to add evidence to DB: %w", err)
+			}
+			if err := t.DB.Link(ctx, filename, targetID, "verifiedBy"); err != nil {
+				return fmt.Errorf("failed to link evidence in DB: %w", err)
+			}
+		}
+		t.resourceUpdated(ResourceURI(ResourceHolon, targetID)) // Its R_eff changes with the evidence
+		return nil
+	})
+	if err != nil {
 		return "", err
 	}
 
-	if t.DB != nil {
-		if err := t.DB.AddEvidence(ctx, filename, targetID, evidenceType, content, normalizedVerdict, assuranceLevel, carrierRef, validUntil); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to add evidence to DB: %v\n", err)
-		}
-		if err := t.DB.Link(ctx, filename, targetID, "verifiedBy"); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to link evidence in DB: %v\n", err)
-		}
-	}
+	t.AuditLog(evidenceTool(currentPhase), "add_evidence", t.actor(), targetID, "SUCCESS",
+		map[string]string{"type": evidenceType, "verdict": normalizedVerdict, "assurance_level": assuranceLevel}, "")
 
 	if !shouldPromote && verdict == "PASS" {
 		return path + " (Evidence recorded, but Assurance Level insufficient for promotion)", nil
@@ -518,6 +796,42 @@ func (t *Tools) ManageEvidence(currentPhase Phase, action, targetID, evidenceTyp
 	return path, nil
 }
 
+// evidenceFilename names a new evidence file, which is also its evidence ID.
+// Repeated evidence of one type on the same day gets a numeric suffix instead
+// of overwriting the earlier record.
+func (t *Tools) evidenceFilename(uow *unitOfWork, date, evidenceType, targetID string) string {
+	base := fmt.Sprintf("%s-%s-%s", date, evidenceType, targetID)
+	filename := base + ".md"
+	for n := 2; t.evidenceExists(uow, targetID, filename); n++ {
+		filename = fmt.Sprintf("%s-%d.md", base, n)
+	}
+	return filename
+}
+
+func (t *Tools) evidenceExists(uow *unitOfWork, targetID, filename string) bool {
+	if uow.Exists(t.evidencePath(targetID, filename)) {
+		return true
+	}
+	if t.DB == nil {
+		return false
+	}
+	_, err := t.DB.GetEvidenceByID(t.callContext(), filename)
+	return err == nil
+}
+
+// evidenceTool names the tool that records evidence in a phase
+func evidenceTool(phase Phase) string {
+	switch phase {
+	case PhaseDeduction:
+		return "quint_verify"
+	case PhaseInduction:
+		return "quint_test"
+	case PhaseAudit, PhaseDecision:
+		return "quint_audit"
+	}
+	return "quint_evidence"
+}
+
 func (t *Tools) RefineLoopback(currentPhase Phase, parentID, insight, newTitle, newContent, scope string) (string, error) {
 	defer t.RecordWork("RefineLoopback", time.Now())
 
@@ -531,26 +845,34 @@ func (t *Tools) RefineLoopback(currentPhase Phase, parentID, insight, newTitle,
 		return "", fmt.Errorf("loopback not applicable from phase %s", currentPhase)
 	}
 
-	if _, err := t.MoveHypothesis(parentID, parentLevel, "invalid"); err != nil {
-		return "", fmt.Errorf("failed to move parent hypothesis to invalid: %v", err)
-	}
+	var childPath string
+	err := t.atomically(func(uow *unitOfWork) error {
+		if _, err := t.MoveHypothesis(parentID, parentLevel, "invalid"); err != nil {
+			return fmt.Errorf("failed to move parent hypothesis to invalid: %v", err)
+		}
 
-	rationale := fmt.Sprintf(`{"source": "loopback", "parent_id": "%s", "insight": "%s"}`, parentID, insight)
-	childPath, err := t.ProposeHypothesis(newTitle, newContent, scope, "system", rationale, "", nil, 3)
-	if err != nil {
-		return "", fmt.Errorf("failed to create child hypothesis: %v", err)
-	}
+		rationale := fmt.Sprintf(`{"source": "loopback", "parent_id": "%s", "insight": "%s"}`, parentID, insight)
+		var err error
+		childPath, err = t.ProposeHypothesis(newTitle, newContent, scope, "system", rationale, "", nil, 3, assurance.MinFormality)
+		if err != nil {
+			return fmt.Errorf("failed to create child hypothesis: %v", err)
+		}
 
-	logFile := filepath.Join(t.GetFPFDir(), "sessions", fmt.Sprintf("loopback-%d.md", time.Now().Unix()))
-	logContent := fmt.Sprintf("# Loopback Event\n\nParent: %s (moved to invalid)\nInsight: %s\nChild: %s\n", parentID, insight, childPath)
-	if err := os.WriteFile(logFile, []byte(logContent), 0644); err != nil {
-		return "", fmt.Errorf("failed to write loopback log file: %v", err)
+		logFile := filepath.Join(t.GetFPFDir(), "sessions", fmt.Sprintf("loopback-%d.md", time.Now().Unix()))
+		logContent := fmt.Sprintf("# Loopback Event\n\nParent: %s (moved to invalid)\nInsight: %s\nChild: %s\n", parentID, insight, childPath)
+		if err := uow.WriteFile(logFile, []byte(logContent)); err != nil {
+			return fmt.Errorf("failed to write loopback log file: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
 
 	return childPath, nil
 }
 
-func (t *Tools) FinalizeDecision(title, winnerID string, rejectedIDs []string, decisionContext, decision, rationale, consequences, characteristics string) (string, error) {
+func (t *Tools) FinalizeDecision(title, winnerID string, rejectedIDs []string, decisionContext, decision, rationale, consequences, characteristics, requiredScope string) (string, error) {
 	defer t.RecordWork("FinalizeDecision", time.Now())
 
 	body := fmt.Sprintf("\n# %s\n\n", title)
@@ -562,83 +884,147 @@ func (t *Tools) FinalizeDecision(title, winnerID string, rejectedIDs []string, d
 	}
 	body += fmt.Sprintf("## Consequences\n%s\n", consequences)
 
+	if t.DB != nil && winnerID != "" {
+		effective, warning := t.CheckDecisionScope(winnerID, requiredScope)
+		body += fmt.Sprintf("\n## Effective Scope (G)\n%s\n", effective)
+		if warning != "" {
+			body += fmt.Sprintf("\n%s\n", warning)
+		}
+	}
+
 	now := time.Now()
-	dateStr := now.Format("2006-01-02")
-	drrName := fmt.Sprintf("DRR-%s-%s.md", dateStr, t.Slugify(title))
-	drrPath := filepath.Join(t.GetFPFDir(), "decisions", drrName)
+	drrID := t.holonID(t.Slugify(title))
+	drrPath := t.decisionPath(drrID, now)
+	drrName := filepath.Base(drrPath)
 
-	fields := map[string]string{
+	fields := Frontmatter{
 		"type":      "DRR",
 		"winner_id": winnerID,
-		"created":   now.Format(time.RFC3339),
+		"created":   now.Truncate(time.Second),
 	}
 
-	if err := WriteWithHash(drrPath, fields, body); err != nil {
-		t.AuditLog("quint_decide", "finalize_decision", "agent", winnerID, "ERROR", map[string]string{"title": title}, err.Error())
-		return "", err
-	}
-
-	if t.DB != nil {
-		ctx := context.Background()
-		drrID := t.Slugify(title)
-		if err := t.DB.CreateHolon(ctx, drrID, "DRR", "", "DRR", title, body, "default", "", winnerID); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to create DRR holon in DB: %v\n", err)
+	err := t.atomically(func(uow *unitOfWork) error {
+		if err := uow.WriteWithHash(drrPath, fields, body); err != nil {
+			return err
 		}
 
-		// Create selects relation: DRR → winner
-		if winnerID != "" {
-			if err := t.createRelation(ctx, drrID, "selects", winnerID, 3); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to create selects relation: %v\n", err)
+		if t.DB != nil {
+			ctx := t.callContext()
+			if err := t.DB.CreateHolon(ctx, drrID, "DRR", "", "DRR", title, body, t.contextID(), "", winnerID); err != nil {
+				return fmt.Errorf("failed to create DRR holon in DB: %w", err)
+			}
+
+			// Create selects relation: DRR → winner
+			if winnerID != "" {
+				if err := t.createRelation(ctx, drrID, "selects", winnerID, 3); err != nil {
+					return fmt.Errorf("failed to create selects relation: %w", err)
+				}
 			}
-		}
 
-		// Create rejects relations: DRR → each rejected alternative
-		for _, rejID := range rejectedIDs {
-			if rejID != "" && rejID != winnerID {
-				if err := t.createRelation(ctx, drrID, "rejects", rejID, 3); err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to create rejects relation to %s: %v\n", rejID, err)
+			// Create rejects relations: DRR → each rejected alternative
+			for _, rejID := range rejectedIDs {
+				if rejID != "" && rejID != winnerID {
+					if err := t.createRelation(ctx, drrID, "rejects", rejID, 3); err != nil {
+						return fmt.Errorf("failed to create rejects relation to %s: %w", rejID, err)
+					}
 				}
 			}
 		}
-	}
 
-	if winnerID != "" {
-		_, err := t.MoveHypothesis(winnerID, "L1", "L2")
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "WARNING: Failed to move winner hypothesis %s to L2: %v\n", winnerID, err)
+		// A winner still in L1 is promoted with the decision; one already
+		// validated in Induction stays in L2
+		if winnerID != "" && uow.Exists(t.holonPath("L1", winnerID)) {
+			if _, err := t.MoveHypothesis(winnerID, "L1", "L2"); err != nil {
+				return fmt.Errorf("failed to move winner hypothesis %s to L2: %w", winnerID, err)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		t.AuditLog("quint_decide", "finalize_decision", t.actor(), winnerID, "ERROR", map[string]string{"title": title}, err.Error())
+		return "", err
 	}
 
-	t.AuditLog("quint_decide", "finalize_decision", "agent", winnerID, "SUCCESS", map[string]string{"title": title, "drr": drrName}, "")
+	t.AuditLog("quint_decide", "finalize_decision", t.actor(), winnerID, "SUCCESS", map[string]string{"title": title, "drr": drrName}, "")
 	return drrPath, nil
 }
 
-func (t *Tools) RunDecay() error {
-	defer t.RecordWork("RunDecay", time.Now())
+// CheckDecisionScope returns the winner's effective scope and a warning when
+// it does not cover the scope the decision is meant for. The required scope is
+// requiredScope if given, otherwise the span of the winner's decision contexts.
+func (t *Tools) CheckDecisionScope(winnerID, requiredScope string) (assurance.Scope, string) {
 	if t.DB == nil {
-		return fmt.Errorf("DB not initialized")
+		return assurance.Scope{}, ""
 	}
+	ctx := t.callContext()
 
-	ctx := context.Background()
-	ids, err := t.DB.ListAllHolonIDs(ctx)
+	calc := t.newCalculator()
+	report, err := calc.CalculateReliability(ctx, winnerID)
 	if err != nil {
-		return err
+		return assurance.Scope{}, ""
 	}
 
-	calc := assurance.New(t.DB.GetRawDB())
-	updatedCount := 0
-
-	for _, id := range ids {
-		_, err := calc.CalculateReliability(ctx, id)
+	var required assurance.Scope
+	if requiredScope != "" {
+		parsed, err := assurance.ParseScope(requiredScope)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error calculating R for %s: %v\n", id, err)
-			continue
+			return report.Scope, fmt.Sprintf("⚠️ Decision scope %q is free text; effective scope was not compared (%v)", requiredScope, err)
 		}
-		updatedCount++
+		required = parsed
+	} else {
+		contextIDs, err := t.DB.GetDecisionContexts(ctx, winnerID)
+		if err != nil {
+			return report.Scope, ""
+		}
+		var scopes []assurance.Scope
+		for _, id := range contextIDs {
+			holon, err := t.DB.GetHolon(ctx, id)
+			if err != nil {
+				continue
+			}
+			if sc, ok := holonScope(holon); ok {
+				scopes = append(scopes, sc)
+			}
+		}
+		if len(scopes) == 0 {
+			return report.Scope, ""
+		}
+		required = assurance.SpanUnion(scopes...)
 	}
 
-	fmt.Fprintf(os.Stderr, "Decay update complete. Processed %d holons.\n", updatedCount)
-	return nil
+	if report.Scope.Covers(required) {
+		return report.Scope, ""
+	}
+	return report.Scope, fmt.Sprintf("⚠️ Scope warning: %s is only supported for [%s], narrower than the decision context [%s]", winnerID, report.Scope, required)
+}
+
+// holonScope returns the declared structured scope of a holon, if it has one
+func holonScope(h db.Holon) (assurance.Scope, bool) {
+	if h.ScopeSlice.Valid && h.ScopeSlice.String != "" {
+		if sc, err := assurance.DecodeScope(h.ScopeSlice.String); err == nil {
+			return sc, true
+		}
+	}
+	sc, err := assurance.ParseScope(h.Scope.String)
+	return sc, err == nil
+}
+
+// RunDecay recomputes and caches R for every holon at the current date and
+// returns how many it processed
+func (t *Tools) RunDecay() (int, error) {
+	defer t.RecordWork("RunDecay", time.Now())
+	if t.DB == nil {
+		return 0, fmt.Errorf("DB not initialized")
+	}
+
+	// One snapshot of the graph, each holon evaluated once, one cache write transaction
+	calc := t.newCalculator()
+	calc.Progress = func(done, total int) { t.reportProgress(done, total, "Recomputing R scores") }
+	reports, err := calc.CalculateAll(t.callContext())
+	if err != nil {
+		return 0, err
+	}
+	return len(reports), nil
 }
 
 func (t *Tools) VisualizeAudit(rootID string) (string, error) {
@@ -651,19 +1037,26 @@ func (t *Tools) VisualizeAudit(rootID string) (string, error) {
 		return "Please specify a root ID for the audit tree.", nil
 	}
 
-	calc := assurance.New(t.DB.GetRawDB())
-	return t.buildAuditTree(rootID, 0, calc)
+	calc := t.newCalculator()
+	tree, err := t.buildAuditTree(rootID, 0, calc, map[string]bool{})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Φ(CL) profile: %s\n\n%s", calc.Penalty, tree), nil
 }
 
-func (t *Tools) buildAuditTree(holonID string, level int, calc *assurance.Calculator) (string, error) {
-	ctx := context.Background()
+// buildAuditTree renders one holon and its components; onPath stops the walk
+// where a componentOf cycle closes
+func (t *Tools) buildAuditTree(holonID string, level int, calc *assurance.Calculator, onPath map[string]bool) (string, error) {
+	ctx := t.callContext()
 	report, err := calc.CalculateReliability(ctx, holonID)
 	if err != nil {
 		return "", err
 	}
 
 	indent := strings.Repeat("  ", level)
-	tree := fmt.Sprintf("%s[%s R:%.2f] %s\n", indent, holonID, report.FinalScore, t.getHolonTitle(holonID))
+	tree := fmt.Sprintf("%s[%s R:%.2f F:%d]%s %s\n", indent, holonID, report.FinalScore, report.Formality, waivedMarker(report), t.getHolonTitle(holonID))
+	tree += fmt.Sprintf("%s  G: %s\n", indent, report.Scope)
 
 	if len(report.Factors) > 0 {
 		for _, f := range report.Factors {
@@ -671,6 +1064,9 @@ func (t *Tools) buildAuditTree(holonID string, level int, calc *assurance.Calcul
 		}
 	}
 
+	onPath[holonID] = true
+	defer delete(onPath, holonID)
+
 	// Show componentOf/constituentOf dependencies (these propagate WLNK)
 	components, err := t.DB.GetComponentsOf(ctx, holonID)
 	if err != nil {
@@ -684,8 +1080,15 @@ func (t *Tools) buildAuditTree(holonID string, level int, calc *assurance.Calcul
 			cl = c.CongruenceLevel.Int64
 		}
 		clStr := fmt.Sprintf("CL:%d", cl)
+		if _, otherContext, cross := t.crossContext(ctx, holonID, c.SourceID); cross {
+			clStr += fmt.Sprintf(", cross-context: %s", otherContext)
+		}
 		tree += fmt.Sprintf("%s  --(%s)-->\n", indent, clStr)
-		subTree, _ := t.buildAuditTree(c.SourceID, level+1, calc)
+		if onPath[c.SourceID] {
+			tree += fmt.Sprintf("%s  [%s CYCLE]\n", strings.Repeat("  ", level+1), c.SourceID)
+			continue
+		}
+		subTree, _ := t.buildAuditTree(c.SourceID, level+1, calc, onPath)
 		tree += subTree
 	}
 
@@ -700,15 +1103,23 @@ func (t *Tools) buildAuditTree(holonID string, level int, calc *assurance.Calcul
 				tree += fmt.Sprintf("%s    - %s (error)\n", indent, m.SourceID)
 				continue
 			}
-			tree += fmt.Sprintf("%s    - [%s R:%.2f] %s\n", indent, m.SourceID, memberReport.FinalScore, t.getHolonTitle(m.SourceID))
+			tree += fmt.Sprintf("%s    - [%s R:%.2f F:%d]%s %s\n", indent, m.SourceID, memberReport.FinalScore, memberReport.Formality, waivedMarker(memberReport), t.getHolonTitle(m.SourceID))
 		}
 	}
 
 	return tree, nil
 }
 
+// waivedMarker flags audit tree nodes whose score relies on waived evidence
+func waivedMarker(report *assurance.AssuranceReport) string {
+	if len(report.Waived) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [WAIVED: %s]", strings.Join(report.Waived, ", "))
+}
+
 func (t *Tools) getHolonTitle(id string) string {
-	ctx := context.Background()
+	ctx := t.callContext()
 	title, err := t.DB.GetHolonTitle(ctx, id)
 	if err != nil || title == "" {
 		return id
@@ -746,7 +1157,8 @@ func (t *Tools) Actualize() (string, error) {
 		report.WriteString("MIGRATION: Renamed to quint.db.\n")
 	}
 
-	cmd := exec.Command("git", "rev-parse", "HEAD")
+	t.reportProgress(0, 3, "Reconciling with git")
+	cmd := exec.CommandContext(t.callContext(), "git", "rev-parse", "HEAD")
 	cmd.Dir = t.RootDir
 	output, err := cmd.Output()
 	if err == nil {
@@ -756,12 +1168,12 @@ func (t *Tools) Actualize() (string, error) {
 		if lastCommit == "" {
 			report.WriteString(fmt.Sprintf("RECONCILIATION: Initializing baseline commit to %s\n", currentCommit))
 			t.FSM.State.LastCommit = currentCommit
-			if err := t.FSM.SaveState("default"); err != nil {
+			if err := t.FSM.SaveState(t.contextID()); err != nil {
 				report.WriteString(fmt.Sprintf("Warning: Failed to save state: %v\n", err))
 			}
 		} else if currentCommit != lastCommit {
 			report.WriteString(fmt.Sprintf("RECONCILIATION: Detected changes since %s\n", lastCommit))
-			diffCmd := exec.Command("git", "diff", "--name-status", lastCommit, "HEAD")
+			diffCmd := exec.CommandContext(t.callContext(), "git", "diff", "--name-status", lastCommit, "HEAD")
 			diffCmd.Dir = t.RootDir
 			diffOutput, err := diffCmd.Output()
 			if err == nil {
@@ -772,7 +1184,7 @@ func (t *Tools) Actualize() (string, error) {
 			}
 
 			t.FSM.State.LastCommit = currentCommit
-			if err := t.FSM.SaveState("default"); err != nil {
+			if err := t.FSM.SaveState(t.contextID()); err != nil {
 				report.WriteString(fmt.Sprintf("Warning: Failed to save state: %v\n", err))
 			}
 		} else {
@@ -782,38 +1194,121 @@ func (t *Tools) Actualize() (string, error) {
 		report.WriteString("RECONCILIATION: Not a git repository or git error.\n")
 	}
 
+	if err := t.callContext().Err(); err != nil {
+		return report.String(), err
+	}
+	t.reportProgress(1, 3, "Refreshing stale R scores")
+	if t.DB != nil {
+		refreshed, err := t.newCalculator().RefreshDirty(t.callContext())
+		if err != nil {
+			report.WriteString(fmt.Sprintf("Warning: Failed to refresh cached R scores: %v\n", err))
+		} else if len(refreshed) > 0 {
+			report.WriteString(fmt.Sprintf("CACHE: Recomputed %d stale R scores.\n", len(refreshed)))
+		}
+	}
+
+	if err := t.callContext().Err(); err != nil {
+		return report.String(), err
+	}
+	t.reportProgress(2, 3, "Validating projection files")
+	events, err := t.ValidateProjection()
+	if err != nil {
+		report.WriteString(fmt.Sprintf("Warning: Failed to validate projection files: %v\n", err))
+	}
+	for _, e := range events {
+		outcome := "left in place, run quint_reconcile to resolve"
+		if e.Regenerated {
+			outcome = "regenerated from DB"
+		}
+		report.WriteString(fmt.Sprintf("INTEGRITY: %s failed its content_hash check (%s).\n", t.relPath(e.FilePath), outcome))
+	}
+
+	t.reportProgress(3, 3, "Actualized")
 	return report.String(), nil
 }
 
+// GetHolon returns a holon with an up-to-date cached_r_score: an invalidated
+// score is recomputed before the holon is returned. A read-only call gets the
+// fresh score without it being written back.
 func (t *Tools) GetHolon(id string) (db.Holon, error) {
 	if t.DB == nil {
 		return db.Holon{}, fmt.Errorf("DB not initialized")
 	}
-	return t.DB.GetHolon(context.Background(), id)
+	ctx := t.callContext()
+	holon, err := t.DB.GetHolon(ctx, id)
+	if err != nil || (holon.RDirty.Int64 == 0 && holon.RComputedAt.Valid) {
+		return holon, err
+	}
+	score, err := t.newCalculator().CachedReliability(ctx, id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to refresh cached R for %s: %v\n", id, err)
+		return holon, nil
+	}
+	holon.CachedRScore = sql.NullFloat64{Float64: score.R, Valid: true}
+	holon.RComputedAt = sql.NullTime{Time: score.ComputedAt, Valid: true}
+	holon.RDirty = sql.NullInt64{}
+	return holon, nil
 }
 
-func (t *Tools) CalculateR(holonID string) (string, error) {
+// ProjectionHorizon bounds how far ahead CalculateR looks for a threshold crossing
+const ProjectionHorizon = 5 * 365 * 24 * time.Hour
+
+func (t *Tools) CalculateR(holonID, asOf string) (string, error) {
 	defer t.RecordWork("CalculateR", time.Now())
 	if t.DB == nil {
 		return "", fmt.Errorf("DB not initialized")
 	}
 
-	calc := assurance.New(t.DB.GetRawDB())
-	report, err := calc.CalculateReliability(context.Background(), holonID)
+	calc := t.newCalculator()
+	if asOf != "" {
+		at, err := parseAsOf(asOf, time.Now())
+		if err != nil {
+			return "", err
+		}
+		calc.AsOf = at
+	}
+	ctx := t.callContext()
+	report, err := calc.CalculateReliability(ctx, holonID)
 	if err != nil {
 		return "", err
 	}
 
+	threshold := 0.8
+	if t.FSM != nil {
+		threshold = t.FSM.GetAssuranceThreshold()
+	}
+
 	var result strings.Builder
-	result.WriteString(fmt.Sprintf("## Reliability Report: %s\n\n", holonID))
-	result.WriteString(fmt.Sprintf("**R_eff: %.2f**\n", report.FinalScore))
+	if calc.AsOf.IsZero() {
+		result.WriteString(fmt.Sprintf("## Reliability Report: %s\n\n", holonID))
+	} else {
+		result.WriteString(fmt.Sprintf("## Reliability Report: %s (as of %s)\n\n", holonID, calc.AsOf.Format("2006-01-02")))
+	}
+	result.WriteString(fmt.Sprintf("**R_eff: %.2f** | **F_eff: F%d**\n", report.FinalScore, report.Formality))
 	result.WriteString(fmt.Sprintf("- Self Score: %.2f\n", report.SelfScore))
+	result.WriteString(fmt.Sprintf("- Self Formality: F%d\n", report.SelfFormality))
+	result.WriteString(fmt.Sprintf("- Effective Scope (G): %s\n", report.Scope))
+	result.WriteString(fmt.Sprintf("- CL Penalty Profile: %s\n", report.Penalty))
+	result.WriteString(fmt.Sprintf("- Evidence Aggregation: %s\n", calc.Aggregation))
 	if report.WeakestLink != "" {
 		result.WriteString(fmt.Sprintf("- Weakest Link: %s\n", report.WeakestLink))
 	}
 	if report.DecayPenalty > 0 {
 		result.WriteString(fmt.Sprintf("- Decay Penalty: %.2f\n", report.DecayPenalty))
 	}
+	for _, cycle := range report.Cycles {
+		result.WriteString(fmt.Sprintf("- Dependency Cycle: %s\n", strings.Join(cycle, " → ")))
+	}
+
+	if report.FinalScore < threshold {
+		result.WriteString(fmt.Sprintf("- Assurance Threshold: %.2f (below)\n", threshold))
+	} else {
+		result.WriteString(fmt.Sprintf("- Assurance Threshold: %.2f\n", threshold))
+		if crossing, ok := t.projectThresholdCrossing(ctx, holonID, report.AsOf, threshold); ok {
+			result.WriteString(fmt.Sprintf("- Projected to fall below threshold: %s\n", crossing.Format("2006-01-02")))
+		}
+	}
+
 	if len(report.Factors) > 0 {
 		result.WriteString("\n**Factors:**\n")
 		for _, f := range report.Factors {
@@ -824,6 +1319,75 @@ func (t *Tools) CalculateR(holonID string) (string, error) {
 	return result.String(), nil
 }
 
+// ExplainR returns the full assurance explanation tree for a holon as JSON:
+// per-evidence scoring, per-edge CL penalties and the weakest link path
+func (t *Tools) ExplainR(holonID, asOf string) (string, error) {
+	defer t.RecordWork("ExplainR", time.Now())
+	if t.DB == nil {
+		return "", fmt.Errorf("DB not initialized")
+	}
+
+	calc := t.newCalculator()
+	if asOf != "" {
+		at, err := parseAsOf(asOf, time.Now())
+		if err != nil {
+			return "", err
+		}
+		calc.AsOf = at
+	}
+
+	explanation, err := calc.Explain(t.callContext(), holonID)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(explanation, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode explanation: %w", err)
+	}
+	return string(data), nil
+}
+
+// projectThresholdCrossing finds, to the day, when decay takes R_eff below
+// threshold. Decay never raises R, so a bisection over time is sound.
+func (t *Tools) projectThresholdCrossing(ctx context.Context, holonID string, from time.Time, threshold float64) (time.Time, bool) {
+	calc := t.newCalculator()
+	below := func(at time.Time) bool {
+		calc.AsOf = at
+		report, err := calc.CalculateReliability(ctx, holonID)
+		return err == nil && report.FinalScore < threshold
+	}
+
+	lo, hi := from, from.Add(ProjectionHorizon)
+	if !below(hi) {
+		return time.Time{}, false
+	}
+	for hi.Sub(lo) > 24*time.Hour {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		if below(mid) {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi, true
+}
+
+// parseAsOf accepts a date (2006-01-02), an RFC3339 timestamp, or an offset
+// from now such as "+90d" or "2w"
+func parseAsOf(value string, now time.Time) (time.Time, error) {
+	if at, err := time.Parse("2006-01-02", value); err == nil {
+		return at, nil
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+	if d, err := assurance.ParseDuration(strings.TrimPrefix(value, "+")); err == nil {
+		return now.Add(d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid as_of %q: use YYYY-MM-DD, RFC3339, or an offset like +90d", value)
+}
+
 func (t *Tools) CheckDecay(deprecate, waiveID, waiveUntil, waiveRationale string) (string, error) {
 	defer t.RecordWork("CheckDecay", time.Now())
 	if t.DB == nil {
@@ -844,7 +1408,7 @@ func (t *Tools) CheckDecay(deprecate, waiveID, waiveUntil, waiveRationale string
 }
 
 func (t *Tools) deprecateHolon(holonID string) (string, error) {
-	ctx := context.Background()
+	ctx := t.callContext()
 	holon, err := t.DB.GetHolon(ctx, holonID)
 	if err != nil {
 		return "", fmt.Errorf("holon not found: %s", holonID)
@@ -864,14 +1428,14 @@ func (t *Tools) deprecateHolon(holonID string) (string, error) {
 		return "", err
 	}
 
-	t.AuditLog("quint_check_decay", "deprecate", "user", holonID, "SUCCESS",
+	t.AuditLog("quint_check_decay", "deprecate", t.actor(), holonID, "SUCCESS",
 		map[string]string{"from": holon.Layer, "to": newLayer}, "Evidence expired, holon deprecated")
 
 	return fmt.Sprintf("Deprecated: %s %s → %s\n\nThis decision now requires re-evaluation.\nNext step: Run /q1-hypothesize to explore alternatives.", holonID, holon.Layer, newLayer), nil
 }
 
 func (t *Tools) createWaiver(evidenceID, until, rationale string) (string, error) {
-	ctx := context.Background()
+	ctx := t.callContext()
 
 	_, err := t.DB.GetEvidenceByID(ctx, evidenceID)
 	if err != nil {
@@ -891,12 +1455,13 @@ func (t *Tools) createWaiver(evidenceID, until, rationale string) (string, error
 	}
 
 	id := uuid.New().String()
-	if err := t.DB.CreateWaiver(ctx, id, evidenceID, "user", untilTime, rationale); err != nil {
+	if err := t.DB.CreateWaiver(ctx, id, evidenceID, t.actor(), untilTime, rationale); err != nil {
 		return "", fmt.Errorf("failed to create waiver: %v", err)
 	}
 
-	t.AuditLog("quint_check_decay", "waive", "user", evidenceID, "SUCCESS",
+	t.AuditLog("quint_check_decay", "waive", t.actor(), evidenceID, "SUCCESS",
 		map[string]string{"until": until, "rationale": rationale}, "")
+	t.resourceUpdated(ResourceURI(ResourceEvidence, evidenceID))
 
 	return fmt.Sprintf(`Waiver recorded:
 - Evidence: %s
@@ -908,7 +1473,7 @@ func (t *Tools) createWaiver(evidenceID, until, rationale string) (string, error
 }
 
 func (t *Tools) generateFreshnessReport() (string, error) {
-	ctx := context.Background()
+	ctx := t.callContext()
 	rawDB := t.DB.GetRawDB()
 
 	rows, err := rawDB.QueryContext(ctx, `
@@ -2,12 +2,14 @@ package fpf
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/m0n0x41d/quint-code/assurance"
 	"github.com/m0n0x41d/quint-code/db"
 )
 
@@ -94,7 +96,7 @@ func TestProposeHypothesis(t *testing.T) {
 	kind := "system"
 	rationale := "This is the rationale."
 
-	path, err := tools.ProposeHypothesis(title, content, scope, kind, rationale, "", nil, 3)
+	path, err := tools.ProposeHypothesis(title, content, scope, kind, rationale, "", nil, 3, 0)
 	if err != nil {
 		t.Fatalf("ProposeHypothesis failed: %v", err)
 	}
@@ -283,7 +285,7 @@ func TestFinalizeDecision(t *testing.T) {
 	title := "Final Project Decision"
 	content := "This is the DRR content for the decision."
 
-	drrPath, err := tools.FinalizeDecision(title, winnerID, nil, "Context", content, "Rationale", "Consequences", "Characteristics")
+	drrPath, err := tools.FinalizeDecision(title, winnerID, nil, "Context", content, "Rationale", "Consequences", "Characteristics", "")
 	if err != nil {
 		t.Fatalf("FinalizeDecision failed: %v", err)
 	}
@@ -333,7 +335,7 @@ func TestVerifyHypothesis(t *testing.T) {
 
 	// Case 1: PASS -> Promote to L1
 	fsm.State.Phase = PhaseDeduction
-	msg, err := tools.VerifyHypothesis(hypoID, `{"check":"ok"}`, "PASS")
+	msg, err := tools.VerifyHypothesis(hypoID, `{"check":"ok"}`, "PASS", -1)
 	if err != nil {
 		t.Errorf("VerifyHypothesis(PASS) failed: %v", err)
 	}
@@ -352,7 +354,7 @@ func TestVerifyHypothesis(t *testing.T) {
 		t.Fatalf("Failed to create dummy L0 hypothesis 2: %v", err)
 	}
 
-	msg, err = tools.VerifyHypothesis(hypoID2, `{"check":"bad"}`, "FAIL")
+	msg, err = tools.VerifyHypothesis(hypoID2, `{"check":"bad"}`, "FAIL", -1)
 	if err != nil {
 		t.Errorf("VerifyHypothesis(FAIL) failed: %v", err)
 	}
@@ -411,7 +413,7 @@ func TestCalculateR(t *testing.T) {
 	}
 
 	// Calculate R
-	result, err := tools.CalculateR("calc-r-test")
+	result, err := tools.CalculateR("calc-r-test", "")
 	if err != nil {
 		t.Fatalf("CalculateR failed: %v", err)
 	}
@@ -428,6 +430,88 @@ func TestCalculateR(t *testing.T) {
 	}
 }
 
+func TestCalculateR_FormalityWeakestLink(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	ctx := context.Background()
+
+	if _, err := tools.ProposeHypothesis("Napkin Sketch", "Rough idea", "global", "system", "{}", "", nil, 3, 1); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	if _, err := tools.ProposeHypothesis("Formal Spec", "TLA+ model", "global", "system", "{}", "", []string{"napkin-sketch"}, 3, 8); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+
+	holon, err := tools.DB.GetHolon(ctx, "formal-spec")
+	if err != nil {
+		t.Fatalf("GetHolon failed: %v", err)
+	}
+	if holon.Formality.Int64 != 8 {
+		t.Errorf("Expected stored formality 8, got %d", holon.Formality.Int64)
+	}
+
+	result, err := tools.CalculateR("formal-spec", "")
+	if err != nil {
+		t.Fatalf("CalculateR failed: %v", err)
+	}
+	if !strings.Contains(result, "F_eff: F1") {
+		t.Errorf("Expected F_eff capped at F1 by dependency, got: %s", result)
+	}
+	if !strings.Contains(result, "Self Formality: F8") {
+		t.Errorf("Expected self formality F8, got: %s", result)
+	}
+
+	if _, err := tools.VerifyHypothesis("napkin-sketch", `{"check":"ok"}`, "PASS", 5); err != nil {
+		t.Fatalf("VerifyHypothesis failed: %v", err)
+	}
+	tree, err := tools.VisualizeAudit("formal-spec")
+	if err != nil {
+		t.Fatalf("VisualizeAudit failed: %v", err)
+	}
+	if !strings.Contains(tree, "[formal-spec R:") || !strings.Contains(tree, "F:5]") {
+		t.Errorf("Expected audit tree to show F:5 after verify raised dependency formality, got: %s", tree)
+	}
+}
+
+func TestFinalizeDecision_ScopeWarning(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+	ctx := context.Background()
+
+	if err := tools.DB.CreateHolon(ctx, "caching-decision", "decision", "system", "L0", "Caching", "Content", "default", "services=payments,billing", ""); err != nil {
+		t.Fatalf("CreateHolon failed: %v", err)
+	}
+	if _, err := tools.ProposeHypothesis("Use Redis", "Redis cache", "services=payments; env=prod", "system", "{}", "caching-decision", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+
+	effective, warning := tools.CheckDecisionScope("use-redis", "")
+	if effective.String() != "env=prod; services=payments" {
+		t.Errorf("Unexpected effective scope: %s", effective)
+	}
+	if !strings.Contains(warning, "narrower than the decision context") {
+		t.Errorf("Expected scope warning, got %q", warning)
+	}
+
+	if _, warning := tools.CheckDecisionScope("use-redis", "services=payments; env=prod"); warning != "" {
+		t.Errorf("Expected no warning when scope is covered, got %q", warning)
+	}
+
+	l1 := filepath.Join(tempDir, ".quint", "knowledge", "L1", "use-redis.md")
+	if err := os.Rename(filepath.Join(tempDir, ".quint", "knowledge", "L0", "use-redis.md"), l1); err != nil {
+		t.Fatalf("Failed to stage hypothesis in L1: %v", err)
+	}
+	drrPath, err := tools.FinalizeDecision("Pick Cache", "use-redis", nil, "Context", "Decision", "Rationale", "Consequences", "", "")
+	if err != nil {
+		t.Fatalf("FinalizeDecision failed: %v", err)
+	}
+	drr, _ := os.ReadFile(drrPath)
+	if !strings.Contains(string(drr), "## Effective Scope (G)\nenv=prod; services=payments") {
+		t.Errorf("Expected effective scope section in DRR, got: %s", drr)
+	}
+	if !strings.Contains(string(drr), "Scope warning") {
+		t.Errorf("Expected scope warning in DRR, got: %s", drr)
+	}
+}
+
 func TestCalculateR_WithDecay(t *testing.T) {
 	tools, _, _ := setupTools(t)
 	ctx := context.Background()
@@ -445,7 +529,7 @@ func TestCalculateR_WithDecay(t *testing.T) {
 	}
 
 	// Calculate R
-	result, err := tools.CalculateR("decay-r-test")
+	result, err := tools.CalculateR("decay-r-test", "")
 	if err != nil {
 		t.Fatalf("CalculateR failed: %v", err)
 	}
@@ -456,6 +540,70 @@ func TestCalculateR_WithDecay(t *testing.T) {
 	}
 }
 
+func TestRunDecay_UpdatesCachedScores(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"decay-parent", "decay-child"} {
+		if err := tools.DB.CreateHolon(ctx, id, "hypothesis", "system", "L1", id, "Content", "ctx", "global", ""); err != nil {
+			t.Fatalf("Failed to create holon %s: %v", id, err)
+		}
+	}
+	if err := tools.DB.CreateRelation(ctx, "decay-parent", "dependsOn", "decay-child", 3); err != nil {
+		t.Fatalf("Failed to create relation: %v", err)
+	}
+	if err := tools.DB.AddEvidence(ctx, "e-parent", "decay-parent", "test", "Fresh test", "pass", "L1", "test-runner", "2099-01-01"); err != nil {
+		t.Fatalf("Failed to add evidence: %v", err)
+	}
+	if err := tools.DB.AddEvidence(ctx, "e-child", "decay-child", "test", "Old test", "pass", "L1", "test-runner", "2020-01-01"); err != nil {
+		t.Fatalf("Failed to add evidence: %v", err)
+	}
+
+	if n, err := tools.RunDecay(); err != nil || n != 2 {
+		t.Fatalf("Expected RunDecay to process 2 holons, got %d (err %v)", n, err)
+	}
+
+	// The expired child caps the parent through the weakest link
+	for _, id := range []string{"decay-parent", "decay-child"} {
+		var cached float64
+		if err := tools.DB.GetRawDB().QueryRow("SELECT cached_r_score FROM holons WHERE id = ?", id).Scan(&cached); err != nil {
+			t.Fatalf("Failed to read cached score for %s: %v", id, err)
+		}
+		if cached != 0.1 {
+			t.Errorf("Expected cached R 0.1 for %s, got %.3f", id, cached)
+		}
+	}
+}
+
+func TestGetHolon_RefreshesInvalidatedScore(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	ctx := context.Background()
+
+	if err := tools.DB.CreateHolon(ctx, "lazy-r", "hypothesis", "system", "L1", "Lazy R", "Content", "ctx", "global", ""); err != nil {
+		t.Fatalf("Failed to create holon: %v", err)
+	}
+	if err := tools.DB.AddEvidence(ctx, "e-lazy", "lazy-r", "test", "Passing test", "pass", "L2", "test-runner", "2099-01-01"); err != nil {
+		t.Fatalf("Failed to add evidence: %v", err)
+	}
+
+	holon, err := tools.GetHolon("lazy-r")
+	if err != nil {
+		t.Fatalf("GetHolon failed: %v", err)
+	}
+	if holon.CachedRScore.Float64 != 1.0 || holon.RDirty.Int64 != 0 || !holon.RComputedAt.Valid {
+		t.Errorf("Expected a freshly computed R 1.0, got R %.2f dirty %d computed %v",
+			holon.CachedRScore.Float64, holon.RDirty.Int64, holon.RComputedAt)
+	}
+
+	if err := tools.DB.AddEvidence(ctx, "e-lazy-fail", "lazy-r", "test", "Failing test", "fail", "L2", "test-runner", "2099-01-01"); err != nil {
+		t.Fatalf("Failed to add evidence: %v", err)
+	}
+	holon, _ = tools.GetHolon("lazy-r")
+	if holon.CachedRScore.Float64 != 0.5 {
+		t.Errorf("Expected new evidence to invalidate and refresh R to 0.5, got %.2f", holon.CachedRScore.Float64)
+	}
+}
+
 func TestCheckDecay_NoExpired(t *testing.T) {
 	tools, _, _ := setupTools(t)
 	ctx := context.Background()
@@ -535,6 +683,7 @@ func TestCheckDecay_Deprecate(t *testing.T) {
 	}
 
 	// Deprecate (L2 -> L1)
+	tools.Session = "claude-code/1.0.0"
 	result, err := tools.CheckDecay(holonID, "", "", "")
 	if err != nil {
 		t.Fatalf("CheckDecay deprecate failed: %v", err)
@@ -557,6 +706,20 @@ func TestCheckDecay_Deprecate(t *testing.T) {
 	if _, err := os.Stat(filepath.Join(l1Dir, holonID+".md")); os.IsNotExist(err) {
 		t.Error("Expected file to exist in L1 directory")
 	}
+
+	logs, err := tools.DB.GetAuditLogByTarget(ctx, holonID)
+	if err != nil {
+		t.Fatalf("GetAuditLogByTarget failed: %v", err)
+	}
+	var actor string
+	for _, l := range logs {
+		if l.Operation == "deprecate" {
+			actor = l.Actor
+		}
+	}
+	if actor != "claude-code/1.0.0" {
+		t.Errorf("Expected the deprecation to be audited as the session, got %q", actor)
+	}
 }
 
 func TestCheckDecay_Waive(t *testing.T) {
@@ -588,10 +751,18 @@ func TestCheckDecay_Waive(t *testing.T) {
 	// Waive the evidence
 	futureDate := "2099-12-31"
 	rationale := "Test waiver"
+	tools.Session = "claude-code/1.0.0"
 	result, err = tools.CheckDecay("", evidenceID, futureDate, rationale)
 	if err != nil {
 		t.Fatalf("CheckDecay waive failed: %v", err)
 	}
+	waiver, err := tools.DB.GetActiveWaiverForEvidence(ctx, evidenceID)
+	if err != nil {
+		t.Fatalf("GetActiveWaiverForEvidence failed: %v", err)
+	}
+	if waiver.WaivedBy != "claude-code/1.0.0" {
+		t.Errorf("Expected the waiver to name the session, got %q", waiver.WaivedBy)
+	}
 
 	if !strings.Contains(result, "Waiver recorded") {
 		t.Errorf("Expected waiver confirmation, got: %s", result)
@@ -701,6 +872,7 @@ func TestPropose_WithDecisionContext(t *testing.T) {
 		"caching-decision", // decision_context
 		nil,                // no depends_on
 		3,
+		0,
 	)
 	if err != nil {
 		t.Fatalf("ProposeHypothesis failed: %v", err)
@@ -748,6 +920,7 @@ func TestPropose_WithDependsOn(t *testing.T) {
 		"",                                      // no decision_context
 		[]string{"auth-module", "rate-limiter"}, // depends_on
 		3,                                       // CL3
+		0,                                       // F0
 	)
 	if err != nil {
 		t.Fatalf("ProposeHypothesis failed: %v", err)
@@ -769,6 +942,35 @@ func TestPropose_WithDependsOn(t *testing.T) {
 	}
 }
 
+func TestHolonFields_DependsOnOmitsMembers(t *testing.T) {
+	tools, fsm, _ := setupTools(t)
+	ctx := context.Background()
+	fsm.State.Phase = PhaseAbduction
+
+	if err := tools.DB.CreateHolon(ctx, "base-layer", "hypothesis", "episteme", "L2", "Base Layer", "Content", "default", "global", ""); err != nil {
+		t.Fatalf("Failed to create base-layer: %v", err)
+	}
+	if _, err := tools.ProposeHypothesis("Caching Options", "Content", "global", "episteme", "R", "", []string{"base-layer"}, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	if _, err := tools.ProposeHypothesis("Use Redis", "Content", "global", "system", "R", "caching-options", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+
+	holon, err := tools.DB.GetHolon(ctx, "caching-options")
+	if err != nil {
+		t.Fatalf("GetHolon failed: %v", err)
+	}
+	fields, err := tools.holonFields(holon)
+	if err != nil {
+		t.Fatalf("holonFields failed: %v", err)
+	}
+	// constituentOf propagates R, the memberOf alternative does not
+	if got := fields.List("depends_on"); len(got) != 1 || got[0] != "base-layer" {
+		t.Errorf("Expected depends_on [base-layer], got %#v", fields["depends_on"])
+	}
+}
+
 func TestPropose_CycleDetection(t *testing.T) {
 	tools, fsm, _ := setupTools(t)
 	ctx := context.Background()
@@ -781,7 +983,7 @@ func TestPropose_CycleDetection(t *testing.T) {
 	}
 
 	// Create holon B that depends on A
-	_, err = tools.ProposeHypothesis("Holon B", "B depends on A", "global", "system", "{}", "", []string{"holon-a"}, 3)
+	_, err = tools.ProposeHypothesis("Holon B", "B depends on A", "global", "system", "{}", "", []string{"holon-a"}, 3, 0)
 	if err != nil {
 		t.Fatalf("ProposeHypothesis for B failed: %v", err)
 	}
@@ -795,7 +997,7 @@ func TestPropose_CycleDetection(t *testing.T) {
 
 	// Try to make A depend on B (would create cycle since B already depends on A)
 	// This should be skipped with a warning, not error
-	_, err = tools.ProposeHypothesis("Holon C Cyclic", "C tries to depend on B", "global", "system", "{}", "", []string{"holon-b"}, 3)
+	_, err = tools.ProposeHypothesis("Holon C Cyclic", "C tries to depend on B", "global", "system", "{}", "", []string{"holon-b"}, 3, 0)
 	// Should NOT error - cycles are skipped with warning
 	if err != nil {
 		t.Fatalf("ProposeHypothesis should not error on cycle, got: %v", err)
@@ -834,6 +1036,7 @@ func TestPropose_InvalidDependency(t *testing.T) {
 		"",
 		[]string{"does-not-exist", "also-missing"}, // These don't exist
 		3,
+		0,
 	)
 	// Should NOT error - invalid deps are skipped with warning
 	if err != nil {
@@ -868,13 +1071,13 @@ func TestPropose_KindDeterminesRelation(t *testing.T) {
 	}
 
 	// Propose system hypothesis - should create componentOf
-	_, err = tools.ProposeHypothesis("System Hypo", "A system thing", "global", "system", "{}", "", []string{"base-claim"}, 3)
+	_, err = tools.ProposeHypothesis("System Hypo", "A system thing", "global", "system", "{}", "", []string{"base-claim"}, 3, 0)
 	if err != nil {
 		t.Fatalf("ProposeHypothesis for system failed: %v", err)
 	}
 
 	// Propose episteme hypothesis - should create constituentOf
-	_, err = tools.ProposeHypothesis("Episteme Hypo", "An epistemic claim", "global", "episteme", "{}", "", []string{"base-claim"}, 3)
+	_, err = tools.ProposeHypothesis("Episteme Hypo", "An epistemic claim", "global", "episteme", "{}", "", []string{"base-claim"}, 3, 0)
 	if err != nil {
 		t.Fatalf("ProposeHypothesis for episteme failed: %v", err)
 	}
@@ -935,6 +1138,7 @@ func TestWLNK_MemberOf_NoPropagation(t *testing.T) {
 		"bad-decision", // MemberOf the bad decision
 		nil,
 		3,
+		0,
 	)
 	if err != nil {
 		t.Fatalf("ProposeHypothesis failed: %v", err)
@@ -947,7 +1151,7 @@ func TestWLNK_MemberOf_NoPropagation(t *testing.T) {
 	}
 
 	// Calculate R for good-member
-	result, err := tools.CalculateR("good-member")
+	result, err := tools.CalculateR("good-member", "")
 	if err != nil {
 		t.Fatalf("CalculateR failed: %v", err)
 	}
@@ -1003,3 +1207,177 @@ func TestFormatInvariants(t *testing.T) {
 		t.Errorf("Expected line 3 to start with '3. Telethon', got: %s", lines[2])
 	}
 }
+
+func TestVisualizeAudit_FlagsWaivedNodes(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	ctx := context.Background()
+
+	if err := tools.DB.CreateHolon(ctx, "waived-root", "hypothesis", "system", "L2", "Waived Root", "Content", "ctx", "global", ""); err != nil {
+		t.Fatalf("Failed to create holon: %v", err)
+	}
+	if err := tools.DB.AddEvidence(ctx, "ev-stale", "waived-root", "test", "Old test", "pass", "L2", "test-runner", "2020-01-01"); err != nil {
+		t.Fatalf("Failed to add evidence: %v", err)
+	}
+	if _, err := tools.CheckDecay("", "ev-stale", "2099-12-31", "Accepted until re-run"); err != nil {
+		t.Fatalf("CheckDecay waive failed: %v", err)
+	}
+
+	tree, err := tools.VisualizeAudit("waived-root")
+	if err != nil {
+		t.Fatalf("VisualizeAudit failed: %v", err)
+	}
+	if !strings.Contains(tree, "[waived-root R:1.00 F:0] [WAIVED: ev-stale]") {
+		t.Errorf("Expected waived node flag in audit tree, got: %s", tree)
+	}
+	if !strings.Contains(tree, "waived by agent until 2099-12-31") {
+		t.Errorf("Expected waiver factor in audit tree, got: %s", tree)
+	}
+}
+
+func TestVisualizeAudit_ComponentCycle(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"cycle-a", "cycle-b"} {
+		if err := tools.DB.CreateHolon(ctx, id, "system", "system", "L2", id, "Content", "ctx", "global", ""); err != nil {
+			t.Fatalf("Failed to create holon %s: %v", id, err)
+		}
+		if err := tools.DB.AddEvidence(ctx, "ev-"+id, id, "test", "Passing test", "pass", "L2", "test-runner", "2099-01-01"); err != nil {
+			t.Fatalf("Failed to add evidence: %v", err)
+		}
+	}
+	// Each is a component of the other
+	_ = tools.DB.CreateRelation(ctx, "cycle-b", "componentOf", "cycle-a", 3)
+	_ = tools.DB.CreateRelation(ctx, "cycle-a", "componentOf", "cycle-b", 3)
+
+	tree, err := tools.VisualizeAudit("cycle-a")
+	if err != nil {
+		t.Fatalf("VisualizeAudit failed: %v", err)
+	}
+	if !strings.Contains(tree, "[cycle-a CYCLE]") {
+		t.Errorf("Expected the walk to stop at the cycle, got: %s", tree)
+	}
+	if !strings.Contains(tree, "[cycle-a R:0.00") || !strings.Contains(tree, "Cycle detected: cycle-a → cycle-b → cycle-a") {
+		t.Errorf("Expected circular support to score R 0 with the cycle listed, got: %s", tree)
+	}
+}
+
+func TestExplainR_ReturnsJSON(t *testing.T) {
+	tools, _, _ := setupTools(t)
+	ctx := context.Background()
+
+	if err := tools.DB.CreateHolon(ctx, "explain-top", "hypothesis", "system", "L2", "Top", "Content", "ctx", "global", ""); err != nil {
+		t.Fatalf("Failed to create holon: %v", err)
+	}
+	if err := tools.DB.CreateHolon(ctx, "explain-part", "hypothesis", "system", "L2", "Part", "Content", "ctx", "global", ""); err != nil {
+		t.Fatalf("Failed to create holon: %v", err)
+	}
+	_ = tools.DB.AddEvidence(ctx, "ev-top", "explain-top", "test", "ok", "pass", "L2", "test-runner", "2099-12-31")
+	_ = tools.DB.AddEvidence(ctx, "ev-part", "explain-part", "test", "meh", "fail", "L2", "test-runner", "2099-12-31")
+	if err := tools.DB.CreateRelation(ctx, "explain-part", "componentOf", "explain-top", 3); err != nil {
+		t.Fatalf("CreateRelation failed: %v", err)
+	}
+
+	result, err := tools.ExplainR("explain-top", "")
+	if err != nil {
+		t.Fatalf("ExplainR failed: %v", err)
+	}
+
+	var explanation assurance.RootExplanation
+	if err := json.Unmarshal([]byte(result), &explanation); err != nil {
+		t.Fatalf("Expected JSON output, got %v: %s", err, result)
+	}
+	if explanation.R != 0.0 {
+		t.Errorf("Expected R_eff 0.0, got %f", explanation.R)
+	}
+	if len(explanation.WeakestLinkPath) != 2 || explanation.WeakestLinkPath[1] != "explain-part" {
+		t.Errorf("Expected weakest link path to explain-part, got %v", explanation.WeakestLinkPath)
+	}
+	if len(explanation.Tree.Dependencies) != 1 || explanation.Tree.Dependencies[0].Child.Evidence[0].ID != "ev-part" {
+		t.Errorf("Expected nested evidence for explain-part, got %+v", explanation.Tree.Dependencies)
+	}
+
+	if err := tools.CheckPreconditions("quint_explain_r", map[string]string{"holon_id": "missing"}); err == nil {
+		t.Errorf("Expected precondition error for missing holon")
+	}
+}
+
+func TestEnterPhase_EnforcesTransitionRules(t *testing.T) {
+	tools, fsm, _ := setupTools(t)
+
+	// Testing straight from IDLE skips abduction and deduction
+	err := tools.EnterPhase("quint_test", PhaseInduction, RoleInductor, tools.phaseAnchor(PhaseInduction, "missing"))
+	if err == nil {
+		t.Fatal("Expected IDLE -> INDUCTION to be rejected")
+	}
+	for _, want := range []string{"Invalid transition: IDLE -> INDUCTION", "call quint_propose to enter ABDUCTION as Abductor"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+		}
+	}
+	if fsm.GetPhase() != PhaseIdle {
+		t.Errorf("Rejected transition must not change the phase, got %s", fsm.GetPhase())
+	}
+
+	if err := tools.EnterPhase("quint_propose", PhaseAbduction, RoleAbductor, tools.phaseAnchor(PhaseAbduction, "")); err != nil {
+		t.Fatalf("IDLE -> ABDUCTION failed: %v", err)
+	}
+	if err := tools.EnterPhase("quint_propose", PhaseAbduction, RoleAbductor, tools.phaseAnchor(PhaseAbduction, "")); err != nil {
+		t.Fatalf("Staying in ABDUCTION failed: %v", err)
+	}
+	if err := tools.EnterPhase("quint_verify", PhaseDeduction, RoleDeductor, tools.phaseAnchor(PhaseDeduction, "h1")); err != nil {
+		t.Fatalf("ABDUCTION -> DEDUCTION failed: %v", err)
+	}
+
+	reloaded, err := LoadState(DefaultContext, fsm.DB)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if reloaded.GetPhase() != PhaseDeduction {
+		t.Errorf("Expected DEDUCTION to be persisted, got %s", reloaded.GetPhase())
+	}
+
+	err = tools.EnterPhase("quint_decide", PhaseDecision, RoleDecider, tools.phaseAnchor(PhaseDecision, "h1"))
+	if err == nil || !strings.Contains(err.Error(), "call quint_verify to continue in DEDUCTION; call quint_propose to enter ABDUCTION as Abductor") {
+		t.Errorf("Expected DEDUCTION -> DECISION to be rejected with next steps, got %v", err)
+	}
+
+	if err := tools.EnterPhase("quint_propose", PhaseAbduction, RoleAbductor, tools.phaseAnchor(PhaseAbduction, "")); err != nil {
+		t.Fatalf("DEDUCTION -> ABDUCTION failed: %v", err)
+	}
+	if fsm.GetPhase() != PhaseAbduction {
+		t.Errorf("Expected a new proposal to reopen ABDUCTION, got %s", fsm.GetPhase())
+	}
+	if got := nextSteps(PhaseOperation); got != "From OPERATION: enter IDLE as Decider" {
+		t.Errorf("Unexpected next steps from OPERATION: %s", got)
+	}
+}
+
+func TestInPhase_FailedOperationKeepsPhase(t *testing.T) {
+	tools, fsm, _ := setupTools(t)
+
+	err := tools.inPhase("quint_propose", PhaseAbduction, RoleAbductor, tools.phaseAnchor(PhaseAbduction, ""), func() error {
+		return fmt.Errorf("proposal rejected")
+	})
+	if err == nil {
+		t.Fatal("Expected the operation's error")
+	}
+	reloaded, err := LoadState(DefaultContext, fsm.DB)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if fsm.GetPhase() != PhaseIdle || reloaded.GetPhase() != PhaseIdle {
+		t.Errorf("Expected a failed operation to leave IDLE, got %s in memory and %s saved", fsm.GetPhase(), reloaded.GetPhase())
+	}
+
+	err = tools.inPhase("quint_propose", PhaseAbduction, RoleAbductor, tools.phaseAnchor(PhaseAbduction, ""), func() error {
+		_, err := tools.ProposeHypothesis("Cache Layer", "Content", "global", "system", "R", "", nil, 3, 0)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("inPhase failed: %v", err)
+	}
+	if reloaded, _ := LoadState(DefaultContext, fsm.DB); reloaded.GetPhase() != PhaseAbduction {
+		t.Errorf("Expected ABDUCTION to be saved with the proposal, got %s", reloaded.GetPhase())
+	}
+}
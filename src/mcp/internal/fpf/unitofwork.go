@@ -0,0 +1,223 @@
+package fpf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/m0n0x41d/quint-code/db"
+)
+
+// unitOfWork groups the DB writes and projection file changes of one tool
+// call. DB writes go through a single transaction; file changes are staged
+// next to their destination and only put in place right before the
+// transaction commits, so both stores commit or roll back together.
+type unitOfWork struct {
+	tx      *db.Tx
+	changes []fileChange
+	notices []notice // Sent once the unit of work commits
+	reverts []func() // Undo in-memory changes when the unit of work does not commit
+}
+
+// fileChange is one staged projection change: the content staged in temp,
+// or the file at from, ends up at path. A change without path removes from.
+type fileChange struct {
+	path string
+	temp string
+	from string
+}
+
+// atomically runs fn as one unit of work. While it runs, t.DB is bound to the
+// transaction. Nested calls join the unit of work already in progress.
+func (t *Tools) atomically(fn func(uow *unitOfWork) error) error {
+	if t.uow != nil {
+		return fn(t.uow)
+	}
+
+	uow := &unitOfWork{}
+	if t.DB != nil {
+		tx, err := t.DB.BeginTx(t.callContext())
+		if err != nil {
+			return err
+		}
+		uow.tx = tx
+		store := t.DB
+		t.DB = tx.Store
+		defer func() { t.DB = store }()
+	}
+	t.uow = uow
+	defer func() { t.uow = nil }()
+
+	if err := fn(uow); err != nil {
+		uow.rollback()
+		return err
+	}
+	notices := append(t.changeNotices(uow), uow.notices...)
+	if err := uow.commit(); err != nil {
+		return err
+	}
+	t.send(notices)
+	return nil
+}
+
+// WriteWithHash stages a projection file with its content_hash frontmatter
+func (u *unitOfWork) WriteWithHash(path string, fields Frontmatter, body string) error {
+	return u.WriteFile(path, renderWithHash(fields, body))
+}
+
+// WriteFile stages data to replace the file at path
+func (u *unitOfWork) WriteFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	u.changes = append(u.changes, fileChange{path: path, temp: f.Name()})
+	return nil
+}
+
+// Move stages moving the file at from to path
+func (u *unitOfWork) Move(from, path string) error {
+	if !u.Exists(from) {
+		return fmt.Errorf("%s does not exist", from)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	for i := len(u.changes) - 1; i >= 0; i-- {
+		if u.changes[i].path == from {
+			u.changes[i].path = path
+			return nil
+		}
+	}
+	u.changes = append(u.changes, fileChange{path: path, from: from})
+	return nil
+}
+
+// Remove stages deleting the file at path
+func (u *unitOfWork) Remove(path string) error {
+	if !u.Exists(path) {
+		return fmt.Errorf("%s does not exist", path)
+	}
+	u.changes = append(u.changes, fileChange{from: path})
+	return nil
+}
+
+// Exists reports whether path exists once the staged changes are applied
+func (u *unitOfWork) Exists(path string) bool {
+	for i := len(u.changes) - 1; i >= 0; i-- {
+		switch path {
+		case u.changes[i].path:
+			return true
+		case u.changes[i].from:
+			return false
+		}
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// commit puts the staged files in place and commits the transaction. If
+// either fails, the files already moved are restored.
+func (u *unitOfWork) commit() error {
+	var undo []func()
+	revert := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}
+
+	for _, c := range u.changes {
+		restore, err := c.apply()
+		if err != nil {
+			revert()
+			u.rollback()
+			return err
+		}
+		undo = append(undo, restore)
+	}
+
+	if u.tx != nil {
+		if err := u.tx.Commit(); err != nil {
+			revert()
+			u.revertState()
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+	}
+	return nil
+}
+
+// onRollback registers how to undo an in-memory change made in the unit of
+// work if it does not commit
+func (u *unitOfWork) onRollback(undo func()) {
+	u.reverts = append(u.reverts, undo)
+}
+
+func (u *unitOfWork) revertState() {
+	for i := len(u.reverts) - 1; i >= 0; i-- {
+		u.reverts[i]()
+	}
+	u.reverts = nil
+}
+
+// rollback discards the staged files and the transaction
+func (u *unitOfWork) rollback() {
+	for _, c := range u.changes {
+		if c.temp != "" {
+			_ = os.Remove(c.temp)
+		}
+	}
+	if u.tx != nil {
+		_ = u.tx.Rollback()
+	}
+	u.revertState()
+}
+
+// apply renames the change into place and returns how to undo it
+func (c fileChange) apply() (func(), error) {
+	if c.path == "" {
+		previous, err := os.ReadFile(c.from)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", c.from, err)
+		}
+		if err := os.Remove(c.from); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", c.from, err)
+		}
+		return func() { _ = os.WriteFile(c.from, previous, 0644) }, nil
+	}
+
+	previous, readErr := os.ReadFile(c.path)
+	existed := readErr == nil
+
+	src := c.temp
+	if src == "" {
+		src = c.from
+	}
+	if err := os.Rename(src, c.path); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", c.path, err)
+	}
+
+	return func() {
+		if c.from != "" {
+			_ = os.Rename(c.path, c.from)
+		} else if !existed {
+			_ = os.Remove(c.path)
+		}
+		if existed {
+			_ = os.WriteFile(c.path, previous, 0644)
+		}
+	}, nil
+}
@@ -0,0 +1,126 @@
+package fpf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAtomically_RollsBackFilesAndDB(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+	ctx := context.Background()
+
+	if _, err := tools.ProposeHypothesis("Kept", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+
+	l0 := filepath.Join(tempDir, ".quint", "knowledge", "L0")
+	staged := filepath.Join(l0, "staged.md")
+	store := tools.DB
+	failure := errors.New("injected failure")
+
+	err := tools.atomically(func(uow *unitOfWork) error {
+		if err := uow.WriteWithHash(staged, Frontmatter{"kind": "system"}, "\nbody"); err != nil {
+			return err
+		}
+		if err := tools.DB.CreateHolon(ctx, "staged", "hypothesis", "system", "L0", "Staged", "body", DefaultContext, "", ""); err != nil {
+			return err
+		}
+		if _, err := tools.MoveHypothesis("kept", "L0", "L1"); err != nil {
+			return err
+		}
+		if uow.Exists(filepath.Join(l0, "kept.md")) {
+			t.Error("Expected the staged move to hide kept.md in L0")
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected the injected failure, got %v", err)
+	}
+
+	if tools.DB != store || tools.uow != nil {
+		t.Error("Expected the unit of work to release the tools")
+	}
+	if _, err := os.Stat(staged); !os.IsNotExist(err) {
+		t.Errorf("Expected staged file to be discarded, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(l0, "kept.md")); err != nil {
+		t.Errorf("Expected kept.md to remain in L0: %v", err)
+	}
+	entries, _ := os.ReadDir(l0)
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".tmp") {
+			t.Errorf("Expected no temp files left behind, found %s", e.Name())
+		}
+	}
+	if _, err := tools.DB.GetHolon(ctx, "staged"); err == nil {
+		t.Error("Expected the staged holon to be rolled back")
+	}
+	holon, err := tools.DB.GetHolon(ctx, "kept")
+	if err != nil || holon.Layer != "L0" {
+		t.Errorf("Expected kept to stay in L0, got %q (%v)", holon.Layer, err)
+	}
+}
+
+func TestProposeHypothesis_DBFailureLeavesNoFile(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+
+	if _, err := tools.ProposeHypothesis("Duplicate", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	path := filepath.Join(tempDir, ".quint", "knowledge", "L0", "duplicate.md")
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("failed to remove projection: %v", err)
+	}
+
+	_, err := tools.ProposeHypothesis("Duplicate", "Other content", "global", "system", "R", "", nil, 3, 0)
+	if err == nil || !strings.Contains(err.Error(), "failed to create holon in DB") {
+		t.Fatalf("Expected the DB failure to surface as an error, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no projection for the failed proposal, got %v", err)
+	}
+
+	logs, _ := tools.DB.GetAuditLogByTarget(context.Background(), "duplicate")
+	var audited bool
+	for _, l := range logs {
+		if l.Operation == "create_hypothesis" && l.Result == "ERROR" {
+			audited = true
+		}
+	}
+	if !audited {
+		t.Errorf("Expected the failure to be audited after rollback, got %+v", logs)
+	}
+}
+
+func TestVerifyHypothesis_RecordsEvidenceWithMove(t *testing.T) {
+	tools, _, tempDir := setupTools(t)
+	ctx := context.Background()
+
+	if _, err := tools.ProposeHypothesis("Verified", "Content", "global", "system", "R", "", nil, 3, 0); err != nil {
+		t.Fatalf("ProposeHypothesis failed: %v", err)
+	}
+	if _, err := tools.VerifyHypothesis("verified", `{"check":"ok"}`, "PASS", -1); err != nil {
+		t.Fatalf("VerifyHypothesis failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, ".quint", "knowledge", "L1", "verified.md")); err != nil {
+		t.Errorf("Expected verified.md in L1: %v", err)
+	}
+	evidence, err := tools.DB.GetEvidence(ctx, "verified")
+	if err != nil || len(evidence) != 1 || evidence[0].Type != "verification" {
+		t.Errorf("Expected one verification evidence, got %+v (%v)", evidence, err)
+	}
+
+	// Same-day evidence of the same type gets its own record
+	if _, err := tools.ManageEvidence(PhaseInduction, "add", "verified", "verification", "Rechecked", "pass", "L1", "internal-logic", ""); err != nil {
+		t.Fatalf("ManageEvidence failed: %v", err)
+	}
+	evidence, _ = tools.DB.GetEvidence(ctx, "verified")
+	if len(evidence) != 2 || evidence[0].ID == evidence[1].ID {
+		t.Errorf("Expected two distinct evidence records, got %+v", evidence)
+	}
+}
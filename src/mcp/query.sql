@@ -16,6 +16,9 @@ SELECT title FROM holons WHERE id = ? LIMIT 1;
 -- name: ListAllHolonIDs :many
 SELECT id FROM holons;
 
+-- name: ListAllHolons :many
+SELECT * FROM holons ORDER BY id;
+
 -- name: ListHolonsByLayer :many
 SELECT * FROM holons WHERE layer = ? ORDER BY created_at DESC;
 
@@ -25,6 +28,29 @@ UPDATE holons SET layer = ?, updated_at = ? WHERE id = ?;
 -- name: UpdateHolonRScore :exec
 UPDATE holons SET cached_r_score = ?, updated_at = ? WHERE id = ?;
 
+-- name: MarkHolonRDirty :exec
+-- Marks a holon and every holon whose R depends on it, transitively:
+-- wholes of its componentOf, groups of its memberOf, and dependents of its dependsOn.
+WITH RECURSIVE affected(id) AS (
+    SELECT ?
+    UNION
+    SELECT r.target_id FROM relations r JOIN affected a ON r.source_id = a.id
+    WHERE r.relation_type IN ('componentOf', 'memberOf')
+    UNION
+    SELECT r.source_id FROM relations r JOIN affected a ON r.target_id = a.id
+    WHERE r.relation_type = 'dependsOn'
+)
+UPDATE holons SET r_dirty = 1 WHERE id IN (SELECT id FROM affected);
+
+-- name: UpdateHolonContent :exec
+UPDATE holons SET title = ?, content = ?, kind = ?, scope = ?, updated_at = ? WHERE id = ?;
+
+-- name: UpdateHolonFormality :exec
+UPDATE holons SET formality = ?, updated_at = ? WHERE id = ?;
+
+-- name: UpdateHolonScopeSlice :exec
+UPDATE holons SET scope_slice = ?, updated_at = ? WHERE id = ?;
+
 -- name: GetHolonsByParent :many
 SELECT * FROM holons WHERE parent_id = ? ORDER BY created_at DESC;
 
@@ -57,6 +83,12 @@ SELECT * FROM evidence WHERE holon_id = ? ORDER BY created_at DESC;
 -- name: GetEvidenceWithCarrier :many
 SELECT * FROM evidence WHERE carrier_ref IS NOT NULL AND carrier_ref != '';
 
+-- name: ListAllEvidence :many
+SELECT * FROM evidence ORDER BY id;
+
+-- name: UpdateEvidence :exec
+UPDATE evidence SET holon_id = ?, type = ?, content = ?, verdict = ?, assurance_level = ?, carrier_ref = ?, valid_until = ? WHERE id = ?;
+
 -- Relation queries
 
 -- name: AddRelation :exec
@@ -91,6 +123,10 @@ SELECT source_id, congruence_level
 FROM relations
 WHERE target_id = ? AND relation_type = 'memberOf';
 
+-- name: GetDecisionContexts :many
+SELECT target_id FROM relations
+WHERE source_id = ? AND relation_type = 'memberOf';
+
 -- Work record queries
 
 -- name: RecordWork :exec
@@ -109,8 +145,20 @@ SELECT * FROM characteristics WHERE holon_id = ?;
 -- Audit log queries
 
 -- name: InsertAuditLog :exec
-INSERT INTO audit_log (id, tool_name, operation, actor, target_id, input_hash, result, details, context_id)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
+INSERT INTO audit_log (id, timestamp, tool_name, operation, actor, target_id, input_hash, result, details, context_id, seq, prev_hash, entry_hash, signature)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetAuditChainHead :one
+SELECT seq, entry_hash FROM audit_log WHERE context_id = ? AND seq IS NOT NULL ORDER BY seq DESC LIMIT 1;
+
+-- name: ListAuditChain :many
+SELECT * FROM audit_log WHERE context_id = ? AND seq IS NOT NULL ORDER BY seq;
+
+-- name: ListAuditLogContexts :many
+SELECT DISTINCT context_id FROM audit_log ORDER BY context_id;
+
+-- name: CountUnchainedAuditLog :one
+SELECT COUNT(*) FROM audit_log WHERE context_id = ? AND seq IS NULL;
 
 -- name: GetAuditLogByContext :many
 SELECT * FROM audit_log WHERE context_id = ? ORDER BY timestamp DESC;
@@ -140,3 +188,29 @@ SELECT * FROM waivers WHERE waived_until > datetime('now') ORDER BY waived_until
 
 -- name: GetEvidenceByID :one
 SELECT * FROM evidence WHERE id = ? LIMIT 1;
+
+-- Context queries
+
+-- name: CreateContext :exec
+INSERT INTO contexts (id, description, active, created_at)
+VALUES (?, ?, 0, ?);
+
+-- name: GetContext :one
+SELECT * FROM contexts WHERE id = ? LIMIT 1;
+
+-- name: ListContexts :many
+SELECT * FROM contexts ORDER BY id;
+
+-- name: GetActiveContext :one
+SELECT * FROM contexts WHERE active = 1 LIMIT 1;
+
+-- name: SetActiveContext :exec
+UPDATE contexts SET active = CASE WHEN id = ? THEN 1 ELSE 0 END;
+
+-- name: ListCrossContextRelations :many
+SELECT r.source_id, s.context_id AS source_context, r.target_id, t.context_id AS target_context, r.relation_type, r.congruence_level
+FROM relations r
+JOIN holons s ON s.id = r.source_id
+JOIN holons t ON t.id = r.target_id
+WHERE s.context_id <> t.context_id AND ? IN (s.context_id, t.context_id)
+ORDER BY r.source_id, r.target_id, r.relation_type;
@@ -13,7 +13,11 @@ CREATE TABLE holons (
     parent_id TEXT REFERENCES holons(id),
     cached_r_score REAL DEFAULT 0.0 CHECK(cached_r_score BETWEEN 0.0 AND 1.0),
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    formality INTEGER DEFAULT 0 CHECK(formality BETWEEN 0 AND 9),
+    scope_slice TEXT,
+    r_dirty INTEGER DEFAULT 1,
+    r_computed_at DATETIME
 );
 
 CREATE TABLE evidence (
@@ -71,7 +75,11 @@ CREATE TABLE audit_log (
     input_hash TEXT,
     result TEXT NOT NULL,
     details TEXT,
-    context_id TEXT NOT NULL DEFAULT 'default'
+    context_id TEXT NOT NULL DEFAULT 'default',
+    seq INTEGER,          -- Position in the context's hash chain
+    prev_hash TEXT,       -- entry_hash of the previous entry in the chain
+    entry_hash TEXT,      -- SHA-256 over this entry and prev_hash
+    signature TEXT        -- Optional ed25519 signature of entry_hash
 );
 
 CREATE TABLE waivers (
@@ -91,10 +99,23 @@ CREATE TABLE fpf_state (
     active_role_context TEXT,
     last_commit TEXT,
     assurance_threshold REAL DEFAULT 0.8 CHECK(assurance_threshold BETWEEN 0.0 AND 1.0),
-    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    cl_penalty_profile TEXT,
+    evidence_decay TEXT,
+    evidence_aggregation TEXT,
+    phase TEXT,
+    separation_of_duties TEXT
+);
+
+CREATE TABLE contexts (
+    id TEXT PRIMARY KEY,
+    description TEXT,
+    active INTEGER DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 );
 
 -- Indexes for WLNK traversal
 CREATE INDEX IF NOT EXISTS idx_relations_target ON relations(target_id, relation_type);
 CREATE INDEX IF NOT EXISTS idx_relations_source ON relations(source_id, relation_type);
 CREATE INDEX IF NOT EXISTS idx_waivers_evidence ON waivers(evidence_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_chain ON audit_log(context_id, seq);